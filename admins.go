@@ -0,0 +1,325 @@
+package neocortex
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AdminRole is the access level of an admin to the API, every role includes the permissions of the lower ones
+type AdminRole string
+
+// ViewerRole can read the summary, the collections and the views
+const ViewerRole AdminRole = "viewer"
+
+// AnalystRole can read the dialogs and the chats and download them
+const AnalystRole AdminRole = "analyst"
+
+// EditorRole can change the views and the action vars
+const EditorRole AdminRole = "editor"
+
+// OwnerRole can manage the other admins
+const OwnerRole AdminRole = "owner"
+
+var roleRanks = map[AdminRole]int{
+	ViewerRole:  1,
+	AnalystRole: 2,
+	EditorRole:  3,
+	OwnerRole:   4,
+}
+
+// IsValid returns true if the role is one of the known roles
+func (role AdminRole) IsValid() bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// Includes returns true if the role has at least the permissions of other
+func (role AdminRole) Includes(other AdminRole) bool {
+	return role.IsValid() && roleRanks[role] >= roleRanks[other]
+}
+
+// Admin is a user of the API, only the hash of its password is kept (see HashPassword)
+type Admin struct {
+	Username          string    `json:"username" bson:"username"`
+	PasswordHash      string    `json:"password_hash" bson:"password_hash"`
+	Role              AdminRole `json:"role" bson:"role"`
+	Disabled          bool      `json:"disabled" bson:"disabled"`
+	CreatedAt         time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" bson:"updated_at"`
+	PasswordChangedAt time.Time `json:"password_changed_at" bson:"password_changed_at"`
+}
+
+// AdminRepository persists the admins of the API, a Repository can implement it to keep the admins
+// between restarts. If the repository of the engine doesn't implement it the admins live into the memory
+type AdminRepository interface {
+	// SaveAdmin inserts the admin or replaces the admin with the same username
+	SaveAdmin(admin *Admin) error
+	GetAdmin(username string) (*Admin, error)
+	// AllAdmins returns the admins sorted by its username
+	AllAdmins() ([]*Admin, error)
+	DeleteAdmin(username string) (*Admin, error)
+}
+
+// memoryAdmins is the AdminRepository used when the repository of the engine can't persist the admins
+type memoryAdmins struct {
+	mu     sync.RWMutex
+	admins map[string]Admin
+}
+
+func newMemoryAdmins() *memoryAdmins {
+	return &memoryAdmins{admins: map[string]Admin{}}
+}
+
+func (m *memoryAdmins) SaveAdmin(admin *Admin) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.admins[admin.Username] = *admin
+	return nil
+}
+
+func (m *memoryAdmins) GetAdmin(username string) (*Admin, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	admin, ok := m.admins[username]
+	if !ok {
+		return nil, ErrAdminNotExist
+	}
+	return &admin, nil
+}
+
+func (m *memoryAdmins) AllAdmins() ([]*Admin, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	admins := make([]*Admin, 0, len(m.admins))
+	for _, admin := range m.admins {
+		admin := admin
+		admins = append(admins, &admin)
+	}
+	sort.Slice(admins, func(i, j int) bool {
+		return admins[i].Username < admins[j].Username
+	})
+	return admins, nil
+}
+
+func (m *memoryAdmins) DeleteAdmin(username string) (*Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	admin, ok := m.admins[username]
+	if !ok {
+		return nil, ErrAdminNotExist
+	}
+	delete(m.admins, username)
+	return &admin, nil
+}
+
+// RegisterAdmin creates an owner (or an admin with the given role) if it doesn't exist yet, an existing
+// admin is kept as it is, so its password can be rotated from the API without being reset on every start
+func (engine *Engine) RegisterAdmin(Username, Password string, role ...AdminRole) error {
+	r := OwnerRole
+	if len(role) > 0 {
+		r = role[0]
+	}
+
+	_, err := engine.CreateAdmin(Username, Password, r)
+	if err == ErrAdminExists {
+		return nil
+	}
+	return err
+}
+
+// registerDeprecatedAdmins creates the owners of the deprecated Register field of the engine
+func (engine *Engine) registerDeprecatedAdmins() {
+	for username, password := range engine.Register {
+		if err := engine.RegisterAdmin(username, password); err != nil {
+			engine.log(Error, "error registering the admin", Fields{"username": username, ErrorField: err})
+		}
+	}
+}
+
+// CreateAdmin creates a new enabled admin, the password is hashed before saving it
+func (engine *Engine) CreateAdmin(username, password string, role AdminRole) (*Admin, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, ErrInvalidUsername
+	}
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	engine.adminsMu.Lock()
+	defer engine.adminsMu.Unlock()
+
+	if _, err := engine.admins.GetAdmin(username); err == nil {
+		return nil, ErrAdminExists
+	} else if err != ErrAdminNotExist {
+		return nil, err
+	}
+
+	now := time.Now()
+	admin := &Admin{
+		Username:          username,
+		PasswordHash:      hash,
+		Role:              role,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		PasswordChangedAt: now,
+	}
+
+	if err := engine.admins.SaveAdmin(admin); err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// Admins returns all admins sorted by its username
+func (engine *Engine) Admins() ([]*Admin, error) {
+	return engine.admins.AllAdmins()
+}
+
+// DisableAdmin blocks the access of the admin, its tokens are rejected from the next request
+func (engine *Engine) DisableAdmin(username string) (*Admin, error) {
+	return engine.updateAdmin(username, func(admin *Admin) error {
+		admin.Disabled = true
+		return nil
+	})
+}
+
+// EnableAdmin gives back the access to a disabled admin
+func (engine *Engine) EnableAdmin(username string) (*Admin, error) {
+	return engine.updateAdmin(username, func(admin *Admin) error {
+		admin.Disabled = false
+		return nil
+	})
+}
+
+// SetAdminRole changes the role of the admin
+func (engine *Engine) SetAdminRole(username string, role AdminRole) (*Admin, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	return engine.updateAdmin(username, func(admin *Admin) error {
+		admin.Role = role
+		return nil
+	})
+}
+
+// RotateAdminPassword replaces the password of the admin
+func (engine *Engine) RotateAdminPassword(username, password string) (*Admin, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.updateAdmin(username, func(admin *Admin) error {
+		admin.PasswordHash = hash
+		admin.PasswordChangedAt = time.Now()
+		return nil
+	})
+}
+
+// RemoveAdmin let you remove the admin
+func (engine *Engine) RemoveAdmin(Username string) error {
+	engine.adminsMu.Lock()
+	defer engine.adminsMu.Unlock()
+
+	admin, err := engine.admins.GetAdmin(Username)
+	if err != nil {
+		return err
+	}
+
+	if err := engine.keepsAnOwner(admin, nil); err != nil {
+		return err
+	}
+
+	_, err = engine.admins.DeleteAdmin(Username)
+	return err
+}
+
+// updateAdmin applies change over the admin and saves it, the change can't leave the API without an enabled owner
+func (engine *Engine) updateAdmin(username string, change func(admin *Admin) error) (*Admin, error) {
+	engine.adminsMu.Lock()
+	defer engine.adminsMu.Unlock()
+
+	admin, err := engine.admins.GetAdmin(username)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *admin
+	if err := change(&updated); err != nil {
+		return nil, err
+	}
+
+	if err := engine.keepsAnOwner(admin, &updated); err != nil {
+		return nil, err
+	}
+
+	updated.UpdatedAt = time.Now()
+	if err := engine.admins.SaveAdmin(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// keepsAnOwner returns ErrLastOwner if replacing the admin by updated (nil to remove it) leaves
+// the API without an enabled owner, it must be called with the lock of the admins
+func (engine *Engine) keepsAnOwner(admin, updated *Admin) error {
+	if !isActiveOwner(admin) || (updated != nil && isActiveOwner(updated)) {
+		return nil
+	}
+
+	admins, err := engine.admins.AllAdmins()
+	if err != nil {
+		return err
+	}
+
+	for _, other := range admins {
+		if other.Username != admin.Username && isActiveOwner(other) {
+			return nil
+		}
+	}
+	return ErrLastOwner
+}
+
+func isActiveOwner(admin *Admin) bool {
+	return admin.Role == OwnerRole && !admin.Disabled
+}
+
+// authenticate returns the admin if it's enabled and the password matches its hash
+func (engine *Engine) authenticate(username, password string) (*Admin, error) {
+	admin, err := engine.admins.GetAdmin(username)
+	if err != nil {
+		// the hash is checked anyway, so a missing admin takes the same time than a wrong password
+		CheckPassword(unknownAdminHash(), password)
+		return nil, ErrInvalidCredentials
+	}
+
+	if !CheckPassword(admin.PasswordHash, password) || admin.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+	return admin, nil
+}
+
+// activeAdmin returns the admin if it still exists and it's enabled
+func (engine *Engine) activeAdmin(username string) (*Admin, error) {
+	admin, err := engine.admins.GetAdmin(username)
+	if err != nil {
+		return nil, err
+	}
+	if admin.Disabled {
+		return nil, ErrAdminDisabled
+	}
+	return admin, nil
+}
@@ -55,11 +55,15 @@ type Summary struct {
 type Analytics struct {
 	performanceFunction func(dialog *Dialog) float64
 	repo                Repository
+	logger              Logger
 }
 
-func newDefaultAnalytics(repo Repository, performanceFunction func(dialog *Dialog) float64) (*Analytics, error) {
+// NewAnalytics creates the analytics over the dialogs of the repository, performanceFunction
+// measures each dialog (see SetPerformanceMetric)
+func NewAnalytics(repo Repository, performanceFunction func(dialog *Dialog) float64) *Analytics {
 	return &Analytics{
 		performanceFunction: performanceFunction,
 		repo:                repo,
-	}, nil
+		logger:              DefaultLogger(),
+	}
 }
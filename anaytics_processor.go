@@ -1,8 +1,6 @@
 package neocortex
 
 import (
-	"fmt"
-	"log"
 	"sort"
 	"time"
 )
@@ -156,13 +154,12 @@ type TimeAnalysisResult struct {
 }
 
 func (analitycs *Analytics) timeAnalysis(viewID string, frame TimeFrame) (*TimeAnalysisResult, error) {
-	log.Println("time analytic")
 	view, err := analitycs.repo.GetViewByID(viewID)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Println(viewID, fmt.Sprintf("%+v", view))
+	analitycs.logger.Log(Debug, "time analytic", Fields{"view_id": viewID, "view": view.Name})
 
 	dialogs, err := analitycs.repo.DialogsByView(viewID, frame)
 	if err != nil {
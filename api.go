@@ -11,6 +11,9 @@ type API struct {
 	repository Repository
 	prefix     string
 	analytics  *Analytics
+
+	corsOrigins []string
+	logger      Logger
 }
 
 func newCortexAPI(repo Repository, analytics *Analytics, prefix, port string) *API {
@@ -20,38 +23,66 @@ func newCortexAPI(repo Repository, analytics *Analytics, prefix, port string) *A
 		prefix:     prefix,
 		repository: repo,
 		analytics:  analytics,
+		logger:     DefaultLogger(),
+	}
+}
+
+// allowsAllOrigins returns true without origins or if any of them is "*"
+func allowsAllOrigins(origins []string) bool {
+	for _, origin := range origins {
+		if origin == "*" {
+			return true
+		}
 	}
+	return len(origins) == 0
 }
 
-func (api *API) registerEndpoints(engine *Engine) {
+func (api *API) registerEndpoints(engine *Engine) error {
 	corsConf := cors.DefaultConfig()
-	corsConf.AddAllowHeaders("Authorization")
+	corsConf.AddAllowHeaders("Authorization", apiKeyHeader)
 
-	corsConf.AllowAllOrigins = true
+	corsConf.AllowAllOrigins = allowsAllOrigins(api.corsOrigins)
+	if !corsConf.AllowAllOrigins {
+		corsConf.AllowOrigins = api.corsOrigins
+	}
 
 	c := cors.New(corsConf)
 
 	api.e.Use(c)
 
-	authJWTMiddleware := getJWTAuth(engine, engine.secret)
+	authJWTMiddleware, err := getJWTAuth(engine)
+	if err != nil {
+		return err
+	}
 
 	api.e.POST("/login", authJWTMiddleware.LoginHandler)
 
-	api.e.GET("/token_refresh", authJWTMiddleware.RefreshHandler)
+	api.e.GET("/token_refresh", refreshHandler(engine, authJWTMiddleware))
 
-	api.e.Use(authJWTMiddleware.MiddlewareFunc())
+	api.e.Use(authMiddleware(engine, authJWTMiddleware))
 
+	api.e.POST("/logout", logoutHandler(engine))
+
+	// every group requires a minimum role to read (GET) and to write, or a scope over the group for
+	// the API keys (see allow). The admins and the API keys are managed only by the owners
 	r := api.e.Group(api.prefix)
-	api.registerDialogsAPI(r)
-	api.registerViewsAPI(r)
-	api.registerActionsAPI(r)
-	api.registerCollectionsAPI(r)
-	api.registerSummaryAPI(r)
-	api.registerChatsAPI(r)
-	api.registerDownloadsAPI(r)
+	api.registerSummaryAPI(r.Group("", allow("summary", ViewerRole, EditorRole)))
+	api.registerStatusAPI(r.Group("", allow("summary", ViewerRole, EditorRole)), engine)
+	api.registerCollectionsAPI(r.Group("", allow("collections", ViewerRole, EditorRole)))
+	api.registerViewsAPI(r.Group("", allow("views", ViewerRole, EditorRole)))
+	api.registerDialogsAPI(r.Group("", allow("dialogs", AnalystRole, EditorRole)))
+	api.registerChatsAPI(r.Group("", allow("chats", AnalystRole, EditorRole)))
+	api.registerDownloadsAPI(r.Group("", allow("downloads", AnalystRole, AnalystRole)))
+	api.registerActionsAPI(r.Group("", allow("actions", AnalystRole, EditorRole)))
+	api.registerAdminAPI(r.Group("", allow("admin", ViewerRole, ViewerRole)), engine)
+	api.registerAdminsAPI(r.Group("", allow("admins", OwnerRole, OwnerRole)), engine)
+	api.registerAPIKeysAPI(r.Group("", allow("admins", OwnerRole, OwnerRole)), engine)
+	return nil
 }
 
 func (api *API) Launch(engine *Engine) error {
-	api.registerEndpoints(engine)
+	if err := api.registerEndpoints(engine); err != nil {
+		return err
+	}
 	return api.e.Run(api.Port)
 }
@@ -0,0 +1,211 @@
+package neocortex
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MinPasswordLength is the minimum length of the passwords set from the API
+const MinPasswordLength = 8
+
+// adminInfo is the public view of an admin, the hash of the password never leaves the engine
+type adminInfo struct {
+	Username          string    `json:"username"`
+	Role              AdminRole `json:"role"`
+	Disabled          bool      `json:"disabled"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+func newAdminInfo(admin *Admin) *adminInfo {
+	return &adminInfo{
+		Username:          admin.Username,
+		Role:              admin.Role,
+		Disabled:          admin.Disabled,
+		CreatedAt:         admin.CreatedAt,
+		UpdatedAt:         admin.UpdatedAt,
+		PasswordChangedAt: admin.PasswordChangedAt,
+	}
+}
+
+func validPassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+func adminErrorStatus(err error) int {
+	switch err {
+	case ErrAdminNotExist:
+		return http.StatusNotFound
+	case ErrAdminExists, ErrLastOwner:
+		return http.StatusConflict
+	case ErrInvalidUsername, ErrInvalidRole, ErrWeakPassword, ErrInvalidCredentials:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// registerAdminAPI registers the endpoints of the authorized admin, any role can use them
+func (api *API) registerAdminAPI(r *gin.RouterGroup, engine *Engine) {
+	r.GET("/admin", func(c *gin.Context) {
+		admin, _ := currentAdmin(c)
+		c.JSON(http.StatusOK, gin.H{"data": newAdminInfo(admin)})
+	})
+
+	r.PUT("/admin/password", func(c *gin.Context) {
+		type bind struct {
+			CurrentPassword string `json:"current_password" binding:"required"`
+			Password        string `json:"password" binding:"required"`
+		}
+
+		body := new(bind)
+		if err := c.ShouldBindJSON(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validPassword(body.Password); err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		current, _ := currentAdmin(c)
+		if _, err := engine.authenticate(current.Username, body.CurrentPassword); err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		admin, err := engine.RotateAdminPassword(current.Username, body.Password)
+		if err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": newAdminInfo(admin)})
+	})
+}
+
+// registerAdminsAPI registers the management of the admins, only for owners
+func (api *API) registerAdminsAPI(r *gin.RouterGroup, engine *Engine) {
+	r.GET("/admins", func(c *gin.Context) {
+		admins, err := engine.Admins()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		infos := make([]*adminInfo, 0, len(admins))
+		for _, admin := range admins {
+			infos = append(infos, newAdminInfo(admin))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": infos})
+	})
+
+	r.POST("/admins", func(c *gin.Context) {
+		type bind struct {
+			Username string    `json:"username" binding:"required"`
+			Password string    `json:"password" binding:"required"`
+			Role     AdminRole `json:"role" binding:"required"`
+		}
+
+		body := new(bind)
+		if err := c.ShouldBindJSON(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validPassword(body.Password); err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		admin, err := engine.CreateAdmin(body.Username, body.Password, body.Role)
+		if err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"data": newAdminInfo(admin)})
+	})
+
+	r.PUT("/admins/:username/password", func(c *gin.Context) {
+		type bind struct {
+			Password string `json:"password" binding:"required"`
+		}
+
+		body := new(bind)
+		if err := c.ShouldBindJSON(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := validPassword(body.Password); err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		admin, err := engine.RotateAdminPassword(c.Param("username"), body.Password)
+		if err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": newAdminInfo(admin)})
+	})
+
+	r.PUT("/admins/:username/role", func(c *gin.Context) {
+		type bind struct {
+			Role AdminRole `json:"role" binding:"required"`
+		}
+
+		body := new(bind)
+		if err := c.ShouldBindJSON(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		admin, err := engine.SetAdminRole(c.Param("username"), body.Role)
+		if err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": newAdminInfo(admin)})
+	})
+
+	r.POST("/admins/:username/disable", func(c *gin.Context) {
+		admin, err := engine.DisableAdmin(c.Param("username"))
+		if err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": newAdminInfo(admin)})
+	})
+
+	r.POST("/admins/:username/enable", func(c *gin.Context) {
+		admin, err := engine.EnableAdmin(c.Param("username"))
+		if err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": newAdminInfo(admin)})
+	})
+
+	r.DELETE("/admins/:username", func(c *gin.Context) {
+		username := c.Param("username")
+		if err := engine.RemoveAdmin(username); err != nil {
+			c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": username})
+	})
+}
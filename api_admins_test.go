@@ -0,0 +1,238 @@
+package neocortex
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubRepository answers the few calls of the routes used by the tests of the API
+type stubRepository struct {
+	Repository
+}
+
+func (repo *stubRepository) AllViews() ([]*View, error)               { return []*View{}, nil }
+func (repo *stubRepository) SaveView(view *View) error                { return nil }
+func (repo *stubRepository) GetDialogByID(id string) (*Dialog, error) { return &Dialog{ID: id}, nil }
+func (repo *stubRepository) GetActionVar(name string) (string, error) { return "value", nil }
+func (repo *stubRepository) SetActionVar(name, value string) error    { return nil }
+
+// newTestAPI returns the engine and the handler of its API, without channels and without running it
+func newTestAPI(t *testing.T) (*Engine, http.Handler) {
+	t.Helper()
+
+	engine := newDefaultEngine(nil)
+	engine.logger = NewLogger(ioutil.Discard, TextFormat)
+	if err := engine.SetAuthConfig(AuthConfig{Secret: strings.Repeat("s", MinSecretLength)}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine.api = newCortexAPI(&stubRepository{}, nil, defaultPrefix, defaultPort)
+	engine.api.e = gin.New()
+	engine.api.logger = engine.logger
+	if err := engine.api.registerEndpoints(engine); err != nil {
+		t.Fatal(err)
+	}
+	return engine, engine.api.e
+}
+
+// serve sends the request with the token (a JWT or an API key) and the body encoded as JSON
+func serve(t *testing.T, handler http.Handler, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+func logIn(t *testing.T, handler http.Handler, username, password string) string {
+	t.Helper()
+
+	res := serve(t, handler, http.MethodPost, "/login", "", login{Username: username, Password: password})
+	if res.Code != http.StatusOK {
+		t.Fatalf("%s can't log in, got %d %s", username, res.Code, res.Body)
+	}
+
+	body := struct {
+		Token string `json:"token"`
+	}{}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Token
+}
+
+func createAdmin(t *testing.T, engine *Engine, username string, role AdminRole) {
+	t.Helper()
+
+	if _, err := engine.CreateAdmin(username, username+" password", role); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRolesByRouteGroup(t *testing.T) {
+	engine, handler := newTestAPI(t)
+
+	roles := []AdminRole{ViewerRole, AnalystRole, EditorRole, OwnerRole}
+	tokens := map[AdminRole]string{}
+	for _, role := range roles {
+		createAdmin(t, engine, string(role), role)
+		tokens[role] = logIn(t, handler, string(role), string(role)+" password")
+	}
+
+	routes := []struct {
+		method   string
+		path     string
+		body     interface{}
+		required AdminRole
+	}{
+		{http.MethodGet, "/api/admin", nil, ViewerRole},
+		{http.MethodGet, "/api/views/", nil, ViewerRole},
+		{http.MethodPost, "/api/view", View{Name: "all"}, EditorRole},
+		{http.MethodGet, "/api/dialog/1", nil, AnalystRole},
+		{http.MethodGet, "/api/actions/env/name", nil, AnalystRole},
+		{http.MethodPost, "/api/actions/env/name", gin.H{"value": "value"}, EditorRole},
+		{http.MethodGet, "/api/admins", nil, OwnerRole},
+		{http.MethodGet, "/api/api_keys", nil, OwnerRole},
+	}
+
+	for _, route := range routes {
+		for _, role := range roles {
+			want := http.StatusForbidden
+			if role.Includes(route.required) {
+				want = http.StatusOK
+			}
+
+			res := serve(t, handler, route.method, route.path, tokens[role], route.body)
+			if res.Code != want {
+				t.Errorf("%s %s as %s: expected %d, got %d %s", route.method, route.path, role, want, res.Code, res.Body)
+			}
+		}
+	}
+
+	if res := serve(t, handler, http.MethodGet, "/api/admin", "", nil); res.Code != http.StatusUnauthorized {
+		t.Errorf("a request without token must be unauthorized, got %d", res.Code)
+	}
+}
+
+func TestADisabledAdminLosesTheAccess(t *testing.T) {
+	engine, handler := newTestAPI(t)
+	createAdmin(t, engine, "owner", OwnerRole)
+	createAdmin(t, engine, "editor", EditorRole)
+	owner, editor := logIn(t, handler, "owner", "owner password"), logIn(t, handler, "editor", "editor password")
+
+	if res := serve(t, handler, http.MethodGet, "/api/admin", editor, nil); res.Code != http.StatusOK {
+		t.Fatalf("expected the access of the editor, got %d", res.Code)
+	}
+
+	if res := serve(t, handler, http.MethodPost, "/api/admins/editor/disable", owner, nil); res.Code != http.StatusOK {
+		t.Fatalf("the owner must disable the editor, got %d %s", res.Code, res.Body)
+	}
+
+	if res := serve(t, handler, http.MethodGet, "/api/admin", editor, nil); res.Code != http.StatusForbidden {
+		t.Errorf("the token of a disabled admin must be rejected on the next request, got %d", res.Code)
+	}
+	if res := serve(t, handler, http.MethodPost, "/login", "", login{Username: "editor", Password: "editor password"}); res.Code != http.StatusUnauthorized {
+		t.Errorf("a disabled admin can't log in, got %d", res.Code)
+	}
+
+	serve(t, handler, http.MethodPost, "/api/admins/editor/enable", owner, nil)
+	if res := serve(t, handler, http.MethodGet, "/api/admin", editor, nil); res.Code != http.StatusOK {
+		t.Errorf("an enabled admin must get back the access, got %d", res.Code)
+	}
+}
+
+func TestRotatingThePasswordRejectsTheOlderTokens(t *testing.T) {
+	engine, handler := newTestAPI(t)
+	createAdmin(t, engine, "owner", OwnerRole)
+	createAdmin(t, engine, "editor", EditorRole)
+	owner, editor := logIn(t, handler, "owner", "owner password"), logIn(t, handler, "editor", "editor password")
+
+	res := serve(t, handler, http.MethodPut, "/api/admins/editor/password", owner, gin.H{"password": "a new password"})
+	if res.Code != http.StatusOK {
+		t.Fatalf("the owner must rotate the password, got %d %s", res.Code, res.Body)
+	}
+
+	// the token was issued into the same second than the rotation, the seconds of orig_iat can't tell them apart
+	if res = serve(t, handler, http.MethodGet, "/api/admin", editor, nil); res.Code != http.StatusForbidden {
+		t.Errorf("a token older than the password must be rejected, got %d", res.Code)
+	}
+	if res = serve(t, handler, http.MethodGet, "/token_refresh", editor, nil); res.Code != http.StatusForbidden {
+		t.Errorf("a token older than the password can't be refreshed, got %d", res.Code)
+	}
+
+	admin, err := engine.admins.GetAdmin("editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Until(admin.PasswordChangedAt.Truncate(time.Second).Add(time.Second)))
+
+	if res := serve(t, handler, http.MethodPost, "/login", "", login{Username: "editor", Password: "editor password"}); res.Code != http.StatusUnauthorized {
+		t.Errorf("the old password can't be used, got %d", res.Code)
+	}
+	editor = logIn(t, handler, "editor", "a new password")
+	if res = serve(t, handler, http.MethodGet, "/api/admin", editor, nil); res.Code != http.StatusOK {
+		t.Errorf("a token of the new password must be accepted, got %d", res.Code)
+	}
+}
+
+func TestTheLastOwnerIsKept(t *testing.T) {
+	engine, handler := newTestAPI(t)
+	createAdmin(t, engine, "owner", OwnerRole)
+	owner := logIn(t, handler, "owner", "owner password")
+
+	changes := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{http.MethodPost, "/api/admins/owner/disable", nil},
+		{http.MethodPut, "/api/admins/owner/role", gin.H{"role": EditorRole}},
+		{http.MethodDelete, "/api/admins/owner", nil},
+	}
+	for _, change := range changes {
+		if res := serve(t, handler, change.method, change.path, owner, change.body); res.Code != http.StatusConflict {
+			t.Errorf("%s %s: the last owner must be kept, got %d", change.method, change.path, res.Code)
+		}
+	}
+
+	createAdmin(t, engine, "other", OwnerRole)
+	if res := serve(t, handler, http.MethodPut, "/api/admins/owner/role", owner, gin.H{"role": EditorRole}); res.Code != http.StatusOK {
+		t.Errorf("an owner can be demoted if there is another one, got %d %s", res.Code, res.Body)
+	}
+	if res := serve(t, handler, http.MethodPost, "/api/admins/other/disable", logIn(t, handler, "other", "other password"), nil); res.Code != http.StatusConflict {
+		t.Errorf("the other owner is the last one now, got %d", res.Code)
+	}
+}
+
+func TestTheDeprecatedRegisterCreatesOwners(t *testing.T) {
+	engine, handler := newTestAPI(t)
+	engine.Register["old"] = "old password"
+	engine.registerDeprecatedAdmins()
+
+	token := logIn(t, handler, "old", "old password")
+	if res := serve(t, handler, http.MethodGet, "/api/admins", token, nil); res.Code != http.StatusOK {
+		t.Errorf("the admins of Register must be owners, got %d", res.Code)
+	}
+}
@@ -1,7 +1,6 @@
 package neocortex
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 
@@ -70,9 +69,8 @@ func (api *API) registerDialogsAPI(r *gin.RouterGroup) {
 
 		viewID = strings.Trim(viewID, "/")
 
-		log.Println("viewID, ", viewID)
 		if _, err := xid.FromString(viewID); err != nil {
-			log.Println(err.Error())
+			api.logger.Log(Warn, "invalid view id", Fields{"view_id": viewID, ErrorField: err})
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid view id"})
 			return
 		}
@@ -136,9 +134,8 @@ func (api *API) registerDialogsAPI(r *gin.RouterGroup) {
 
 		viewID = strings.Trim(viewID, "/")
 
-		log.Println("viewID, ", viewID)
 		if _, err := xid.FromString(viewID); err != nil {
-			log.Println(err.Error())
+			api.logger.Log(Warn, "invalid view id", Fields{"view_id": viewID, ErrorField: err})
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid view id"})
 			return
 		}
@@ -0,0 +1,19 @@
+package neocortex
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerStatusAPI serves the health of the engine, the cognitive status is null if the
+// cognitive service doesn't implement StatusReporter
+func (api *API) registerStatusAPI(r *gin.RouterGroup, engine *Engine) {
+	r.GET("/status", func(c *gin.Context) {
+		cognitive, _ := engine.CognitiveStatus()
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{
+			"active_sessions": engine.Sessions.Len(),
+			"cognitive":       cognitive,
+		}})
+	})
+}
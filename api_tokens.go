@@ -0,0 +1,100 @@
+package neocortex
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyInfo is the public view of an API key, the hash of its secret never leaves the engine
+type apiKeyInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []Scope   `json:"scopes"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+	Active    bool      `json:"active"`
+}
+
+func newAPIKeyInfo(key *APIKey) *apiKeyInfo {
+	return &apiKeyInfo{
+		ID:        key.ID,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		CreatedBy: key.CreatedBy,
+		CreatedAt: key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+		RevokedAt: key.RevokedAt,
+		Active:    key.Active(time.Now()),
+	}
+}
+
+func tokenErrorStatus(err error) int {
+	switch err {
+	case ErrAPIKeyNotExist:
+		return http.StatusNotFound
+	case ErrInvalidScope:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// registerAPIKeysAPI registers the management of the API keys, only for owners
+func (api *API) registerAPIKeysAPI(r *gin.RouterGroup, engine *Engine) {
+	r.GET("/api_keys", func(c *gin.Context) {
+		keys, err := engine.APIKeys()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		infos := make([]*apiKeyInfo, 0, len(keys))
+		for _, key := range keys {
+			infos = append(infos, newAPIKeyInfo(key))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": infos})
+	})
+
+	// the key is returned only once, into the response of its creation
+	r.POST("/api_keys", func(c *gin.Context) {
+		type bind struct {
+			Name      string    `json:"name" binding:"required"`
+			Scopes    []Scope   `json:"scopes" binding:"required"`
+			ExpiresAt time.Time `json:"expires_at"`
+		}
+
+		body := new(bind)
+		if err := c.ShouldBindJSON(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !body.ExpiresAt.IsZero() && !body.ExpiresAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the expiration must be in the future"})
+			return
+		}
+
+		admin, _ := currentAdmin(c)
+		key, raw, err := engine.CreateAPIKey(body.Name, body.Scopes, body.ExpiresAt, admin.Username)
+		if err != nil {
+			c.JSON(tokenErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"data": gin.H{"key": raw, "api_key": newAPIKeyInfo(key)}})
+	})
+
+	r.POST("/api_keys/:id/revoke", func(c *gin.Context) {
+		key, err := engine.RevokeAPIKey(c.Param("id"))
+		if err != nil {
+			c.JSON(tokenErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": newAPIKeyInfo(key)})
+	})
+}
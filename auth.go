@@ -1,14 +1,15 @@
 package neocortex
 
 import (
-	"encoding/json"
-	"errors"
-	"io/ioutil"
-	"log"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/xid"
 )
 
 type login struct {
@@ -18,61 +19,173 @@ type login struct {
 
 type user struct {
 	Username string
+	Role     AdminRole
 }
 
 const identityKey = "id"
 
-func getJWTAuth(engine *Engine, secretKey string) *jwt.GinJWTMiddleware {
+const roleKey = "role"
+
+const tokenIDKey = "jti"
+
+// adminKey is the key of the authorized admin into the gin context (see currentAdmin)
+const adminKey = "admin"
+
+// apiKeyKey is the key of the authorized API key into the gin context (see currentAPIKey)
+const apiKeyKey = "api_key"
+
+// apiKeyHeader carries the API key, a key is accepted as a bearer token too
+const apiKeyHeader = "X-API-Key"
+
+// MinSecretLength is the minimum length (in bytes) of the secret that signs the tokens of the API
+const MinSecretLength = 32
+
+// AuthConfig configures the tokens of the API, the zero fields take the defaults. Without a secret the engine
+// generates a random one at start, so the tokens don't survive a restart (nobody can forge them anyway)
+type AuthConfig struct {
+	Secret     string
+	Realm      string
+	Timeout    time.Duration
+	MaxRefresh time.Duration
+}
+
+func defaultAuthConfig() AuthConfig {
+	return AuthConfig{
+		Realm:      "neocortex",
+		Timeout:    time.Hour,
+		MaxRefresh: time.Hour,
+	}
+}
+
+// SetAuthConfig changes the configuration of the tokens of the API, it must be called before Run
+func (engine *Engine) SetAuthConfig(config AuthConfig) error {
+	if config.Secret != "" && len(config.Secret) < MinSecretLength {
+		return ErrWeakSecret
+	}
+
+	defaults := defaultAuthConfig()
+	if config.Realm == "" {
+		config.Realm = defaults.Realm
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxRefresh <= 0 {
+		config.MaxRefresh = defaults.MaxRefresh
+	}
+
+	engine.auth = config
+	return nil
+}
+
+func randomSecret() ([]byte, error) {
+	secret := make([]byte, MinSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("can't generate the JWT secret: %w", err)
+	}
+	return secret, nil
+}
+
+// claimTime returns the time of a numeric claim (e.g. exp or orig_iat)
+func claimTime(claims jwt.MapClaims, name string) time.Time {
+	value, _ := claims[name].(float64)
+	return time.Unix(int64(value), 0)
+}
+
+// authorizeClaims returns the admin of the claims if the token wasn't revoked, its admin is still
+// enabled and the token was issued after the last change of the password of the admin
+func (engine *Engine) authorizeClaims(claims jwt.MapClaims) (*Admin, error) {
+	id, _ := claims[tokenIDKey].(string)
+	if id == "" {
+		return nil, jwt.ErrForbidden
+	}
+
+	revoked, err := engine.tokens.IsTokenRevoked(id)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, jwt.ErrForbidden
+	}
+
+	username, _ := claims[identityKey].(string)
+	admin, err := engine.activeAdmin(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// orig_iat has seconds, so a token issued into the same second than a rotation of the password is
+	// rejected too (the admin logs in again). The password set when the admin is created doesn't reject them
+	rotated := admin.PasswordChangedAt.After(admin.CreatedAt)
+	if rotated && claimTime(claims, "orig_iat").Unix() <= admin.PasswordChangedAt.Unix() {
+		return nil, jwt.ErrForbidden
+	}
+
+	return admin, nil
+}
+
+func getJWTAuth(engine *Engine) (*jwt.GinJWTMiddleware, error) {
+	config := engine.auth
+	key := []byte(config.Secret)
+	if len(key) == 0 {
+		engine.log(Warn, "the JWT secret isn't configured, a random one is used and the tokens won't survive a restart", nil)
+		var err error
+		if key, err = randomSecret(); err != nil {
+			return nil, err
+		}
+	}
+
 	authMiddleware, err := jwt.New(&jwt.GinJWTMiddleware{
-		Realm:       "test zone",
-		Key:         []byte(secretKey),
-		Timeout:     time.Hour,
-		MaxRefresh:  time.Hour,
+		Realm:       config.Realm,
+		Key:         key,
+		Timeout:     config.Timeout,
+		MaxRefresh:  config.MaxRefresh,
 		IdentityKey: identityKey,
+		// every token has its own id (jti), so it can be revoked
 		PayloadFunc: func(data interface{}) jwt.MapClaims {
 			if v, ok := data.(*user); ok {
 				return jwt.MapClaims{
 					identityKey: v.Username,
+					roleKey:     string(v.Role),
+					tokenIDKey:  xid.New().String(),
 				}
 			}
 			return jwt.MapClaims{}
 		},
 		IdentityHandler: func(c *gin.Context) interface{} {
 			claims := jwt.ExtractClaims(c)
+			username, _ := claims[identityKey].(string)
+			role, _ := claims[roleKey].(string)
 			return &user{
-				Username: claims["id"].(string),
+				Username: username,
+				Role:     AdminRole(role),
 			}
 		},
 		Authenticator: func(c *gin.Context) (interface{}, error) {
-			data, err := ioutil.ReadAll(c.Request.Body)
-			if err != nil {
-				return "", errors.New(err.Error())
-			}
 			l := new(login)
-			if l == nil {
-				return nil, jwt.ErrFailedAuthentication
+			if err := c.ShouldBindJSON(l); err != nil {
+				return nil, jwt.ErrMissingLoginValues
 			}
-			err = json.Unmarshal(data, l)
-			if err != nil {
-				return "", errors.New(err.Error())
-			}
-
-			userID := l.Username
-			password := l.Password
 
-			passwordAdmin, err := engine.getAdmin(userID)
-			if err != nil || passwordAdmin == "" {
+			admin, err := engine.authenticate(l.Username, l.Password)
+			if err != nil {
 				return nil, jwt.ErrFailedAuthentication
 			}
-			if password == passwordAdmin {
-				return &user{
-					Username: userID,
-				}, nil
-			}
 
-			return nil, jwt.ErrFailedAuthentication
+			return &user{
+				Username: admin.Username,
+				Role:     admin.Role,
+			}, nil
 		},
+		// the admin is loaded on every request, so a disabled admin loses the access
+		// immediately and a new role takes effect without a new token
 		Authorizator: func(data interface{}, c *gin.Context) bool {
+			admin, err := engine.authorizeClaims(jwt.ExtractClaims(c))
+			if err != nil {
+				return false
+			}
+
+			c.Set(adminKey, admin)
 			return true
 		},
 		Unauthorized: func(c *gin.Context, code int, message string) {
@@ -89,8 +202,148 @@ func getJWTAuth(engine *Engine, secretKey string) *jwt.GinJWTMiddleware {
 	})
 
 	if err != nil {
-		log.Fatal("JWT Error:" + err.Error())
+		return nil, fmt.Errorf("can't create the JWT middleware: %w", err)
+	}
+
+	return authMiddleware, nil
+}
+
+// refreshHandler issues a new token (with a new id) only if the current one is still authorized
+func refreshHandler(engine *Engine, mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := mw.CheckIfTokenExpire(c)
+		if err != nil {
+			mw.Unauthorized(c, http.StatusUnauthorized, mw.HTTPStatusMessageFunc(err, c))
+			return
+		}
+
+		admin, err := engine.authorizeClaims(jwt.MapClaims(claims))
+		if err != nil {
+			mw.Unauthorized(c, http.StatusForbidden, mw.HTTPStatusMessageFunc(jwt.ErrForbidden, c))
+			return
+		}
+
+		token, expire, err := mw.TokenGenerator(&user{Username: admin.Username, Role: admin.Role})
+		if err != nil {
+			mw.Unauthorized(c, http.StatusUnauthorized, mw.HTTPStatusMessageFunc(err, c))
+			return
+		}
+
+		mw.RefreshResponse(c, http.StatusOK, token, expire)
+	}
+}
+
+// logoutHandler revokes the token of the request
+func logoutHandler(engine *Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := jwt.ExtractClaims(c)
+		id, _ := claims[tokenIDKey].(string)
+		if id == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "only the tokens of the admins can be revoked"})
+			return
+		}
+
+		if err := engine.RevokeToken(id, claimTime(claims, "exp")); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": id})
+	}
+}
+
+// apiKeyFromRequest returns the API key of the request (from the X-API-Key header or as a bearer token)
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(auth, "Bearer "+apiKeyPrefix) {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ""
+}
+
+// authMiddleware authorizes the requests with an API key or else with the JWT of an admin
+func authMiddleware(engine *Engine, mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	jwtMiddleware := mw.MiddlewareFunc()
+	return func(c *gin.Context) {
+		raw := apiKeyFromRequest(c.Request)
+		if raw == "" {
+			jwtMiddleware(c)
+			return
+		}
+
+		key, err := engine.authenticateAPIKey(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    http.StatusUnauthorized,
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.Set(apiKeyKey, key)
+		c.Next()
+	}
+}
+
+// currentAdmin returns the admin authorized for the request
+func currentAdmin(c *gin.Context) (*Admin, bool) {
+	value, ok := c.Get(adminKey)
+	if !ok {
+		return nil, false
+	}
+	admin, ok := value.(*Admin)
+	return admin, ok
+}
+
+// currentAPIKey returns the API key authorized for the request
+func currentAPIKey(c *gin.Context) (*APIKey, bool) {
+	value, ok := c.Get(apiKeyKey)
+	if !ok {
+		return nil, false
 	}
+	key, ok := value.(*APIKey)
+	return key, ok
+}
 
-	return authMiddleware
+// allow lets pass the admins with at least the role read for the safe methods (GET and HEAD)
+// and at least the role write for the others, the API keys need a scope over the group
+func allow(group string, read, write AdminRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writing := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+
+		if key, ok := currentAPIKey(c); ok {
+			if !key.Allows(group, writing) {
+				access := readAccess
+				if writing {
+					access = writeAccess
+				}
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the scope " + group + ":" + access + " is required"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		admin, ok := currentAdmin(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		required := read
+		if writing {
+			required = write
+		}
+
+		if !admin.Role.Includes(required) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the role " + string(required) + " is required"})
+			return
+		}
+
+		c.Next()
+	}
 }
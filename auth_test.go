@@ -0,0 +1,117 @@
+package neocortex
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestALoggedOutTokenIsRejected(t *testing.T) {
+	engine, handler := newTestAPI(t)
+	createAdmin(t, engine, "owner", OwnerRole)
+	token := logIn(t, handler, "owner", "owner password")
+
+	if res := serve(t, handler, http.MethodPost, "/logout", token, nil); res.Code != http.StatusOK {
+		t.Fatalf("expected the logout, got %d %s", res.Code, res.Body)
+	}
+
+	if res := serve(t, handler, http.MethodGet, "/api/admin", token, nil); res.Code != http.StatusForbidden {
+		t.Errorf("the middleware must reject a logged out token, got %d", res.Code)
+	}
+	if res := serve(t, handler, http.MethodGet, "/token_refresh", token, nil); res.Code != http.StatusForbidden {
+		t.Errorf("a logged out token can't be refreshed, got %d", res.Code)
+	}
+
+	other := logIn(t, handler, "owner", "owner password")
+	if res := serve(t, handler, http.MethodGet, "/api/admin", other, nil); res.Code != http.StatusOK {
+		t.Errorf("the logout revokes only its token, got %d", res.Code)
+	}
+}
+
+func TestInactiveAPIKeysAreUnauthorized(t *testing.T) {
+	engine, handler := newTestAPI(t)
+	scopes := []Scope{"views:read"}
+
+	_, expired, err := engine.CreateAPIKey("expired", scopes, time.Now().Add(-time.Minute), "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revokedKey, revoked, err := engine.CreateAPIKey("revoked", scopes, time.Time{}, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(t, handler, http.MethodGet, "/api/views/", revoked, nil); res.Code != http.StatusOK {
+		t.Fatalf("expected the access of the key before its revocation, got %d %s", res.Code, res.Body)
+	}
+	if _, err = engine.RevokeAPIKey(revokedKey.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string]string{
+		"expired":   expired,
+		"revoked":   revoked,
+		"forged":    revoked[:len(revoked)-1] + "x",
+		"malformed": apiKeyPrefix + "nothing",
+	}
+	for name, key := range keys {
+		if res := serve(t, handler, http.MethodGet, "/api/views/", key, nil); res.Code != http.StatusUnauthorized {
+			t.Errorf("the %s key must be unauthorized, got %d", name, res.Code)
+		}
+	}
+}
+
+func TestTheScopesOfTheAPIKeys(t *testing.T) {
+	engine, handler := newTestAPI(t)
+
+	_, reader, err := engine.CreateAPIKey("reader", []Scope{"actions:read"}, time.Time{}, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, writer, err := engine.CreateAPIKey("writer", []Scope{"actions:write"}, time.Time{}, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		want   int
+	}{
+		{"reader", reader, http.MethodGet, "/api/actions/env/name", http.StatusOK},
+		{"reader", reader, http.MethodPost, "/api/actions/env/name", http.StatusForbidden},
+		{"writer", writer, http.MethodGet, "/api/actions/env/name", http.StatusOK},
+		{"writer", writer, http.MethodPost, "/api/actions/env/name", http.StatusOK},
+		{"writer", writer, http.MethodGet, "/api/views/", http.StatusForbidden},
+		{"writer", writer, http.MethodGet, "/api/admins", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if res := serve(t, handler, tc.method, tc.path, tc.key, gin.H{"value": "value"}); res.Code != tc.want {
+			t.Errorf("%s %s with %s: expected %d, got %d %s", tc.method, tc.path, tc.name, tc.want, res.Code, res.Body)
+		}
+	}
+}
+
+func TestSetAuthConfigRejectsWeakSecrets(t *testing.T) {
+	engine := newDefaultEngine(nil)
+
+	if err := engine.SetAuthConfig(AuthConfig{Secret: strings.Repeat("s", MinSecretLength-1)}); !errors.Is(err, ErrWeakSecret) {
+		t.Errorf("expected ErrWeakSecret, got %v", err)
+	}
+	if engine.auth.Secret != "" {
+		t.Error("a rejected configuration can't be kept")
+	}
+
+	if err := engine.SetAuthConfig(AuthConfig{Secret: strings.Repeat("s", MinSecretLength)}); err != nil {
+		t.Fatal(err)
+	}
+	if engine.auth.Realm != "neocortex" || engine.auth.Timeout != time.Hour {
+		t.Errorf("the zero fields must take the defaults, got %+v", engine.auth)
+	}
+}
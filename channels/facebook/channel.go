@@ -1,10 +1,9 @@
 package facebook
 
 import (
-	"fmt"
-	"log"
 	"net/http"
 	"strconv"
+	"sync"
 
 	neo "github.com/minskylab/neocortex"
 	"github.com/minskylab/neocortex/channels/facebook/messenger"
@@ -12,11 +11,13 @@ import (
 
 type Channel struct {
 	m                    *messenger.Messenger
+	mu                   sync.Mutex
 	messageIn            neo.MiddleHandler
 	newContext           neo.ContextFabric
 	contexts             map[int64]*neo.Context
 	newContextCallbacks  []*func(c *neo.Context)
 	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
 }
 
 type ChannelOptions struct {
@@ -30,9 +31,14 @@ func (fb *Channel) RegisterMessageEndpoint(handler neo.MiddleHandler) error {
 	return nil
 }
 
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (fb *Channel) SetLogger(logger neo.Logger) {
+	fb.logger = logger
+}
+
 func (fb *Channel) ToHear() error {
 	http.Handle("/fb-channel", fb.m)
-	fmt.Println("listening on :8080 facebook webhook: /fb-channel")
+	fb.logger.Log(neo.Info, "facebook channel listening", neo.Fields{"addr": ":8080", "path": "/fb-channel"})
 	return http.ListenAndServe(":8080", nil)
 }
 
@@ -59,9 +65,11 @@ func (fb *Channel) OnContextIsDone(callback func(c *neo.Context)) {
 }
 
 func (fb *Channel) CallContextDone(c *neo.Context) {
-	log.Println("fb, CallContextDone: ", c.SessionID)
+	fb.logger.Log(neo.Debug, "context done", neo.Fields{neo.SessionIDField: c.SessionID, neo.PersonIDField: c.Person.ID})
 	id, err := strconv.ParseInt(c.Person.ID, 10, 64)
 	if err == nil {
+		fb.mu.Lock()
 		delete(fb.contexts, id)
+		fb.mu.Unlock()
 	}
 }
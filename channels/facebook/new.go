@@ -3,7 +3,6 @@ package facebook
 import (
 	"context"
 	"fmt"
-	"log"
 	"strconv"
 
 	neo "github.com/minskylab/neocortex"
@@ -13,6 +12,7 @@ import (
 func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel, error) {
 	fb := &Channel{
 		contexts: map[int64]*neo.Context{},
+		logger:   neo.DefaultLogger(),
 	}
 
 	if len(fabric) > 0 {
@@ -23,6 +23,7 @@ func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel,
 	hook := func(msn *messenger.Messenger, user messenger.UserInfo, m messenger.FacebookMessage) {
 		uID := strconv.FormatInt(user.ID, 10)
 		tz := fmt.Sprintf("%d", int(user.Timezone))
+		fb.mu.Lock()
 		c, contextExist := fb.contexts[user.ID]
 
 		if !contextExist {
@@ -39,11 +40,14 @@ func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel,
 			}
 			fb.contexts[user.ID] = c
 		}
+		fb.mu.Unlock()
 
 		// This is because facebook channel not support entities or intents as input (from messenger chat)
 		in := fb.NewInputText(m.Text, nil, nil)
 		err := fb.messageIn(c, in, func(c *neo.Context, out *neo.Output) error {
+			fb.mu.Lock()
 			fb.contexts[user.ID] = c
+			fb.mu.Unlock()
 			err := decodeOutput(user.ID, msn, out)
 
 			if err != nil {
@@ -52,7 +56,7 @@ func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel,
 			return nil
 		})
 		if err != nil {
-			log.Println(err)
+			fb.logger.Log(neo.Error, "error resolving the message", neo.Fields{neo.PersonIDField: uID, neo.ErrorField: err})
 		}
 	}
 
@@ -61,6 +65,7 @@ func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel,
 
 		uID := strconv.FormatInt(user.ID, 10)
 		tz := fmt.Sprintf("%d", int(user.Timezone))
+		fb.mu.Lock()
 		c, contextExist := fb.contexts[user.ID]
 		if !contextExist {
 			c = fb.newContext(context.Background(), neo.PersonInfo{
@@ -74,10 +79,13 @@ func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel,
 			}
 			fb.contexts[user.ID] = c
 		}
+		fb.mu.Unlock()
 		// This is because facebook channel not support entities or intents as input (from messenger chat)
 		in := fb.NewInputText(text, nil, nil)
 		err := fb.messageIn(c, in, func(c *neo.Context, out *neo.Output) error {
+			fb.mu.Lock()
 			fb.contexts[user.ID] = c
+			fb.mu.Unlock()
 			err := decodeOutput(user.ID, msn, out)
 
 			if err != nil {
@@ -86,7 +94,7 @@ func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel,
 			return nil
 		})
 		if err != nil {
-			log.Println(err)
+			fb.logger.Log(neo.Error, "error resolving the message", neo.Fields{neo.PersonIDField: uID, neo.ErrorField: err})
 		}
 	}
 
@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"sync"
+
+	neo "github.com/minskylab/neocortex"
+	pb "github.com/minskylab/neocortex/proto"
+	"google.golang.org/grpc"
+)
+
+type Channel struct {
+	pb.UnimplementedNeocortexServer
+
+	options              ChannelOptions
+	server               *grpc.Server
+	messageIn            neo.MiddleHandler
+	newContext           neo.ContextFabric
+	mu                   sync.Mutex
+	sessions             map[string]*session
+	newContextCallbacks  []*func(c *neo.Context)
+	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
+}
+
+type ChannelOptions struct {
+	// Addr of the grpc server, by default :50051
+	Addr string
+	// ServerOptions are passed to the grpc server (credentials, interceptors, etc)
+	ServerOptions []grpc.ServerOption
+}
+
+func (ch *Channel) RegisterMessageEndpoint(handler neo.MiddleHandler) error {
+	ch.messageIn = handler
+	return nil
+}
+
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (ch *Channel) SetLogger(logger neo.Logger) {
+	ch.logger = logger
+}
+
+func (ch *Channel) ToHear() error {
+	return ch.listen()
+}
+
+func (ch *Channel) GetContextFabric() neo.ContextFabric {
+	return ch.newContext
+}
+
+func (ch *Channel) SetContextFabric(fabric neo.ContextFabric) {
+	ch.newContext = fabric
+}
+
+func (ch *Channel) OnNewContextCreated(callback func(c *neo.Context)) {
+	if ch.newContextCallbacks == nil {
+		ch.newContextCallbacks = []*func(c *neo.Context){}
+	}
+	ch.newContextCallbacks = append(ch.newContextCallbacks, &callback)
+}
+
+func (ch *Channel) OnContextIsDone(callback func(c *neo.Context)) {
+	if ch.doneContextCallbacks == nil {
+		ch.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	ch.doneContextCallbacks = append(ch.doneContextCallbacks, &callback)
+}
+
+func (ch *Channel) CallContextDone(c *neo.Context) {
+	ch.mu.Lock()
+	var done *session
+	for id, s := range ch.sessions {
+		if s.context() == c {
+			done = s
+			delete(ch.sessions, id)
+			break
+		}
+	}
+	ch.mu.Unlock()
+
+	if done != nil {
+		done.send(&pb.ConverseResponse{Context: ch.toProtoContext(done.id, c), Closed: true})
+	}
+}
+
+// Register mounts the Neocortex service into an external grpc server, in that case
+// ToHear is not needed to serve the channel
+func (ch *Channel) Register(s *grpc.Server) {
+	pb.RegisterNeocortexServer(s, ch)
+}
@@ -0,0 +1,234 @@
+package grpc
+
+import (
+	"encoding/json"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+	pb "github.com/minskylab/neocortex/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func toProtoPerson(p neo.PersonInfo) *pb.PersonInfo {
+	return &pb.PersonInfo{
+		Id:       p.ID,
+		Timezone: p.Timezone,
+		Picture:  p.Picture,
+		Locale:   p.Locale,
+		Name:     p.Name,
+	}
+}
+
+func fromProtoPerson(p *pb.PersonInfo) neo.PersonInfo {
+	return neo.PersonInfo{
+		ID:       p.GetId(),
+		Timezone: p.GetTimezone(),
+		Picture:  p.GetPicture(),
+		Locale:   p.GetLocale(),
+		Name:     p.GetName(),
+	}
+}
+
+// toProtoContext answers the id of the grpc session as the session_id, the clients resume
+// the session with it and the session id of the cognitive service is not changed
+func (ch *Channel) toProtoContext(id string, c *neo.Context) *pb.Context {
+	if c == nil {
+		return nil
+	}
+	return &pb.Context{
+		SessionId: id,
+		Person:    toProtoPerson(c.Person),
+		Variables: ch.toStruct(c.Variables),
+	}
+}
+
+func fromProtoInput(in *pb.Input) *neo.Input {
+	input := &neo.Input{
+		Data: neo.InputData{
+			Type:  neo.InputType(in.GetData().GetType()),
+			Value: in.GetData().GetValue(),
+			Data:  in.GetData().GetData(),
+		},
+		Entities: []neo.Entity{},
+		Intents:  []neo.Intent{},
+	}
+
+	if input.Data.Type == neo.InputText && len(input.Data.Data) == 0 {
+		input.Data.Data = []byte(input.Data.Value)
+	}
+
+	for _, e := range in.GetEntities() {
+		input.Entities = append(input.Entities, neo.Entity{
+			Entity:     e.Entity,
+			Location:   e.Location,
+			Value:      e.Value,
+			Confidence: e.Confidence,
+			Metadata:   e.GetMetadata().AsMap(),
+		})
+	}
+
+	for _, i := range in.GetIntents() {
+		input.Intents = append(input.Intents, neo.Intent{
+			Intent:     i.Intent,
+			Confidence: i.Confidence,
+		})
+	}
+
+	return input
+}
+
+func (ch *Channel) toProtoOutput(out *neo.Output) *pb.Output {
+	output := &pb.Output{}
+
+	for _, e := range out.Entities {
+		output.Entities = append(output.Entities, &pb.Entity{
+			Entity:     e.Entity,
+			Location:   e.Location,
+			Value:      e.Value,
+			Confidence: e.Confidence,
+			Metadata:   ch.toStruct(e.Metadata),
+		})
+	}
+
+	for _, i := range out.Intents {
+		output.Intents = append(output.Intents, &pb.Intent{
+			Intent:     i.Intent,
+			Confidence: i.Confidence,
+		})
+	}
+
+	for _, n := range out.VisitedNodes {
+		if n == nil {
+			continue
+		}
+		output.VisitedNodes = append(output.VisitedNodes, &pb.DialogNode{
+			Name:       n.Name,
+			Title:      n.Title,
+			Conditions: n.Conditions,
+		})
+	}
+
+	for _, l := range out.Logs {
+		if l == nil {
+			continue
+		}
+		output.Logs = append(output.Logs, &pb.LogMessage{
+			Level:   string(l.Level),
+			Message: l.Message,
+		})
+	}
+
+	for _, r := range out.Responses {
+		output.Responses = append(output.Responses, ch.toProtoResponse(r))
+	}
+
+	return output
+}
+
+func (ch *Channel) toProtoResponse(r neo.Response) *pb.Response {
+	res := &pb.Response{
+		IsTyping: r.IsTyping,
+		Type:     string(r.Type),
+	}
+
+	switch v := r.Value.(type) {
+	case string:
+		if r.Type == neo.Image {
+			res.Value = &pb.Response_ImageUrl{ImageUrl: v}
+		} else {
+			res.Value = &pb.Response_Text{Text: v}
+		}
+	case time.Duration:
+		res.Value = &pb.Response_PauseMillis{PauseMillis: v.Milliseconds()}
+	case neo.OptionsResponse:
+		res.Value = &pb.Response_Option{Option: toProtoOptions(&v)}
+	case *neo.OptionsResponse:
+		res.Value = &pb.Response_Option{Option: toProtoOptions(v)}
+	case []neo.OptionsResponse:
+		list := &pb.OptionsList{}
+		for i := range v {
+			list.Items = append(list.Items, toProtoOptions(&v[i]))
+		}
+		res.Value = &pb.Response_Options{Options: list}
+	default:
+		res.Value = &pb.Response_Raw{Raw: ch.toValue(v)}
+	}
+
+	return res
+}
+
+func toProtoOptions(o *neo.OptionsResponse) *pb.OptionsResponse {
+	res := &pb.OptionsResponse{
+		Title:       o.Title,
+		Description: o.Description,
+		ItemUrl:     o.ItemURL,
+		Image:       o.Image,
+	}
+	for _, opt := range o.Options {
+		if opt == nil {
+			continue
+		}
+		res.Options = append(res.Options, &pb.Option{
+			Text:       opt.Text,
+			Action:     opt.Action,
+			IsPostBack: opt.IsPostBack,
+		})
+	}
+	return res
+}
+
+// toStruct converts a map into a protobuf struct, the values that structpb doesn't know
+// (e.g. typed slices or structs) are converted through json, the failures are logged by the channel
+func (ch *Channel) toStruct(m map[string]interface{}) *structpb.Struct {
+	if m == nil {
+		return nil
+	}
+
+	s, err := structpb.NewStruct(m)
+	if err == nil {
+		return s
+	}
+
+	generic := map[string]interface{}{}
+	if err = jsonRoundTrip(m, &generic); err != nil {
+		ch.logger.Log(neo.Warn, "can't convert the map to a protobuf struct", neo.Fields{neo.ErrorField: err})
+		return nil
+	}
+
+	s, err = structpb.NewStruct(generic)
+	if err != nil {
+		ch.logger.Log(neo.Warn, "can't convert the map to a protobuf struct", neo.Fields{neo.ErrorField: err})
+		return nil
+	}
+
+	return s
+}
+
+func (ch *Channel) toValue(v interface{}) *structpb.Value {
+	value, err := structpb.NewValue(v)
+	if err == nil {
+		return value
+	}
+
+	var generic interface{}
+	if err = jsonRoundTrip(v, &generic); err != nil {
+		ch.logger.Log(neo.Warn, "can't convert the value to a protobuf value", neo.Fields{neo.ErrorField: err})
+		return structpb.NewNullValue()
+	}
+
+	value, err = structpb.NewValue(generic)
+	if err != nil {
+		ch.logger.Log(neo.Warn, "can't convert the value to a protobuf value", neo.Fields{neo.ErrorField: err})
+		return structpb.NewNullValue()
+	}
+
+	return value
+}
+
+func jsonRoundTrip(from interface{}, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
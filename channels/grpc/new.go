@@ -0,0 +1,33 @@
+// Package grpc implements a neocortex channel over gRPC, the service is defined in proto/neocortex.proto.
+// Every Converse stream holds one session, the first request opens it (or resumes it with the session_id and
+// the resume_token given when the session was opened, without a valid token a new session is created).
+// The inputs that can't be resolved are answered with an error and the stream is kept open.
+package grpc
+
+import (
+	neo "github.com/minskylab/neocortex"
+	pb "github.com/minskylab/neocortex/proto"
+	"google.golang.org/grpc"
+)
+
+func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel, error) {
+	if options.Addr == "" {
+		options.Addr = ":50051"
+	}
+
+	ch := &Channel{
+		options:  options,
+		sessions: map[string]*session{},
+		logger:   neo.DefaultLogger(),
+	}
+
+	ch.server = grpc.NewServer(options.ServerOptions...)
+	pb.RegisterNeocortexServer(ch.server, ch)
+
+	if len(fabric) > 0 {
+		f := fabric[0]
+		ch.newContext = f
+	}
+
+	return ch, nil
+}
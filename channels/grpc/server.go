@@ -0,0 +1,141 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	neo "github.com/minskylab/neocortex"
+	pb "github.com/minskylab/neocortex/proto"
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (ch *Channel) listen() error {
+	lis, err := net.Listen("tcp", ch.options.Addr)
+	if err != nil {
+		return err
+	}
+
+	ch.logger.Log(neo.Info, "grpc channel listening", neo.Fields{"addr": ch.options.Addr})
+	return ch.server.Serve(lis)
+}
+
+// Converse implements the bidirectional stream of the Neocortex service
+func (ch *Channel) Converse(stream pb.Neocortex_ConverseServer) error {
+	var s *session
+	defer func() {
+		if s != nil {
+			s.detach(stream)
+		}
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if s == nil || (req.SessionId != "" && req.SessionId != s.id) || !ch.isOpen(s) {
+			if s == nil && req.SessionId == "" && req.Person == nil {
+				return status.Error(codes.InvalidArgument, "the first request must have the person or the session_id")
+			}
+
+			person := req.Person
+			if s != nil && person == nil {
+				// the engine closed the session, a new one is opened for the same person
+				person = toProtoPerson(s.context().Person)
+			}
+
+			if s != nil {
+				s.detach(stream)
+			}
+
+			s, err = ch.openSession(stream.Context(), req.SessionId, req.ResumeToken, person)
+			if err != nil {
+				ch.logger.Log(neo.Error, "error opening the session", neo.Fields{neo.ErrorField: err})
+				return status.Error(codes.Internal, "the session can't be opened")
+			}
+			s.attach(stream)
+			s.send(&pb.ConverseResponse{Context: ch.toProtoContext(s.id, s.context()), ResumeToken: s.token})
+		}
+
+		if req.Input != nil {
+			if err = ch.resolve(s, req.Input); err != nil {
+				// the error is answered into the stream, the next inputs of the session can be resolved
+				ch.logger.Log(neo.Error, "error resolving the input", neo.Fields{neo.SessionIDField: s.id, neo.ErrorField: err})
+				s.send(&pb.ConverseResponse{Context: ch.toProtoContext(s.id, s.context()), Error: err.Error()})
+			}
+		}
+	}
+}
+
+// openSession resumes the session if it exists and the token is its resume token,
+// otherwise a new session is created
+func (ch *Channel) openSession(ctx context.Context, sessionID, token string, person *pb.PersonInfo) (*session, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if s, exist := ch.sessions[sessionID]; exist && sessionID != "" && s.resumableWith(token) {
+		return s, nil
+	}
+
+	token, err := newResumeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	info := fromProtoPerson(person)
+	if info.ID == "" {
+		info.ID = xid.New().String()
+	}
+
+	// the context of the stream is not used because the session lives more than the stream
+	c := ch.newContext(context.Background(), info)
+	for _, call := range ch.newContextCallbacks {
+		(*call)(c)
+	}
+
+	// the clients resume the session with the session id of the context, a new session
+	// never takes the place of another one, in that case the session has its own id and
+	// the session id of the context is kept for the cognitive service
+	id := c.SessionID
+	if _, exist := ch.sessions[id]; id == "" || exist {
+		id = xid.New().String()
+	}
+
+	s := &session{id: id, token: token, c: c, logger: ch.logger}
+	ch.sessions[id] = s
+
+	return s, nil
+}
+
+func (ch *Channel) isOpen(s *session) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	_, exist := ch.sessions[s.id]
+	return exist
+}
+
+func (ch *Channel) resolve(s *session, input *pb.Input) error {
+	in := fromProtoInput(input)
+	if in.Data.Type == "" {
+		in.Data.Type = neo.InputText
+	}
+
+	if in.Data.Type != neo.InputText && in.Data.Value == "" && len(in.Data.Data) == 0 {
+		return errors.New("empty input")
+	}
+
+	return ch.messageIn(s.context(), in, func(c *neo.Context, out *neo.Output) error {
+		s.setContext(c)
+		s.send(&pb.ConverseResponse{Context: ch.toProtoContext(s.id, c), Output: ch.toProtoOutput(out)})
+		return nil
+	})
+}
@@ -0,0 +1,210 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	neo "github.com/minskylab/neocortex"
+	pb "github.com/minskylab/neocortex/proto"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// echo answers the text of the input, the text "fail" can't be resolved
+type echo struct{}
+
+func (e *echo) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return &neo.Context{Context: c, SessionID: "session-" + info.ID, Person: info, Variables: map[string]interface{}{}}
+}
+
+func (e *echo) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if in.Data.Value == "fail" {
+		return nil, errors.New("the echo failed")
+	}
+	return &neo.Output{Responses: []neo.Response{{Type: neo.Text, Value: "echo: " + in.Data.Value}}}, nil
+}
+
+func (e *echo) OnContextIsDone(callback func(c *neo.Context)) {}
+
+func newTestClient(t *testing.T) (*Channel, pb.NeocortexClient) {
+	t.Helper()
+
+	ch, err := NewChannel(ChannelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := neo.New(&echo{}, []neo.CommunicationChannel{ch}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = ch.server.Serve(lis) }()
+	t.Cleanup(ch.server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return ch, pb.NewNeocortexClient(conn)
+}
+
+func converse(t *testing.T, client pb.NeocortexClient) pb.Neocortex_ConverseClient {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	stream, err := client.Converse(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stream
+}
+
+func exchange(t *testing.T, stream pb.Neocortex_ConverseClient, req *pb.ConverseRequest) *pb.ConverseResponse {
+	t.Helper()
+
+	if err := stream.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func textInput(text string) *pb.Input {
+	return &pb.Input{Data: &pb.InputData{Type: string(neo.InputText), Value: text}}
+}
+
+func TestConverse(t *testing.T) {
+	_, client := newTestClient(t)
+	stream := converse(t, client)
+
+	opened := exchange(t, stream, &pb.ConverseRequest{Person: &pb.PersonInfo{Id: "42", Name: "Ann"}})
+	if opened.GetContext().GetSessionId() == "" || opened.GetContext().GetPerson().GetName() != "Ann" {
+		t.Fatalf("the session must be opened with the context, got %v", opened)
+	}
+	if len(opened.GetResumeToken()) < 43 {
+		t.Errorf("the resume token must have 32 random bytes, got %q", opened.GetResumeToken())
+	}
+
+	res := exchange(t, stream, &pb.ConverseRequest{Input: textInput("hi")})
+	if got := res.GetOutput().GetResponses(); len(got) != 1 || got[0].GetText() != "echo: hi" {
+		t.Fatalf("expected the echo of the input, got %v", res)
+	}
+}
+
+func TestErrorsKeepTheStreamOpen(t *testing.T) {
+	_, client := newTestClient(t)
+	stream := converse(t, client)
+	exchange(t, stream, &pb.ConverseRequest{Person: &pb.PersonInfo{Id: "42"}})
+
+	res := exchange(t, stream, &pb.ConverseRequest{Input: textInput("fail")})
+	if res.GetError() == "" || res.GetOutput() != nil {
+		t.Fatalf("the error must be answered into the stream, got %v", res)
+	}
+
+	res = exchange(t, stream, &pb.ConverseRequest{Input: &pb.Input{Data: &pb.InputData{Type: string(neo.InputAudio)}}})
+	if res.GetError() == "" {
+		t.Fatalf("an empty input must be answered with an error, got %v", res)
+	}
+
+	res = exchange(t, stream, &pb.ConverseRequest{Input: textInput("still here")})
+	if got := res.GetOutput().GetResponses(); len(got) != 1 || got[0].GetText() != "echo: still here" {
+		t.Fatalf("the stream must keep resolving the inputs, got %v", res)
+	}
+}
+
+func TestResume(t *testing.T) {
+	_, client := newTestClient(t)
+
+	first := converse(t, client)
+	opened := exchange(t, first, &pb.ConverseRequest{Person: &pb.PersonInfo{Id: "42"}})
+	sessionID := opened.GetContext().GetSessionId()
+
+	for _, token := range []string{"", "guessed", opened.GetResumeToken()[1:]} {
+		intruder := converse(t, client)
+		res := exchange(t, intruder, &pb.ConverseRequest{SessionId: sessionID, ResumeToken: token, Person: &pb.PersonInfo{Id: "666"}})
+		if res.GetContext().GetSessionId() == sessionID || res.GetResumeToken() == opened.GetResumeToken() {
+			t.Fatalf("the session can't be resumed with the token %q", token)
+		}
+	}
+
+	second := converse(t, client)
+	resumed := exchange(t, second, &pb.ConverseRequest{SessionId: sessionID, ResumeToken: opened.GetResumeToken()})
+	if resumed.GetContext().GetSessionId() != sessionID || resumed.GetContext().GetPerson().GetId() != "42" {
+		t.Fatalf("the session must be resumed with its token, got %v", resumed)
+	}
+
+	res := exchange(t, second, &pb.ConverseRequest{Input: textInput("back")})
+	if res.GetContext().GetSessionId() != sessionID {
+		t.Errorf("the input must be resolved into the resumed session, got %v", res)
+	}
+}
+
+func TestTheSessionIDOfTheCognitiveIsKept(t *testing.T) {
+	ch, client := newTestClient(t)
+
+	first := exchange(t, converse(t, client), &pb.ConverseRequest{Person: &pb.PersonInfo{Id: "42"}})
+	second := exchange(t, converse(t, client), &pb.ConverseRequest{Person: &pb.PersonInfo{Id: "42"}})
+	firstID, secondID := first.GetContext().GetSessionId(), second.GetContext().GetSessionId()
+	if firstID == secondID {
+		t.Fatalf("a new session can't take the place of another one, got %q twice", firstID)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	for _, id := range []string{firstID, secondID} {
+		s, exist := ch.sessions[id]
+		if !exist {
+			t.Fatalf("the session %q must be open", id)
+		}
+		if got := s.context().SessionID; got != "session-42" {
+			t.Errorf("the session id of the cognitive service can't be changed, got %q", got)
+		}
+	}
+}
+
+func TestConvertWithTheChannelLogger(t *testing.T) {
+	ch, err := NewChannel(ChannelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logged := 0
+	ch.SetLogger(loggerFunc(func(level neo.LogLevelType, message string, fields neo.Fields) { logged++ }))
+
+	if v := ch.toValue(func() {}); v.GetNullValue() != 0 || logged != 1 {
+		t.Errorf("a value that can't be converted must be logged by the channel, logged %d", logged)
+	}
+	if s := ch.toStruct(map[string]interface{}{"f": func() {}}); s != nil || logged != 2 {
+		t.Errorf("a map that can't be converted must be logged by the channel, logged %d", logged)
+	}
+}
+
+type loggerFunc func(level neo.LogLevelType, message string, fields neo.Fields)
+
+func (f loggerFunc) Log(level neo.LogLevelType, message string, fields neo.Fields) {
+	f(level, message, fields)
+}
@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"sync"
+
+	neo "github.com/minskylab/neocortex"
+	pb "github.com/minskylab/neocortex/proto"
+)
+
+// session survives to the streams, a new stream can resume it with the session id
+type session struct {
+	id    string
+	token string
+
+	mu     sync.Mutex
+	c      *neo.Context
+	stream pb.Neocortex_ConverseServer
+	logger neo.Logger
+}
+
+// newResumeToken returns 32 random bytes encoded as base64, the session ids can be guessed
+// so the token is the secret that allows to resume a session
+func newResumeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// resumableWith compares the token in constant time
+func (s *session) resumableWith(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(s.token), []byte(token)) == 1
+}
+
+func (s *session) context() *neo.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c
+}
+
+func (s *session) setContext(c *neo.Context) {
+	s.mu.Lock()
+	s.c = c
+	s.mu.Unlock()
+}
+
+func (s *session) attach(stream pb.Neocortex_ConverseServer) {
+	s.mu.Lock()
+	s.stream = stream
+	s.mu.Unlock()
+}
+
+func (s *session) detach(stream pb.Neocortex_ConverseServer) {
+	s.mu.Lock()
+	if s.stream == stream {
+		s.stream = nil
+	}
+	s.mu.Unlock()
+}
+
+// send writes into the current stream, the grpc streams don't support concurrent writes
+func (s *session) send(res *pb.ConverseResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stream == nil {
+		return
+	}
+
+	if err := s.stream.Send(res); err != nil {
+		s.logger.Log(neo.Warn, "error sending the response", neo.Fields{neo.SessionIDField: s.id, neo.ErrorField: err})
+	}
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"sync"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+type Channel struct {
+	options              ChannelOptions
+	messageIn            neo.MiddleHandler
+	newContext           neo.ContextFabric
+	mu                   sync.Mutex
+	sessions             map[string]*neo.Context
+	newContextCallbacks  []*func(c *neo.Context)
+	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
+}
+
+type ChannelOptions struct {
+	// Addr of the http server, by default :8082
+	Addr string
+	// Prefix of the endpoints, e.g. /bot (by default empty)
+	Prefix string
+	// Token is required in the Authorization header as "Bearer <token>" if it's not empty
+	Token string
+}
+
+func (ch *Channel) RegisterMessageEndpoint(handler neo.MiddleHandler) error {
+	ch.messageIn = handler
+	return nil
+}
+
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (ch *Channel) SetLogger(logger neo.Logger) {
+	ch.logger = logger
+}
+
+func (ch *Channel) ToHear() error {
+	return ch.listen()
+}
+
+func (ch *Channel) GetContextFabric() neo.ContextFabric {
+	return ch.newContext
+}
+
+func (ch *Channel) SetContextFabric(fabric neo.ContextFabric) {
+	ch.newContext = fabric
+}
+
+func (ch *Channel) OnNewContextCreated(callback func(c *neo.Context)) {
+	if ch.newContextCallbacks == nil {
+		ch.newContextCallbacks = []*func(c *neo.Context){}
+	}
+	ch.newContextCallbacks = append(ch.newContextCallbacks, &callback)
+}
+
+func (ch *Channel) OnContextIsDone(callback func(c *neo.Context)) {
+	if ch.doneContextCallbacks == nil {
+		ch.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	ch.doneContextCallbacks = append(ch.doneContextCallbacks, &callback)
+}
+
+func (ch *Channel) CallContextDone(c *neo.Context) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	for id, sc := range ch.sessions {
+		if sc == c {
+			delete(ch.sessions, id)
+			return
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package http
+
+import neo "github.com/minskylab/neocortex"
+
+func (ch *Channel) NewInputText(text string, i []neo.Intent, e []neo.Entity) *neo.Input {
+	t := neo.InputData{
+		Type:  neo.InputText,
+		Value: text,
+		Data:  []byte(text),
+	}
+	return ch.NewInput(t, i, e)
+}
@@ -0,0 +1,33 @@
+// Package http implements a synchronous request-response channel, useful to integrate any backend with a bot.
+//
+//	POST {prefix}/message            {"session_id": "", "person": {...}, "text": "hello"}
+//	                              -> {"session_id": "bq4f...", "person": {...}, "responses": [...]}
+//	DELETE {prefix}/sessions/{id}  -> {"session_id": "bq4f...", "closed": true}
+//
+// If the session_id is empty a new session is created with an ID generated by the channel, otherwise the session
+// is reused. An unknown session_id (e.g. a session closed by the engine) is rejected with 404, the client must
+// start a new session with an empty session_id.
+package http
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel, error) {
+	if options.Addr == "" {
+		options.Addr = ":8082"
+	}
+
+	ch := &Channel{
+		options:  options,
+		sessions: map[string]*neo.Context{},
+		logger:   neo.DefaultLogger(),
+	}
+
+	if len(fabric) > 0 {
+		f := fabric[0]
+		ch.newContext = f
+	}
+
+	return ch, nil
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// normalizeResponses converts the pauses into milliseconds, the other responses keep their value
+func normalizeResponses(responses []neo.Response) []neo.Response {
+	normalized := make([]neo.Response, 0, len(responses))
+	for _, r := range responses {
+		if r.Type == neo.Pause {
+			if d, ok := r.Value.(time.Duration); ok {
+				r.Value = d.Milliseconds()
+			}
+		}
+		normalized = append(normalized, r)
+	}
+	return normalized
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// errSessionNotFound is returned when the client sends a session_id unknown by the channel
+var errSessionNotFound = errors.New("session not found")
+
+// MessageRequest is the body of POST /message
+type MessageRequest struct {
+	SessionID string         `json:"session_id"`
+	Person    neo.PersonInfo `json:"person"`
+	Text      string         `json:"text"`
+}
+
+// MessageResponse contains all the responses given by the bot to one message
+type MessageResponse struct {
+	SessionID string         `json:"session_id"`
+	Person    neo.PersonInfo `json:"person"`
+	Responses []neo.Response `json:"responses"`
+}
+
+func (ch *Channel) listen() error {
+	ch.logger.Log(neo.Info, "http channel listening", neo.Fields{"addr": ch.options.Addr, "prefix": ch.options.Prefix})
+	return http.ListenAndServe(ch.options.Addr, ch)
+}
+
+// ServeHTTP handles the endpoints of the channel, it can be mounted into your own server
+func (ch *Channel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if ch.options.Token != "" && !ch.authorized(r) {
+		ch.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or missing token"})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, ch.options.Prefix)
+	switch {
+	case path == "/message" && r.Method == http.MethodPost:
+		ch.handleMessage(w, r)
+	case strings.HasPrefix(path, "/sessions/") && r.Method == http.MethodDelete:
+		ch.handleEndSession(w, strings.TrimPrefix(path, "/sessions/"))
+	default:
+		ch.writeJSON(w, http.StatusNotFound, map[string]string{"error": "endpoint not found"})
+	}
+}
+
+// authorized compares the bearer token in constant time
+func (ch *Channel) authorized(r *http.Request) bool {
+	expected := []byte("Bearer " + ch.options.Token)
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) == 1
+}
+
+func (ch *Channel) handleMessage(w http.ResponseWriter, r *http.Request) {
+	req := new(MessageRequest)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		ch.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body: " + err.Error()})
+		return
+	}
+
+	sessionID, c, err := ch.getSession(req.SessionID, req.Person)
+	if errors.Is(err, errSessionNotFound) {
+		ch.writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ch.logger.Log(neo.Error, "error creating the session", neo.Fields{neo.ErrorField: err})
+		ch.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "the session can't be created"})
+		return
+	}
+
+	var mu sync.Mutex
+	responses := make([]neo.Response, 0)
+
+	in := ch.NewInputText(req.Text, nil, nil)
+	err = ch.messageIn(c, in, func(c *neo.Context, out *neo.Output) error {
+		ch.mu.Lock()
+		if _, exist := ch.sessions[sessionID]; exist {
+			ch.sessions[sessionID] = c
+		}
+		ch.mu.Unlock()
+
+		mu.Lock()
+		responses = append(responses, normalizeResponses(out.Responses)...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		ch.logger.Log(neo.Error, "error resolving the message", neo.Fields{neo.SessionIDField: sessionID, neo.ErrorField: err})
+		ch.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	ch.writeJSON(w, http.StatusOK, MessageResponse{
+		SessionID: sessionID,
+		Person:    c.Person,
+		Responses: responses,
+	})
+}
+
+func (ch *Channel) handleEndSession(w http.ResponseWriter, sessionID string) {
+	ch.mu.Lock()
+	c, exist := ch.sessions[sessionID]
+	ch.mu.Unlock()
+
+	if !exist {
+		ch.writeJSON(w, http.StatusNotFound, map[string]string{"error": errSessionNotFound.Error()})
+		return
+	}
+
+	for _, call := range ch.doneContextCallbacks {
+		(*call)(c)
+	}
+
+	// if the channel is used without engine nobody calls CallContextDone
+	ch.mu.Lock()
+	delete(ch.sessions, sessionID)
+	ch.mu.Unlock()
+
+	ch.writeJSON(w, http.StatusOK, map[string]interface{}{"session_id": sessionID, "closed": true})
+}
+
+// getSession reuses the session of the ID, without ID a new one is created with the context fabric.
+// The IDs are only generated by the channel, the unknown IDs are rejected
+func (ch *Channel) getSession(sessionID string, person neo.PersonInfo) (string, *neo.Context, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if sessionID != "" {
+		c, exist := ch.sessions[sessionID]
+		if !exist {
+			return "", nil, errSessionNotFound
+		}
+		return sessionID, c, nil
+	}
+
+	sessionID, err := newSessionID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if person.ID == "" {
+		person.ID = sessionID
+	}
+
+	c := ch.newContext(context.Background(), person)
+	for _, call := range ch.newContextCallbacks {
+		(*call)(c)
+	}
+	ch.sessions[sessionID] = c
+
+	return sessionID, c, nil
+}
+
+// newSessionID returns 16 random bytes encoded as base64, the ID is the only credential of the session
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (ch *Channel) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		ch.logger.Log(neo.Warn, "error writing the response", neo.Fields{neo.ErrorField: err})
+	}
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// echo answers the text of the input
+type echo struct{}
+
+func (e *echo) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return &neo.Context{Context: c, SessionID: "session-" + info.ID, Person: info, Variables: map[string]interface{}{}}
+}
+
+func (e *echo) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	return &neo.Output{Responses: []neo.Response{{Type: neo.Text, Value: "echo: " + in.Data.Value}}}, nil
+}
+
+func (e *echo) OnContextIsDone(callback func(c *neo.Context)) {}
+
+func newTestChannel(t *testing.T, options ChannelOptions) *Channel {
+	t.Helper()
+
+	ch, err := NewChannel(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := neo.New(&echo{}, []neo.CommunicationChannel{ch}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+	return ch
+}
+
+func request(ch *Channel, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	r := httptest.NewRequest(method, path, strings.NewReader(string(data)))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	ch.ServeHTTP(w, r)
+	return w
+}
+
+func message(t *testing.T, ch *Channel, req MessageRequest) MessageResponse {
+	t.Helper()
+
+	w := request(ch, http.MethodPost, "/message", "", req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	res := MessageResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestMessageAndSessions(t *testing.T) {
+	ch := newTestChannel(t, ChannelOptions{})
+
+	first := message(t, ch, MessageRequest{Person: neo.PersonInfo{ID: "42"}, Text: "hi"})
+	if first.SessionID == "" || len(first.Responses) != 1 || first.Responses[0].Value != "echo: hi" {
+		t.Fatalf("unexpected response %+v", first)
+	}
+
+	second := message(t, ch, MessageRequest{SessionID: first.SessionID, Text: "again"})
+	if second.SessionID != first.SessionID || second.Person.ID != "42" {
+		t.Errorf("the session must be reused, got %+v", second)
+	}
+
+	other := message(t, ch, MessageRequest{Person: neo.PersonInfo{ID: "43"}, Text: "hi"})
+	if other.SessionID == first.SessionID {
+		t.Error("every new session must have its own ID")
+	}
+
+	w := request(ch, http.MethodDelete, "/sessions/"+first.SessionID, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	// a closed session can't be used anymore
+	w = request(ch, http.MethodPost, "/message", "", MessageRequest{SessionID: first.SessionID, Text: "hi"})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("a closed session must be rejected with 404, got %d", w.Code)
+	}
+	w = request(ch, http.MethodDelete, "/sessions/"+first.SessionID, "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestUnknownSessionIsRejected(t *testing.T) {
+	ch := newTestChannel(t, ChannelOptions{})
+
+	w := request(ch, http.MethodPost, "/message", "", MessageRequest{SessionID: "chosen-by-the-client", Text: "hi"})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if len(ch.sessions) != 0 {
+		t.Errorf("the unknown session can't be created, got %d sessions", len(ch.sessions))
+	}
+}
+
+func TestToken(t *testing.T) {
+	ch := newTestChannel(t, ChannelOptions{Token: "s3cret", Prefix: "/bot"})
+
+	for _, token := range []string{"", "wrong", "s3cre", "s3cret "} {
+		if w := request(ch, http.MethodPost, "/bot/message", token, MessageRequest{Text: "hi"}); w.Code != http.StatusUnauthorized {
+			t.Errorf("the token %q must be rejected, got %d", token, w.Code)
+		}
+	}
+
+	if w := request(ch, http.MethodPost, "/bot/message", "s3cret", MessageRequest{Text: "hi"}); w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+	if w := request(ch, http.MethodGet, "/bot/message", "s3cret", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
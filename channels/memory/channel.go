@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"sync"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+type Channel struct {
+	messageIn            neo.MiddleHandler
+	newContext           neo.ContextFabric
+	mu                   sync.Mutex
+	contexts             map[string]*neo.Context
+	ended                map[string]bool
+	newContextCallbacks  []*func(c *neo.Context)
+	doneContextCallbacks []*func(c *neo.Context)
+}
+
+func (ch *Channel) RegisterMessageEndpoint(handler neo.MiddleHandler) error {
+	ch.messageIn = handler
+	return nil
+}
+
+// ToHear doesn't listen anything, the messages are sent with Say and Send
+func (ch *Channel) ToHear() error {
+	return nil
+}
+
+func (ch *Channel) GetContextFabric() neo.ContextFabric {
+	return ch.newContext
+}
+
+func (ch *Channel) SetContextFabric(fabric neo.ContextFabric) {
+	ch.newContext = fabric
+}
+
+func (ch *Channel) OnNewContextCreated(callback func(c *neo.Context)) {
+	if ch.newContextCallbacks == nil {
+		ch.newContextCallbacks = []*func(c *neo.Context){}
+	}
+	ch.newContextCallbacks = append(ch.newContextCallbacks, &callback)
+}
+
+func (ch *Channel) OnContextIsDone(callback func(c *neo.Context)) {
+	if ch.doneContextCallbacks == nil {
+		ch.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	ch.doneContextCallbacks = append(ch.doneContextCallbacks, &callback)
+}
+
+func (ch *Channel) CallContextDone(c *neo.Context) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	for id, context := range ch.contexts {
+		if context == c {
+			delete(ch.contexts, id)
+			ch.ended[id] = true
+		}
+	}
+}
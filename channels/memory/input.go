@@ -0,0 +1,11 @@
+package memory
+
+import neo "github.com/minskylab/neocortex"
+
+func (ch *Channel) NewInput(data neo.InputData, i []neo.Intent, e []neo.Entity) *neo.Input {
+	return &neo.Input{
+		Data:     data,
+		Intents:  i,
+		Entities: e,
+	}
+}
@@ -0,0 +1,20 @@
+// Package memory is an in-memory channel for tests, the messages are sent synchronously
+// and the outputs of the engine are returned to the caller
+package memory
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+func NewChannel(fabric ...neo.ContextFabric) *Channel {
+	ch := &Channel{
+		contexts: map[string]*neo.Context{},
+		ended:    map[string]bool{},
+	}
+
+	if len(fabric) > 0 {
+		ch.newContext = fabric[0]
+	}
+
+	return ch
+}
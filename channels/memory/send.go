@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+var ErrNotRegistered = errors.New("the channel is not registered into an engine")
+
+// Exchange is the result of one message, the outputs are in the order that the resolvers sent them
+type Exchange struct {
+	Context *neo.Context
+	Outputs []*neo.Output
+	// Ended is true if the conversation was closed during the message
+	Ended bool
+}
+
+// Responses returns the responses of all the outputs
+func (e *Exchange) Responses() []neo.Response {
+	responses := make([]neo.Response, 0)
+	for _, out := range e.Outputs {
+		responses = append(responses, out.Responses...)
+	}
+	return responses
+}
+
+// Texts returns the text responses
+func (e *Exchange) Texts() []string {
+	texts := make([]string, 0)
+	for _, r := range e.Responses() {
+		if t, ok := r.Value.(string); ok && r.Type == neo.Text {
+			texts = append(texts, t)
+		}
+	}
+	return texts
+}
+
+// Open returns the context of the person, it is created if the person doesn't have one
+func (ch *Channel) Open(person neo.PersonInfo) (*neo.Context, error) {
+	if ch.newContext == nil {
+		return nil, ErrNotRegistered
+	}
+
+	ch.mu.Lock()
+	c, exist := ch.contexts[person.ID]
+	ch.mu.Unlock()
+
+	if exist {
+		return c, nil
+	}
+
+	c = ch.newContext(context.Background(), person)
+
+	ch.mu.Lock()
+	ch.contexts[person.ID] = c
+	delete(ch.ended, person.ID)
+	ch.mu.Unlock()
+
+	for _, call := range ch.newContextCallbacks {
+		(*call)(c)
+	}
+
+	return c, nil
+}
+
+// Say sends a text as the person
+func (ch *Channel) Say(person neo.PersonInfo, text string) (*Exchange, error) {
+	return ch.Send(person, ch.NewInputText(text, nil, nil))
+}
+
+// Send sends the input to the engine and waits for its outputs
+func (ch *Channel) Send(person neo.PersonInfo, in *neo.Input) (*Exchange, error) {
+	if ch.messageIn == nil {
+		return nil, ErrNotRegistered
+	}
+
+	c, err := ch.Open(person)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := &Exchange{Context: c, Outputs: []*neo.Output{}}
+	err = ch.messageIn(c, in, func(c *neo.Context, out *neo.Output) error {
+		exchange.Context = c
+		exchange.Outputs = append(exchange.Outputs, out)
+		return nil
+	})
+
+	ch.mu.Lock()
+	exchange.Ended = ch.ended[person.ID]
+	delete(ch.ended, person.ID)
+	ch.mu.Unlock()
+
+	return exchange, err
+}
+
+// End closes the conversation of the person like the other channels do when a session expires
+func (ch *Channel) End(personID string) {
+	ch.mu.Lock()
+	c, exist := ch.contexts[personID]
+	ch.mu.Unlock()
+
+	if !exist {
+		return
+	}
+
+	for _, call := range ch.doneContextCallbacks {
+		(*call)(c)
+	}
+
+	ch.mu.Lock()
+	if ch.contexts[personID] == c {
+		delete(ch.contexts, personID)
+	}
+	delete(ch.ended, personID)
+	ch.mu.Unlock()
+}
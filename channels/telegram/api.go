@@ -0,0 +1,182 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultAPIURL = "https://api.telegram.org"
+
+// User represents a telegram user or bot
+type User struct {
+	ID           int64  `json:"id"`
+	IsBot        bool   `json:"is_bot"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// Chat represents a private chat, group or channel
+type Chat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// Message is an incoming telegram message, only the text messages are supported
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from"`
+	Chat      Chat   `json:"chat"`
+	Date      int64  `json:"date"`
+	Text      string `json:"text"`
+}
+
+// CallbackQuery is sent when a user press a button of an inline keyboard
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message"`
+	Data    string   `json:"data"`
+}
+
+// Update is an incoming update from the telegram bot API
+type Update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message"`
+	CallbackQuery *CallbackQuery `json:"callback_query"`
+}
+
+type inlineKeyboardButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+type inlineKeyboardMarkup struct {
+	InlineKeyboard [][]inlineKeyboardButton `json:"inline_keyboard"`
+}
+
+type apiResponse struct {
+	Ok          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+}
+
+type botAPI struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newBotAPI(url, token string, timeout time.Duration) *botAPI {
+	if url == "" {
+		url = defaultAPIURL
+	}
+	return &botAPI{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// call executes a method of the bot API, the result is decoded into result if it's not nil
+func (bot *botAPI) call(method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", bot.url, bot.token, method)
+	resp, err := bot.client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	r := new(apiResponse)
+	if err = json.NewDecoder(resp.Body).Decode(r); err != nil {
+		return err
+	}
+
+	if !r.Ok {
+		return fmt.Errorf("telegram %s: %d %s", method, r.ErrorCode, r.Description)
+	}
+
+	if result != nil {
+		return json.Unmarshal(r.Result, result)
+	}
+
+	return nil
+}
+
+func (bot *botAPI) getUpdates(offset int64, timeout time.Duration) ([]Update, error) {
+	updates := make([]Update, 0)
+	err := bot.call("getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message", "callback_query"},
+	}, &updates)
+	return updates, err
+}
+
+func (bot *botAPI) setWebhook(url, secretToken string) error {
+	params := map[string]interface{}{
+		"url":             url,
+		"allowed_updates": []string{"message", "callback_query"},
+	}
+	if secretToken != "" {
+		params["secret_token"] = secretToken
+	}
+	return bot.call("setWebhook", params, nil)
+}
+
+func (bot *botAPI) deleteWebhook() error {
+	return bot.call("deleteWebhook", map[string]interface{}{}, nil)
+}
+
+func (bot *botAPI) sendMessage(chatID int64, text string, markup *inlineKeyboardMarkup) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	if markup != nil {
+		params["reply_markup"] = markup
+	}
+	return bot.call("sendMessage", params, nil)
+}
+
+func (bot *botAPI) sendPhoto(chatID int64, photo, caption string, markup *inlineKeyboardMarkup) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+		"photo":   photo,
+	}
+	if caption != "" {
+		params["caption"] = caption
+	}
+	if markup != nil {
+		params["reply_markup"] = markup
+	}
+	return bot.call("sendPhoto", params, nil)
+}
+
+func (bot *botAPI) sendChatAction(chatID int64, action string) error {
+	return bot.call("sendChatAction", map[string]interface{}{
+		"chat_id": chatID,
+		"action":  action,
+	}, nil)
+}
+
+func (bot *botAPI) answerCallbackQuery(id string) error {
+	return bot.call("answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": id,
+	}, nil)
+}
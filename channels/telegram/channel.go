@@ -1,35 +1,86 @@
 package telegram
 
 import (
-	"github.com/minskylab/neocortex"
+	"strconv"
+	"sync"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
 )
 
-type Channel struct {}
+type Channel struct {
+	bot                  *botAPI
+	options              ChannelOptions
+	messageIn            neo.MiddleHandler
+	newContext           neo.ContextFabric
+	mu                   sync.Mutex
+	contexts             map[int64]*neo.Context
+	creating             map[int64]chan struct{}
+	queues               map[int64]*chatQueue
+	newContextCallbacks  []*func(c *neo.Context)
+	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
+}
+
+type ChannelOptions struct {
+	// Token of the bot, given by @BotFather
+	Token string
+	// APIURL by default is https://api.telegram.org, it can be changed to use a local bot API server
+	APIURL string
+	// WebhookURL is the public url of the webhook, if it's empty the channel works with long polling
+	WebhookURL string
+	// ListenAddr is the address of the webhook server, by default :8443
+	ListenAddr string
+	// SecretToken is sent by telegram in every webhook request (optional)
+	SecretToken string
+	// PollTimeout is the timeout of the long polling, by default 30 seconds
+	PollTimeout time.Duration
+}
+
+func (tg *Channel) RegisterMessageEndpoint(handler neo.MiddleHandler) error {
+	tg.messageIn = handler
+	return nil
+}
 
-func (c *Channel) RegisterMessageEndpoint(handler neocortex.MiddleHandler) error {
-	panic("implement me")
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (tg *Channel) SetLogger(logger neo.Logger) {
+	tg.logger = logger
 }
 
-func (c *Channel) ToHear() error {
-	panic("implement me")
+func (tg *Channel) ToHear() error {
+	if tg.options.WebhookURL != "" {
+		return tg.listenWebhook()
+	}
+	return tg.poll()
 }
 
-func (c *Channel) GetContextFabric() neocortex.ContextFabric {
-	panic("implement me")
+func (tg *Channel) GetContextFabric() neo.ContextFabric {
+	return tg.newContext
 }
 
-func (c *Channel) SetContextFabric(fabric neocortex.ContextFabric) {
-	panic("implement me")
+func (tg *Channel) SetContextFabric(fabric neo.ContextFabric) {
+	tg.newContext = fabric
 }
 
-func (c *Channel) OnNewContextCreated(callback func(c *neocortex.Context)) {
-	panic("implement me")
+func (tg *Channel) OnNewContextCreated(callback func(c *neo.Context)) {
+	if tg.newContextCallbacks == nil {
+		tg.newContextCallbacks = []*func(c *neo.Context){}
+	}
+	tg.newContextCallbacks = append(tg.newContextCallbacks, &callback)
 }
 
-func (c *Channel) OnContextIsDone(callback func(c *neocortex.Context)) {
-	panic("implement me")
+func (tg *Channel) OnContextIsDone(callback func(c *neo.Context)) {
+	if tg.doneContextCallbacks == nil {
+		tg.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	tg.doneContextCallbacks = append(tg.doneContextCallbacks, &callback)
 }
 
-func (c *Channel) CallContextDone(c *neocortex.Context) {
-	panic("implement me")
+func (tg *Channel) CallContextDone(c *neo.Context) {
+	id, err := strconv.ParseInt(c.Person.ID, 10, 64)
+	if err == nil {
+		tg.mu.Lock()
+		delete(tg.contexts, id)
+		tg.mu.Unlock()
+	}
 }
@@ -0,0 +1,380 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+const testToken = "123:test"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// fakeTelegram is a local stand-in of api.telegram.org
+type fakeTelegram struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	pending  []Update
+	sent     map[int64][]string
+	markups  map[int64][]inlineKeyboardMarkup
+	answered []string
+	webhook  string
+	calls    map[string]int
+}
+
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	fake := &fakeTelegram{
+		sent:    map[int64][]string{},
+		markups: map[int64][]inlineKeyboardMarkup{},
+		calls:   map[string]int{},
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (fake *fakeTelegram) push(updates ...Update) {
+	fake.mu.Lock()
+	fake.pending = append(fake.pending, updates...)
+	fake.mu.Unlock()
+}
+
+func (fake *fakeTelegram) reply(w http.ResponseWriter, result interface{}) {
+	data, _ := json.Marshal(result)
+	_ = json.NewEncoder(w).Encode(apiResponse{Ok: true, Result: data})
+}
+
+func (fake *fakeTelegram) handle(w http.ResponseWriter, r *http.Request) {
+	prefix := "/bot" + testToken + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		_ = json.NewEncoder(w).Encode(apiResponse{Ok: false, ErrorCode: 401, Description: "Unauthorized"})
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, prefix)
+
+	params := map[string]json.RawMessage{}
+	_ = json.NewDecoder(r.Body).Decode(&params)
+
+	fake.mu.Lock()
+	fake.calls[method]++
+	fake.mu.Unlock()
+
+	switch method {
+	case "getUpdates":
+		var offset int64
+		_ = json.Unmarshal(params["offset"], &offset)
+
+		deadline := time.Now().Add(100 * time.Millisecond)
+		for {
+			fake.mu.Lock()
+			updates := make([]Update, 0)
+			for _, u := range fake.pending {
+				if u.UpdateID >= offset {
+					updates = append(updates, u)
+				}
+			}
+			fake.mu.Unlock()
+
+			if len(updates) > 0 || time.Now().After(deadline) {
+				fake.reply(w, updates)
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	case "sendMessage":
+		var chatID int64
+		var text string
+		_ = json.Unmarshal(params["chat_id"], &chatID)
+		_ = json.Unmarshal(params["text"], &text)
+
+		fake.mu.Lock()
+		fake.sent[chatID] = append(fake.sent[chatID], text)
+		if raw, ok := params["reply_markup"]; ok {
+			markup := inlineKeyboardMarkup{}
+			_ = json.Unmarshal(raw, &markup)
+			fake.markups[chatID] = append(fake.markups[chatID], markup)
+		}
+		fake.mu.Unlock()
+		fake.reply(w, map[string]interface{}{"message_id": 1})
+	case "answerCallbackQuery":
+		var id string
+		_ = json.Unmarshal(params["callback_query_id"], &id)
+
+		fake.mu.Lock()
+		fake.answered = append(fake.answered, id)
+		fake.mu.Unlock()
+		fake.reply(w, true)
+	case "setWebhook":
+		var url string
+		_ = json.Unmarshal(params["url"], &url)
+
+		fake.mu.Lock()
+		fake.webhook = url
+		fake.mu.Unlock()
+		fake.reply(w, true)
+	default:
+		fake.reply(w, true)
+	}
+}
+
+func (fake *fakeTelegram) messages(chatID int64) []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]string{}, fake.sent[chatID]...)
+}
+
+func (fake *fakeTelegram) waitMessages(t *testing.T, chatID int64, total int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if messages := fake.messages(chatID); len(messages) >= total {
+			return messages
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("chat %d: expected %d messages, got %q", chatID, total, fake.messages(chatID))
+	return nil
+}
+
+// echo answers the text of the input after a random delay, the messages resolved concurrently
+// would be answered out of order
+type echo struct{}
+
+func (e *echo) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return &neo.Context{Context: c, SessionID: "session-" + info.ID, Person: info, Variables: map[string]interface{}{}}
+}
+
+func (e *echo) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+	return &neo.Output{Responses: []neo.Response{{Type: neo.Text, Value: "echo: " + in.Data.Value}}}, nil
+}
+
+func (e *echo) OnContextIsDone(callback func(c *neo.Context)) {}
+
+func newTestChannel(t *testing.T, fake *fakeTelegram, options ChannelOptions) *Channel {
+	t.Helper()
+
+	options.Token = testToken
+	options.APIURL = fake.URL
+	options.PollTimeout = time.Second
+	tg, err := NewChannel(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := neo.New(&echo{}, []neo.CommunicationChannel{tg}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.ResolveAny(tg, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		if in.Data.Value == "menu" {
+			out.AddOptionsResponse("pick one", "", neo.Option{Text: "Yes", Action: "yes", IsPostBack: true}, neo.Option{Text: "Docs", Action: "https://example.com"})
+		}
+		return response(c, out)
+	})
+	return tg
+}
+
+func textUpdate(id, chatID int64, text string) Update {
+	return Update{
+		UpdateID: id,
+		Message: &Message{
+			MessageID: id,
+			From:      &User{ID: chatID, FirstName: "Ada", LanguageCode: "en"},
+			Chat:      Chat{ID: chatID, Type: "private", FirstName: "Ada"},
+			Text:      text,
+		},
+	}
+}
+
+func TestLongPollingKeepsTheOrderOfEachChat(t *testing.T) {
+	fake := newFakeTelegram(t)
+	newTestChannel(t, fake, ChannelOptions{})
+
+	const chats = 3
+	const messages = 20
+
+	id := int64(1)
+	for m := 0; m < messages; m++ {
+		for chat := int64(1); chat <= chats; chat++ {
+			fake.push(textUpdate(id, chat, fmt.Sprintf("%d", m)))
+			id++
+		}
+	}
+
+	for chat := int64(1); chat <= chats; chat++ {
+		got := fake.waitMessages(t, chat, messages)
+		for m := 0; m < messages; m++ {
+			if want := fmt.Sprintf("echo: %d", m); got[m] != want {
+				t.Fatalf("chat %d: message %d is %q, expected %q (%q)", chat, m, got[m], want, got)
+			}
+		}
+	}
+
+	// every update is received once, the offset confirms the previous updates
+	time.Sleep(150 * time.Millisecond)
+	for chat := int64(1); chat <= chats; chat++ {
+		if got := fake.messages(chat); len(got) != messages {
+			t.Fatalf("chat %d: expected %d messages, got %d", chat, messages, len(got))
+		}
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.calls["deleteWebhook"] != 1 {
+		t.Errorf("the webhook must be removed before the long polling, deleteWebhook was called %d times", fake.calls["deleteWebhook"])
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	fake := newFakeTelegram(t)
+	tg := newTestChannel(t, fake, ChannelOptions{
+		WebhookURL:  "https://bot.example.com/telegram",
+		ListenAddr:  "127.0.0.1:0",
+		SecretToken: "s3cret",
+	})
+
+	post := func(secret string, body interface{}) int {
+		data, _ := json.Marshal(body)
+		r := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(string(data)))
+		if secret != "" {
+			r.Header.Set("X-Telegram-Bot-Api-Secret-Token", secret)
+		}
+		w := httptest.NewRecorder()
+		tg.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := post("", textUpdate(1, 7, "hello")); code != http.StatusUnauthorized {
+		t.Errorf("without the secret token expected 401, got %d", code)
+	}
+	if code := post("wrong", textUpdate(1, 7, "hello")); code != http.StatusUnauthorized {
+		t.Errorf("with a wrong secret token expected 401, got %d", code)
+	}
+
+	w := httptest.NewRecorder()
+	tg.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/telegram", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET expected 405, got %d", w.Code)
+	}
+
+	for i := int64(1); i <= 10; i++ {
+		if code := post("s3cret", textUpdate(i, 7, fmt.Sprintf("%d", i))); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+
+	got := fake.waitMessages(t, 7, 10)
+	for i := 1; i <= 10; i++ {
+		if want := fmt.Sprintf("echo: %d", i); got[i-1] != want {
+			t.Fatalf("message %d is %q, expected %q", i, got[i-1], want)
+		}
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.webhook != "https://bot.example.com/telegram" {
+		t.Errorf("the webhook must be registered, got %q", fake.webhook)
+	}
+}
+
+func TestOptionsAndCallbackQueries(t *testing.T) {
+	fake := newFakeTelegram(t)
+	newTestChannel(t, fake, ChannelOptions{})
+
+	fake.push(textUpdate(1, 9, "menu"))
+	fake.waitMessages(t, 9, 2)
+
+	fake.mu.Lock()
+	markups := fake.markups[9]
+	fake.mu.Unlock()
+	if len(markups) != 1 || len(markups[0].InlineKeyboard) != 2 ||
+		markups[0].InlineKeyboard[0][0].CallbackData != "yes" || markups[0].InlineKeyboard[1][0].URL != "https://example.com" {
+		t.Fatalf("the options must be sent as an inline keyboard, got %+v", markups)
+	}
+
+	fake.push(Update{
+		UpdateID: 2,
+		CallbackQuery: &CallbackQuery{
+			ID:      "query-1",
+			From:    User{ID: 9, FirstName: "Ada"},
+			Message: &Message{MessageID: 1, Chat: Chat{ID: 9, Type: "private"}},
+			Data:    "yes",
+		},
+	})
+	got := fake.waitMessages(t, 9, 3)
+	if got[2] != "echo: yes" {
+		t.Errorf("the data of the callback query must be resolved as a text, got %q", got[2])
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.answered) != 1 || fake.answered[0] != "query-1" {
+		t.Errorf("the callback query must be answered, got %q", fake.answered)
+	}
+}
+
+func TestASlowContextDoesNotBlockTheOtherChats(t *testing.T) {
+	tg, err := NewChannel(ChannelOptions{Token: testToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	created := map[string]int{}
+	tg.SetContextFabric(func(ctx context.Context, info neo.PersonInfo) *neo.Context {
+		mu.Lock()
+		created[info.ID]++
+		mu.Unlock()
+		if info.ID == "1" {
+			<-release
+		}
+		return &neo.Context{Context: &ctx, SessionID: "session-" + info.ID, Person: info}
+	})
+
+	slow := make(chan *neo.Context, 2)
+	for i := 0; i < 2; i++ {
+		go func() { slow <- tg.getContext(Chat{ID: 1}, nil) }()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tg.getContext(Chat{ID: 2}, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the context of a chat must not wait for the context of another chat")
+	}
+
+	close(release)
+	first, second := <-slow, <-slow
+	if first != second {
+		t.Error("the updates of a chat must share its context")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if created["1"] != 1 {
+		t.Errorf("the context of a chat must be created once, got %d", created["1"])
+	}
+}
@@ -0,0 +1,89 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// poll receives the updates with long polling, it never returns unless the webhook can't be removed
+func (tg *Channel) poll() error {
+	// telegram doesn't allow getUpdates while a webhook is active
+	if err := tg.bot.deleteWebhook(); err != nil {
+		return err
+	}
+
+	tg.logger.Log(neo.Info, "telegram channel listening with long polling", nil)
+
+	offset := int64(0)
+	for {
+		updates, err := tg.bot.getUpdates(offset, tg.options.PollTimeout)
+		if err != nil {
+			tg.logger.Log(neo.Error, "error getting the updates", neo.Fields{neo.ErrorField: err})
+			time.Sleep(3 * time.Second)
+			continue
+		}
+
+		for _, update := range updates {
+			if update.UpdateID >= offset {
+				offset = update.UpdateID + 1
+			}
+			tg.dispatch(update)
+		}
+	}
+}
+
+func (tg *Channel) listenWebhook() error {
+	u, err := url.Parse(tg.options.WebhookURL)
+	if err != nil {
+		return err
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	if err = tg.bot.setWebhook(tg.options.WebhookURL, tg.options.SecretToken); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, tg.ServeHTTP)
+
+	tg.logger.Log(neo.Info, "telegram channel listening", neo.Fields{"addr": tg.options.ListenAddr, "path": path})
+	return http.ListenAndServe(tg.options.ListenAddr, mux)
+}
+
+// ServeHTTP handles the webhook requests of telegram, it can be mounted into your own server
+func (tg *Channel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if tg.options.SecretToken != "" && !validSecret(r.Header.Get("X-Telegram-Bot-Api-Secret-Token"), tg.options.SecretToken) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	update := Update{}
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// telegram waits the response of the webhook, the update is resolved in background
+	tg.dispatch(update)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// validSecret compares the secret token of the webhook in constant time
+func validSecret(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
@@ -0,0 +1,12 @@
+package telegram
+
+import neo "github.com/minskylab/neocortex"
+
+func (tg *Channel) NewInputText(text string, i []neo.Intent, e []neo.Entity) *neo.Input {
+	t := neo.InputData{
+		Type:  neo.InputText,
+		Value: text,
+		Data:  []byte(text),
+	}
+	return tg.NewInput(t, i, e)
+}
@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel, error) {
+	if options.Token == "" {
+		return nil, errors.New("telegram bot token not found")
+	}
+
+	if options.PollTimeout <= 0 {
+		options.PollTimeout = 30 * time.Second
+	}
+
+	if options.ListenAddr == "" {
+		options.ListenAddr = ":8443"
+	}
+
+	tg := &Channel{
+		// the http timeout must be greater than the long polling timeout
+		bot:      newBotAPI(options.APIURL, options.Token, options.PollTimeout+10*time.Second),
+		options:  options,
+		contexts: map[int64]*neo.Context{},
+		creating: map[int64]chan struct{}{},
+		queues:   map[int64]*chatQueue{},
+		logger:   neo.DefaultLogger(),
+	}
+
+	if len(fabric) > 0 {
+		f := fabric[0]
+		tg.newContext = f
+	}
+
+	return tg, nil
+}
+
+// getContext returns the context of the chat, if it not exists a new one is created. The context is
+// created out of the lock of the channel (it calls the cognitive service), the other updates of the
+// same chat wait for it meanwhile
+func (tg *Channel) getContext(chat Chat, user *User) *neo.Context {
+	for {
+		tg.mu.Lock()
+		if c, contextExist := tg.contexts[chat.ID]; contextExist {
+			tg.mu.Unlock()
+			return c
+		}
+
+		if wait, creating := tg.creating[chat.ID]; creating {
+			tg.mu.Unlock()
+			<-wait
+			continue
+		}
+
+		created := make(chan struct{})
+		tg.creating[chat.ID] = created
+		tg.mu.Unlock()
+
+		c := tg.createContext(chat, user)
+
+		tg.mu.Lock()
+		tg.contexts[chat.ID] = c
+		delete(tg.creating, chat.ID)
+		tg.mu.Unlock()
+		close(created)
+
+		return c
+	}
+}
+
+func (tg *Channel) createContext(chat Chat, user *User) *neo.Context {
+	info := neo.PersonInfo{
+		ID:   strconv.FormatInt(chat.ID, 10),
+		Name: strings.TrimSpace(chat.FirstName + " " + chat.LastName),
+	}
+
+	if user != nil {
+		info.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		info.Locale = user.LanguageCode
+	}
+
+	if info.Name == "" {
+		info.Name = chat.Title
+	}
+
+	c := tg.newContext(context.Background(), info)
+
+	for _, call := range tg.newContextCallbacks {
+		(*call)(c)
+	}
+	return c
+}
+
+func (tg *Channel) handleUpdate(update Update) {
+	var chat Chat
+	var user *User
+	text := ""
+
+	switch {
+	case update.Message != nil:
+		if update.Message.Text == "" {
+			// This is because telegram channel only support text messages by now
+			return
+		}
+		chat = update.Message.Chat
+		user = update.Message.From
+		text = update.Message.Text
+	case update.CallbackQuery != nil:
+		if err := tg.bot.answerCallbackQuery(update.CallbackQuery.ID); err != nil {
+			tg.logger.Log(neo.Warn, "error answering the callback query", neo.Fields{neo.ErrorField: err})
+		}
+		if update.CallbackQuery.Message == nil {
+			return
+		}
+		chat = update.CallbackQuery.Message.Chat
+		user = &update.CallbackQuery.From
+		text = update.CallbackQuery.Data
+	default:
+		return
+	}
+
+	c := tg.getContext(chat, user)
+
+	in := tg.NewInputText(text, nil, nil)
+	err := tg.messageIn(c, in, func(c *neo.Context, out *neo.Output) error {
+		tg.mu.Lock()
+		tg.contexts[chat.ID] = c
+		tg.mu.Unlock()
+
+		return decodeOutput(chat.ID, tg.bot, out)
+	})
+	if err != nil {
+		tg.logger.Log(neo.Error, "error resolving the message", neo.Fields{
+			neo.SessionIDField: c.SessionID,
+			neo.PersonIDField:  c.Person.ID,
+			neo.ErrorField:     err,
+		})
+	}
+}
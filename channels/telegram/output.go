@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/minskylab/neocortex"
+)
+
+func decodeOutput(chatID int64, bot *botAPI, out *neocortex.Output) error {
+	for _, r := range out.Responses {
+		if r.IsTyping {
+			if err := bot.sendChatAction(chatID, "typing"); err != nil {
+				return err
+			}
+		}
+
+		switch r.Type {
+		case neocortex.Text:
+			if err := sendTextResponse(chatID, bot, fmt.Sprintf("%v", r.Value)); err != nil {
+				return err
+			}
+		case neocortex.Options:
+			options, isOne := r.Value.(neocortex.OptionsResponse)
+			optionsArray, isArray := r.Value.([]neocortex.OptionsResponse)
+
+			if isOne {
+				optionsArray = []neocortex.OptionsResponse{options}
+			} else if !isArray {
+				return errors.New("invalid value, it cannot be parsed as OptionResponse struct")
+			}
+
+			for _, options := range optionsArray {
+				if err := sendOptionsResponse(chatID, bot, options); err != nil {
+					return err
+				}
+			}
+		case neocortex.Pause:
+			if err := sendPauseResponse(chatID, bot, r.Value); err != nil {
+				return err
+			}
+		case neocortex.Image:
+			url, ok := r.Value.(string)
+			if !ok {
+				return errors.New("invalid value, it must be a string")
+			}
+			if err := sendImageResponse(chatID, bot, url); err != nil {
+				return err
+			}
+		case neocortex.Suggestion:
+			// * Unsupported by telegram
+		case neocortex.Unknown:
+			// * Unsupported by telegram
+		default:
+			// by default neocortex sends a raw stringify of the value
+			if err := bot.sendMessage(chatID, fmt.Sprintf("%v", r.Value), nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
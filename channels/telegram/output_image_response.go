@@ -0,0 +1,5 @@
+package telegram
+
+func sendImageResponse(chatID int64, bot *botAPI, url string) error {
+	return bot.sendPhoto(chatID, url, "", nil)
+}
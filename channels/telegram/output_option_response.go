@@ -0,0 +1,37 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/minskylab/neocortex"
+)
+
+// sendOptionsResponse renders the options as an inline keyboard, one button per row
+func sendOptionsResponse(chatID int64, bot *botAPI, options neocortex.OptionsResponse) error {
+	markup := &inlineKeyboardMarkup{InlineKeyboard: [][]inlineKeyboardButton{}}
+	for _, o := range options.Options {
+		button := inlineKeyboardButton{Text: o.Text}
+		if o.IsPostBack {
+			button.CallbackData = o.Action
+		} else {
+			button.URL = o.Action
+		}
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []inlineKeyboardButton{button})
+	}
+
+	text := strings.TrimSpace(options.Title + "\n" + options.Description)
+	if options.ItemURL != "" {
+		text = strings.TrimSpace(text + "\n" + options.ItemURL)
+	}
+
+	if options.Image != "" {
+		return bot.sendPhoto(chatID, options.Image, text, markup)
+	}
+
+	if text == "" {
+		// telegram doesn't allow empty messages
+		text = "..."
+	}
+
+	return bot.sendMessage(chatID, text, markup)
+}
@@ -0,0 +1,37 @@
+package telegram
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// sendPauseResponse shows the typing action meanwhile the pause is executed
+func sendPauseResponse(chatID int64, bot *botAPI, pause interface{}) error {
+	var delay time.Duration
+	switch p := pause.(type) {
+	case int: // in milliseconds
+		delay = time.Duration(p) * time.Millisecond
+	case int64: // in milliseconds
+		delay = time.Duration(p) * time.Millisecond
+	case float64: // in milliseconds
+		delay = time.Duration(p) * time.Millisecond
+	case time.Duration:
+		delay = p
+	case string: // in milliseconds
+		ms, err := strconv.Atoi(p)
+		if err != nil {
+			return err
+		}
+		delay = time.Duration(ms) * time.Millisecond
+	default:
+		return errors.New("invalid value, it cannot be parsed as duration or same")
+	}
+
+	if err := bot.sendChatAction(chatID, "typing"); err != nil {
+		return err
+	}
+
+	time.Sleep(delay)
+	return nil
+}
@@ -0,0 +1,5 @@
+package telegram
+
+func sendTextResponse(chatID int64, bot *botAPI, text string) error {
+	return bot.sendMessage(chatID, text, nil)
+}
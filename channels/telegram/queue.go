@@ -0,0 +1,53 @@
+package telegram
+
+// chatQueue keeps the pending updates of a chat, they are resolved one at time and in order of
+// arrival (the engine doesn't guarantee the order of concurrent messages of the same context)
+type chatQueue struct {
+	updates []Update
+}
+
+// chatID returns the chat of the update, the updates without chat are queued under the chat 0
+func (update Update) chatID() int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
+
+// dispatch queues the update into its chat, a worker per chat with pending updates resolves them
+func (tg *Channel) dispatch(update Update) {
+	id := update.chatID()
+
+	tg.mu.Lock()
+	q, working := tg.queues[id]
+	if !working {
+		q = &chatQueue{}
+		tg.queues[id] = q
+	}
+	q.updates = append(q.updates, update)
+	tg.mu.Unlock()
+
+	if !working {
+		go tg.work(id, q)
+	}
+}
+
+// work resolves the updates of the chat until its queue is empty
+func (tg *Channel) work(id int64, q *chatQueue) {
+	for {
+		tg.mu.Lock()
+		if len(q.updates) == 0 {
+			delete(tg.queues, id)
+			tg.mu.Unlock()
+			return
+		}
+		update := q.updates[0]
+		q.updates = q.updates[1:]
+		tg.mu.Unlock()
+
+		tg.handleUpdate(update)
+	}
+}
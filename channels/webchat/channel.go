@@ -0,0 +1,88 @@
+package webchat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	neo "github.com/minskylab/neocortex"
+)
+
+type Channel struct {
+	options              ChannelOptions
+	upgrader             websocket.Upgrader
+	messageIn            neo.MiddleHandler
+	newContext           neo.ContextFabric
+	mu                   sync.Mutex
+	sessions             map[string]*session
+	newContextCallbacks  []*func(c *neo.Context)
+	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
+}
+
+type ChannelOptions struct {
+	// Addr of the http server, by default :8081
+	Addr string
+	// Path of the websocket endpoint, by default /webchat
+	Path string
+	// AllowedOrigins of the browsers, use "*" to allow any origin. By default only the same origin is allowed
+	AllowedOrigins []string
+	// MaxPending is the max of envelopes kept for a disconnected session, by default 50
+	MaxPending int
+	// WriteTimeout is the max time to write an envelope into the socket, by default 10 seconds.
+	// The slow browsers are disconnected and its envelopes are kept as pending
+	WriteTimeout time.Duration
+}
+
+func (wc *Channel) RegisterMessageEndpoint(handler neo.MiddleHandler) error {
+	wc.messageIn = handler
+	return nil
+}
+
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (wc *Channel) SetLogger(logger neo.Logger) {
+	wc.logger = logger
+}
+
+func (wc *Channel) ToHear() error {
+	return wc.listen()
+}
+
+func (wc *Channel) GetContextFabric() neo.ContextFabric {
+	return wc.newContext
+}
+
+func (wc *Channel) SetContextFabric(fabric neo.ContextFabric) {
+	wc.newContext = fabric
+}
+
+func (wc *Channel) OnNewContextCreated(callback func(c *neo.Context)) {
+	if wc.newContextCallbacks == nil {
+		wc.newContextCallbacks = []*func(c *neo.Context){}
+	}
+	wc.newContextCallbacks = append(wc.newContextCallbacks, &callback)
+}
+
+func (wc *Channel) OnContextIsDone(callback func(c *neo.Context)) {
+	if wc.doneContextCallbacks == nil {
+		wc.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	wc.doneContextCallbacks = append(wc.doneContextCallbacks, &callback)
+}
+
+func (wc *Channel) CallContextDone(c *neo.Context) {
+	wc.mu.Lock()
+	var done *session
+	for id, s := range wc.sessions {
+		if s.context() == c {
+			done = s
+			delete(wc.sessions, id)
+			break
+		}
+	}
+	wc.mu.Unlock()
+
+	if done != nil {
+		done.send(Envelope{Type: End, SessionID: done.id})
+	}
+}
@@ -0,0 +1,192 @@
+package webchat
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// echo answers the text of the input
+type echo struct{}
+
+func (e *echo) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return &neo.Context{Context: c, SessionID: "session-" + info.ID, Person: info, Variables: map[string]interface{}{}}
+}
+
+func (e *echo) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	return &neo.Output{Responses: []neo.Response{{Type: neo.Text, Value: "echo: " + in.Data.Value}}}, nil
+}
+
+func (e *echo) OnContextIsDone(callback func(c *neo.Context)) {}
+
+func newTestServer(t *testing.T, options ChannelOptions) (*Channel, *httptest.Server) {
+	t.Helper()
+
+	wc, err := NewChannel(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := neo.New(&echo{}, []neo.CommunicationChannel{wc}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.ResolveAny(wc, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+
+	server := httptest.NewServer(wc)
+	t.Cleanup(server.Close)
+	return wc, server
+}
+
+func dial(t *testing.T, server *httptest.Server, header http.Header) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, env Envelope) {
+	t.Helper()
+	if err := conn.WriteJSON(env); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, conn *websocket.Conn) Envelope {
+	t.Helper()
+
+	env := Envelope{}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&env); err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func hello(t *testing.T, conn *websocket.Conn, sessionID, token string) Envelope {
+	t.Helper()
+
+	send(t, conn, Envelope{Type: Hello, SessionID: sessionID, ResumeToken: token, Person: &neo.PersonInfo{ID: "42", Name: "Ann"}})
+	welcome := receive(t, conn)
+	if welcome.Type != Welcome || welcome.SessionID == "" {
+		t.Fatalf("expected a welcome with the session, got %+v", welcome)
+	}
+	return welcome
+}
+
+func TestHelloAndMessage(t *testing.T) {
+	_, server := newTestServer(t, ChannelOptions{})
+	conn := dial(t, server, nil)
+
+	welcome := hello(t, conn, "", "")
+	if welcome.Person == nil || welcome.Person.Name != "Ann" {
+		t.Errorf("the welcome must carry the person, got %+v", welcome.Person)
+	}
+	if len(welcome.ResumeToken) < 43 {
+		t.Errorf("the resume token must have 32 random bytes, got %q", welcome.ResumeToken)
+	}
+
+	send(t, conn, Envelope{Type: Message, Text: "hi"})
+	env := receive(t, conn)
+	if env.Type != ResponseEnvelope || env.Response == nil || env.Response.Value != "echo: hi" {
+		t.Fatalf("expected the echo of the message, got %+v", env)
+	}
+
+	send(t, conn, Envelope{Type: "shout"})
+	if env = receive(t, conn); env.Type != ErrorEnvelope {
+		t.Errorf("an unknown envelope must be answered with an error, got %+v", env)
+	}
+}
+
+func TestResumeWithTheToken(t *testing.T) {
+	wc, server := newTestServer(t, ChannelOptions{})
+
+	first := dial(t, server, nil)
+	welcome := hello(t, first, "", "")
+	_ = first.Close()
+
+	// the envelopes sent while the browser is disconnected are kept as pending
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		wc.mu.Lock()
+		s := wc.sessions[welcome.SessionID]
+		wc.mu.Unlock()
+
+		s.mu.Lock()
+		detached := s.conn == nil
+		s.mu.Unlock()
+		if detached {
+			s.send(Envelope{Type: ResponseEnvelope, SessionID: s.id, Response: &neo.Response{Type: neo.Text, Value: "while you were away"}})
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the session wasn't detached")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	second := dial(t, server, nil)
+	resumed := hello(t, second, welcome.SessionID, welcome.ResumeToken)
+	if resumed.SessionID != welcome.SessionID || resumed.ResumeToken != welcome.ResumeToken {
+		t.Fatalf("the session must be resumed, got %+v", resumed)
+	}
+
+	env := receive(t, second)
+	if env.Response == nil || env.Response.Value != "while you were away" {
+		t.Fatalf("the pending envelopes must be delivered on resume, got %+v", env)
+	}
+}
+
+func TestResumeWithoutTheToken(t *testing.T) {
+	_, server := newTestServer(t, ChannelOptions{})
+
+	owner := dial(t, server, nil)
+	welcome := hello(t, owner, "", "")
+
+	for _, token := range []string{"", "guessed", welcome.ResumeToken[1:]} {
+		intruder := dial(t, server, nil)
+		got := hello(t, intruder, welcome.SessionID, token)
+		if got.SessionID == welcome.SessionID || got.ResumeToken == welcome.ResumeToken {
+			t.Fatalf("the session can't be resumed with the token %q", token)
+		}
+	}
+
+	// the owner keeps its session
+	send(t, owner, Envelope{Type: Message, Text: "still mine"})
+	if env := receive(t, owner); env.Response == nil || env.Response.Value != "echo: still mine" {
+		t.Fatalf("the owner must keep its session, got %+v", env)
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	_, server := newTestServer(t, ChannelOptions{AllowedOrigins: []string{"https://chat.example.com"}})
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("a foreign origin must be rejected, got %v", err)
+	}
+
+	dial(t, server, http.Header{"Origin": {"https://chat.example.com"}})
+}
@@ -0,0 +1,11 @@
+package webchat
+
+import neo "github.com/minskylab/neocortex"
+
+func (wc *Channel) NewInput(data neo.InputData, i []neo.Intent, e []neo.Entity) *neo.Input {
+	return &neo.Input{
+		Data:     data,
+		Intents:  i,
+		Entities: e,
+	}
+}
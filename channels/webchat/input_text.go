@@ -0,0 +1,12 @@
+package webchat
+
+import neo "github.com/minskylab/neocortex"
+
+func (wc *Channel) NewInputText(text string, i []neo.Intent, e []neo.Entity) *neo.Input {
+	t := neo.InputData{
+		Type:  neo.InputText,
+		Value: text,
+		Data:  []byte(text),
+	}
+	return wc.NewInput(t, i, e)
+}
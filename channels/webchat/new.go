@@ -0,0 +1,68 @@
+package webchat
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+	neo "github.com/minskylab/neocortex"
+)
+
+func NewChannel(options ChannelOptions, fabric ...neo.ContextFabric) (*Channel, error) {
+	if options.Addr == "" {
+		options.Addr = ":8081"
+	}
+
+	if options.Path == "" {
+		options.Path = "/webchat"
+	}
+
+	if options.MaxPending <= 0 {
+		options.MaxPending = 50
+	}
+
+	if options.WriteTimeout <= 0 {
+		options.WriteTimeout = 10 * time.Second
+	}
+
+	wc := &Channel{
+		options:  options,
+		sessions: map[string]*session{},
+		logger:   neo.DefaultLogger(),
+	}
+
+	wc.upgrader = websocket.Upgrader{
+		CheckOrigin: wc.checkOrigin,
+	}
+
+	if len(fabric) > 0 {
+		f := fabric[0]
+		wc.newContext = f
+	}
+
+	return wc, nil
+}
+
+func (wc *Channel) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if len(wc.options.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return u.Host == r.Host
+	}
+
+	for _, allowed := range wc.options.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package webchat
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func sendOutput(s *session, out *neo.Output) error {
+	for _, r := range out.Responses {
+		if r.Type == neo.Pause {
+			delay, err := pauseDuration(r.Value)
+			if err != nil {
+				return err
+			}
+
+			s.send(Envelope{Type: Typing, SessionID: s.id})
+			s.send(Envelope{Type: ResponseEnvelope, SessionID: s.id, Response: &neo.Response{
+				IsTyping: true,
+				Type:     neo.Pause,
+				Value:    delay.Milliseconds(),
+			}})
+
+			time.Sleep(delay)
+			continue
+		}
+
+		if r.IsTyping {
+			s.send(Envelope{Type: Typing, SessionID: s.id})
+		}
+
+		response := r
+		s.send(Envelope{Type: ResponseEnvelope, SessionID: s.id, Response: &response})
+	}
+
+	return nil
+}
+
+func pauseDuration(pause interface{}) (time.Duration, error) {
+	switch p := pause.(type) {
+	case int: // in milliseconds
+		return time.Duration(p) * time.Millisecond, nil
+	case int64: // in milliseconds
+		return time.Duration(p) * time.Millisecond, nil
+	case float64: // in milliseconds
+		return time.Duration(p) * time.Millisecond, nil
+	case time.Duration:
+		return p, nil
+	case string: // in milliseconds
+		ms, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	return 0, errors.New("invalid value, it cannot be parsed as duration or same")
+}
@@ -0,0 +1,61 @@
+// Package webchat implements a neocortex channel over WebSocket, ready to embed a chat in any website.
+//
+// Every frame of the socket is a JSON Envelope. The conversation starts with a "hello" envelope sent
+// by the browser with the PersonInfo of the user. The welcome carries the session ID and a secret resume
+// token, the browser sends both into a later hello to resume that session (the pending messages are
+// delivered on reconnect), without a valid token a new session is created:
+//
+//	-> {"type": "hello", "person": {"id": "42", "name": "Ann", "locale": "es", "timezone": "-5"}}
+//	<- {"type": "welcome", "session_id": "bq4f5oc2lfl3kbb0ot6g", "resume_token": "Hq3...", "person": {...}}
+//	-> {"type": "message", "text": "hello bot"}
+//	<- {"type": "typing"}
+//	<- {"type": "response", "response": {"is_typing": true, "type": "text", "value": "hi Ann"}}
+//	-> {"type": "postback", "text": "BUY_ACTION"}
+//	<- {"type": "end"}
+//
+// The responses carry every neocortex ResponseType ("text", "option", "image", "pause", "suggestion",
+// "unknown") with its original value, the pauses are sent in milliseconds. A "typing" envelope is sent
+// before every response marked with IsTyping and before the pauses. The "end" envelope is sent when the
+// engine closes the session, the next "hello" creates a new one.
+package webchat
+
+import neo "github.com/minskylab/neocortex"
+
+// EnvelopeType identifies the kind of envelope
+type EnvelopeType string
+
+// Hello starts or resumes (with session_id and resume_token) a session, sent by the browser
+const Hello EnvelopeType = "hello"
+
+// Message is a text written by the user, sent by the browser
+const Message EnvelopeType = "message"
+
+// Postback is the action of an option selected by the user, sent by the browser
+const Postback EnvelopeType = "postback"
+
+// Welcome confirms the session, sent by the channel
+const Welcome EnvelopeType = "welcome"
+
+// Typing indicates that the bot is writing, sent by the channel
+const Typing EnvelopeType = "typing"
+
+// ResponseEnvelope carries one response of the bot, sent by the channel
+const ResponseEnvelope EnvelopeType = "response"
+
+// End indicates that the session is closed, sent by the channel
+const End EnvelopeType = "end"
+
+// ErrorEnvelope reports an invalid envelope or a failure of the bot, sent by the channel
+const ErrorEnvelope EnvelopeType = "error"
+
+// Envelope is the JSON frame exchanged between the browser and the channel
+type Envelope struct {
+	Type      EnvelopeType `json:"type"`
+	SessionID string       `json:"session_id,omitempty"`
+	// ResumeToken is given into the welcome, it's required to resume the session
+	ResumeToken string          `json:"resume_token,omitempty"`
+	Person      *neo.PersonInfo `json:"person,omitempty"`
+	Text        string          `json:"text,omitempty"`
+	Response    *neo.Response   `json:"response,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
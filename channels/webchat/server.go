@@ -0,0 +1,173 @@
+package webchat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	neo "github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+func (wc *Channel) listen() error {
+	mux := http.NewServeMux()
+	mux.Handle(wc.options.Path, wc)
+
+	wc.logger.Log(neo.Info, "webchat channel listening", neo.Fields{"addr": wc.options.Addr, "path": wc.options.Path})
+	return http.ListenAndServe(wc.options.Addr, mux)
+}
+
+// ServeHTTP upgrades the request to a websocket, it can be mounted into your own server
+func (wc *Channel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := wc.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		wc.logger.Log(neo.Warn, "error upgrading the connection", neo.Fields{neo.ErrorField: err})
+		return
+	}
+
+	var s *session
+	defer func() {
+		if s != nil {
+			s.detach(conn)
+		}
+		_ = conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		env := Envelope{}
+		if err = json.Unmarshal(data, &env); err != nil {
+			wc.reply(conn, s, Envelope{Type: ErrorEnvelope, Error: "invalid envelope: " + err.Error()})
+			continue
+		}
+
+		switch env.Type {
+		case Hello:
+			if s != nil {
+				s.detach(conn)
+			}
+			s, err = wc.openSession(env.SessionID, env.ResumeToken, env.Person)
+			if err != nil {
+				wc.logger.Log(neo.Error, "error opening the session", neo.Fields{neo.ErrorField: err})
+				wc.reply(conn, nil, Envelope{Type: ErrorEnvelope, Error: "the session can't be opened"})
+				return
+			}
+			if err = wc.welcome(conn, s); err != nil {
+				return
+			}
+			if err = s.attach(conn); err != nil {
+				return
+			}
+		case Message, Postback:
+			if s == nil {
+				wc.reply(conn, s, Envelope{Type: ErrorEnvelope, Error: "the session is not open, send a hello envelope first"})
+				continue
+			}
+
+			if !wc.isOpen(s) {
+				// the engine closed the session, a new one is opened for the same person
+				person := s.context().Person
+				s.detach(conn)
+				s, err = wc.openSession("", "", &person)
+				if err != nil {
+					wc.logger.Log(neo.Error, "error opening the session", neo.Fields{neo.ErrorField: err})
+					wc.reply(conn, nil, Envelope{Type: ErrorEnvelope, Error: "the session can't be opened"})
+					return
+				}
+				if err = wc.welcome(conn, s); err != nil {
+					return
+				}
+				if err = s.attach(conn); err != nil {
+					return
+				}
+			}
+
+			wc.resolve(s, env.Text)
+		default:
+			wc.reply(conn, s, Envelope{Type: ErrorEnvelope, Error: fmt.Sprintf("invalid envelope type '%s'", env.Type)})
+		}
+	}
+}
+
+// reply writes directly into the socket until the session is attached
+func (wc *Channel) reply(conn *websocket.Conn, s *session, env Envelope) {
+	if s != nil {
+		s.send(env)
+		return
+	}
+	_ = writeEnvelope(conn, env, wc.options.WriteTimeout)
+}
+
+// welcome confirms the session to the browser with its resume token
+func (wc *Channel) welcome(conn *websocket.Conn, s *session) error {
+	person := s.context().Person
+	return writeEnvelope(conn, Envelope{Type: Welcome, SessionID: s.id, ResumeToken: s.token, Person: &person}, wc.options.WriteTimeout)
+}
+
+// openSession resumes the session if it exists and the token is its resume token,
+// otherwise a new session is created
+func (wc *Channel) openSession(sessionID, token string, person *neo.PersonInfo) (*session, error) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	if s, exist := wc.sessions[sessionID]; exist && sessionID != "" && s.resumableWith(token) {
+		return s, nil
+	}
+
+	token, err := newResumeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	info := neo.PersonInfo{}
+	if person != nil {
+		info = *person
+	}
+
+	id := xid.New().String()
+	if info.ID == "" {
+		info.ID = id
+	}
+
+	c := wc.newContext(context.Background(), info)
+	for _, call := range wc.newContextCallbacks {
+		(*call)(c)
+	}
+
+	s := &session{
+		id:           id,
+		token:        token,
+		maxPending:   wc.options.MaxPending,
+		writeTimeout: wc.options.WriteTimeout,
+		c:            c,
+	}
+	wc.sessions[id] = s
+
+	return s, nil
+}
+
+func (wc *Channel) isOpen(s *session) bool {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	_, exist := wc.sessions[s.id]
+	return exist
+}
+
+func (wc *Channel) resolve(s *session, text string) {
+	in := wc.NewInputText(text, nil, nil)
+	err := wc.messageIn(s.context(), in, func(c *neo.Context, out *neo.Output) error {
+		s.setContext(c)
+		return sendOutput(s, out)
+	})
+	if err != nil {
+		wc.logger.Log(neo.Error, "error resolving the message", neo.Fields{neo.SessionIDField: s.id, neo.ErrorField: err})
+		s.send(Envelope{Type: ErrorEnvelope, SessionID: s.id, Error: err.Error()})
+	}
+}
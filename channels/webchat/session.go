@@ -0,0 +1,107 @@
+package webchat
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	neo "github.com/minskylab/neocortex"
+)
+
+// session is a browser session, it survives to the reconnections of the socket
+type session struct {
+	id           string
+	token        string
+	maxPending   int
+	writeTimeout time.Duration
+
+	mu      sync.Mutex
+	c       *neo.Context
+	conn    *websocket.Conn
+	pending []Envelope
+}
+
+// newResumeToken returns 32 random bytes encoded as base64, the session ids can be guessed
+// so the token is the secret that allows to resume a session
+func newResumeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// resumableWith compares the token in constant time
+func (s *session) resumableWith(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(s.token), []byte(token)) == 1
+}
+
+// writeEnvelope writes into the socket with a deadline, a slow browser can't block the session
+func writeEnvelope(conn *websocket.Conn, env Envelope, timeout time.Duration) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(env)
+}
+
+func (s *session) context() *neo.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c
+}
+
+func (s *session) setContext(c *neo.Context) {
+	s.mu.Lock()
+	s.c = c
+	s.mu.Unlock()
+}
+
+// attach links the socket to the session and delivers the pending envelopes
+func (s *session) attach(conn *websocket.Conn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil && s.conn != conn {
+		_ = s.conn.Close()
+	}
+	s.conn = conn
+
+	for len(s.pending) > 0 {
+		if err := writeEnvelope(conn, s.pending[0], s.writeTimeout); err != nil {
+			return err
+		}
+		s.pending = s.pending[1:]
+	}
+
+	return nil
+}
+
+func (s *session) detach(conn *websocket.Conn) {
+	s.mu.Lock()
+	if s.conn == conn {
+		s.conn = nil
+	}
+	s.mu.Unlock()
+}
+
+// send writes the envelope into the socket, if the browser is disconnected the envelope is kept
+func (s *session) send(env Envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if err := writeEnvelope(s.conn, env, s.writeTimeout); err == nil {
+			return
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+
+	s.pending = append(s.pending, env)
+	if len(s.pending) > s.maxPending {
+		s.pending = s.pending[len(s.pending)-s.maxPending:]
+	}
+}
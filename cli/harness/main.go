@@ -0,0 +1,63 @@
+// harness runs transcripts against an offline bot (a rules skill or a replayed cassette),
+// every output of the cognitive service is sent as it is
+//
+//	go run ./cli/harness -skill examples/rules/skill.yaml examples/rules/transcripts/*.yaml
+//	go run ./cli/harness -cassette testdata/watson.jsonl transcripts/*.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/memory"
+	"github.com/minskylab/neocortex/cognitive/cassette"
+	"github.com/minskylab/neocortex/cognitive/rules"
+	"github.com/minskylab/neocortex/harness"
+)
+
+func main() {
+	skill := flag.String("skill", "", "skill file of the rules cognitive service")
+	tape := flag.String("cassette", "", "cassette file to replay")
+	verbose := flag.Bool("v", false, "show the logs of the engine")
+	flag.Parse()
+
+	options := []neo.EngineOption{}
+	if !*verbose {
+		options = append(options, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	var cognitive neo.CognitiveService
+	var err error
+	switch {
+	case *skill != "":
+		cognitive, err = rules.NewCognitiveFromFile(*skill)
+	case *tape != "":
+		cognitive, err = cassette.New(nil, cassette.Options{Path: *tape, Mode: cassette.Replay})
+	default:
+		err = fmt.Errorf("one of -skill or -cassette is required")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ch := memory.NewChannel()
+	engine, err := neo.New(cognitive, []neo.CommunicationChannel{ch}, options...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+
+	os.Args = append(os.Args[:1], flag.Args()...)
+	harness.Main(ch)
+}
@@ -7,3 +7,15 @@ type CognitiveService interface {
 	OnContextIsDone(callback func(c *Context))
 	GetProtoResponse(c *Context, in *Input) (*Output, error)
 }
+
+// ContextDoneCaller is implemented by the cognitive services that keep something per context,
+// the engine calls CallContextDone when it closes a context (e.g. expired by the garbage collector)
+type ContextDoneCaller interface {
+	CallContextDone(c *Context)
+}
+
+// StatusReporter is implemented by the cognitive services that report its health (e.g. the state of
+// a circuit breaker), the status is served by the API of the engine as JSON
+type StatusReporter interface {
+	Status() interface{}
+}
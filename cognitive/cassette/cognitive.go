@@ -0,0 +1,355 @@
+// Package cassette records the exchanges of a cognitive service into a jsonl file (the cassette)
+// and replays them offline, e.g. to run the resolvers into the CI without credentials
+package cassette
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+// Mode of the cassette
+type Mode string
+
+// Record calls the service and writes every exchange, the cassette is overwritten
+const Record Mode = "record"
+
+// Replay serves the exchanges of the cassette, the service is never called
+const Replay Mode = "replay"
+
+// Auto replays the recorded exchanges and records the missing ones
+const Auto Mode = "auto"
+
+// ModeEnv is read when the options don't have a mode, by default the mode is replay
+const ModeEnv = "NEOCORTEX_CASSETTE_MODE"
+
+type Options struct {
+	Path string
+	Mode Mode
+	// MatchVariables are the context variables used to match an exchange, by default all of them
+	MatchVariables []string
+	// IgnoreVariables are never used to match (e.g. timestamps)
+	IgnoreVariables []string
+}
+
+type Cognitive struct {
+	service              neo.CognitiveService
+	options              Options
+	mu                   sync.Mutex
+	file                 *os.File
+	exchanges            map[string][]*entry
+	byText               map[string][]*entry
+	served               map[string]map[string]int
+	misses               []*MissError
+	finished             map[*neo.Context]bool
+	doneContextCallbacks []*func(c *neo.Context)
+}
+
+// New loads the cassette, the service can be nil in replay mode
+func New(service neo.CognitiveService, options Options) (*Cognitive, error) {
+	if options.Mode == "" {
+		options.Mode = Mode(os.Getenv(ModeEnv))
+	}
+
+	if options.Mode == "" {
+		options.Mode = Replay
+	}
+
+	if options.Mode != Record && options.Mode != Replay && options.Mode != Auto {
+		return nil, ErrInvalidMode
+	}
+
+	if options.Mode != Replay && service == nil {
+		return nil, ErrNoService
+	}
+
+	cassette := &Cognitive{
+		service:   service,
+		options:   options,
+		exchanges: map[string][]*entry{},
+		byText:    map[string][]*entry{},
+		served:    map[string]map[string]int{},
+		misses:    []*MissError{},
+		finished:  map[*neo.Context]bool{},
+	}
+
+	if options.Mode != Record {
+		if err := cassette.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	switch options.Mode {
+	case Record:
+		cassette.file, err = os.OpenFile(options.Path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	case Auto:
+		cassette.file, err = os.OpenFile(options.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if service != nil {
+		service.OnContextIsDone(func(c *neo.Context) {
+			cassette.mu.Lock()
+			cassette.finished[c] = true
+			cassette.mu.Unlock()
+			cassette.callDone(c)
+		})
+	}
+
+	return cassette, nil
+}
+
+func (cassette *Cognitive) load() error {
+	file, err := os.Open(cassette.options.Path)
+	if os.IsNotExist(err) && cassette.options.Mode == Auto {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		e := new(entry)
+		if err = json.Unmarshal(scanner.Bytes(), e); err != nil {
+			return err
+		}
+		decodeOutput(e.Output)
+		cassette.add(e)
+	}
+
+	return scanner.Err()
+}
+
+func (cassette *Cognitive) add(e *entry) {
+	k := cassette.key(e.Text, e.Variables)
+	cassette.exchanges[k] = append(cassette.exchanges[k], e)
+	cassette.byText[e.Text] = append(cassette.byText[e.Text], e)
+}
+
+// matchVariables filters the variables used to match
+func (cassette *Cognitive) matchVariables(vars map[string]interface{}) map[string]interface{} {
+	filtered := map[string]interface{}{}
+	if len(cassette.options.MatchVariables) > 0 {
+		for _, name := range cassette.options.MatchVariables {
+			if v, exist := vars[name]; exist {
+				filtered[name] = v
+			}
+		}
+	} else {
+		for k, v := range vars {
+			filtered[k] = v
+		}
+	}
+
+	for _, name := range cassette.options.IgnoreVariables {
+		delete(filtered, name)
+	}
+	return filtered
+}
+
+func (cassette *Cognitive) key(text string, vars map[string]interface{}) string {
+	return text + "\x00" + canonical(cassette.matchVariables(vars))
+}
+
+func (cassette *Cognitive) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	if cassette.options.Mode != Replay {
+		return cassette.service.CreateNewContext(c, info)
+	}
+
+	id := xid.New()
+	return &neo.Context{
+		Context:   c,
+		SessionID: id.String(),
+		Person:    info,
+		Variables: map[string]interface{}{},
+	}
+}
+
+func (cassette *Cognitive) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if c == nil {
+		return nil, neo.ErrContextNotExist
+	}
+
+	if c.Variables == nil {
+		c.Variables = map[string]interface{}{}
+	}
+
+	text := normalizeText(in.Data.Value)
+
+	if cassette.options.Mode != Record {
+		if e, found := cassette.lookup(c.SessionID, text, c.Variables); found {
+			return cassette.replay(c, e)
+		}
+
+		if cassette.options.Mode == Replay {
+			return nil, cassette.miss(text, c.Variables)
+		}
+	}
+
+	return cassette.record(c, in, text)
+}
+
+// lookup serves the exchanges with the same key in order, the last one is repeated. The order
+// is counted by session, so the concurrent conversations don't take the exchanges of each other
+func (cassette *Cognitive) lookup(sessionID, text string, vars map[string]interface{}) (*entry, bool) {
+	cassette.mu.Lock()
+	defer cassette.mu.Unlock()
+
+	k := cassette.key(text, vars)
+	entries := cassette.exchanges[k]
+	if len(entries) == 0 {
+		return nil, false
+	}
+
+	served, exist := cassette.served[sessionID]
+	if !exist {
+		served = map[string]int{}
+		cassette.served[sessionID] = served
+	}
+
+	i := served[k]
+	if i >= len(entries) {
+		i = len(entries) - 1
+	}
+	served[k] = i + 1
+
+	return entries[i], true
+}
+
+func (cassette *Cognitive) replay(c *neo.Context, e *entry) (*neo.Output, error) {
+	for k, v := range e.VariablesAfter {
+		c.Variables[k] = v
+	}
+
+	if e.Done {
+		cassette.forget(c)
+		cassette.callDone(c)
+	}
+
+	if e.Error != "" {
+		return nil, decodeError(e.Error)
+	}
+
+	if e.Output == nil {
+		return nil, nil
+	}
+
+	return e.Output.Copy(), nil
+}
+
+func (cassette *Cognitive) miss(text string, vars map[string]interface{}) error {
+	cassette.mu.Lock()
+	defer cassette.mu.Unlock()
+
+	miss := &MissError{
+		Text:       text,
+		Variables:  cassette.matchVariables(vars),
+		Candidates: []map[string]interface{}{},
+	}
+	for _, e := range cassette.byText[text] {
+		miss.Candidates = append(miss.Candidates, cassette.matchVariables(e.Variables))
+	}
+
+	cassette.misses = append(cassette.misses, miss)
+	return miss
+}
+
+func (cassette *Cognitive) record(c *neo.Context, in *neo.Input, text string) (*neo.Output, error) {
+	e := &entry{
+		Text:       text,
+		Variables:  snapshot(c.Variables),
+		Input:      in,
+		RecordedAt: time.Now(),
+	}
+
+	out, err := cassette.service.GetProtoResponse(c, in)
+
+	// the caller can change the output, the cassette keeps its own copy to replay it
+	e.Output = out.Copy()
+	e.VariablesAfter = snapshot(c.Variables)
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	cassette.mu.Lock()
+	e.Done = cassette.finished[c]
+	delete(cassette.finished, c)
+	cassette.add(e)
+	writeErr := json.NewEncoder(cassette.file).Encode(e)
+	cassette.mu.Unlock()
+
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	return out, err
+}
+
+// forget drops the served exchanges of the session of the context
+func (cassette *Cognitive) forget(c *neo.Context) {
+	cassette.mu.Lock()
+	delete(cassette.served, c.SessionID)
+	delete(cassette.finished, c)
+	cassette.mu.Unlock()
+}
+
+// CallContextDone forgets the session of the closed context and passes it to the recorded
+// service if it implements neo.ContextDoneCaller
+func (cassette *Cognitive) CallContextDone(c *neo.Context) {
+	cassette.forget(c)
+	if caller, ok := cassette.service.(neo.ContextDoneCaller); ok {
+		caller.CallContextDone(c)
+	}
+}
+
+func (cassette *Cognitive) callDone(c *neo.Context) {
+	for _, call := range cassette.doneContextCallbacks {
+		(*call)(c)
+	}
+}
+
+func (cassette *Cognitive) OnContextIsDone(callback func(c *neo.Context)) {
+	if cassette.doneContextCallbacks == nil {
+		cassette.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	cassette.doneContextCallbacks = append(cassette.doneContextCallbacks, &callback)
+}
+
+// Misses returns the inputs that were not found into the cassette
+func (cassette *Cognitive) Misses() []*MissError {
+	cassette.mu.Lock()
+	defer cassette.mu.Unlock()
+
+	return append([]*MissError{}, cassette.misses...)
+}
+
+// Close closes the cassette file
+func (cassette *Cognitive) Close() error {
+	if cassette.file == nil {
+		return nil
+	}
+	return cassette.file.Close()
+}
+
+func snapshot(vars map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,220 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// counter answers how many times it was called, it ends the context on "bye"
+type counter struct {
+	mu    sync.Mutex
+	calls int
+	done  []*func(c *neo.Context)
+}
+
+func (s *counter) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return &neo.Context{Context: c, SessionID: "session-" + info.ID, Person: info, Variables: map[string]interface{}{}}
+}
+
+func (s *counter) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	s.mu.Lock()
+	s.calls++
+	calls := s.calls
+	s.mu.Unlock()
+
+	c.Variables["calls"] = calls
+	if in.Data.Value == "bye" {
+		for _, call := range s.done {
+			(*call)(c)
+		}
+	}
+
+	out := &neo.Output{Intents: []neo.Intent{{Intent: "count", Confidence: 1}}}
+	out.AddTextResponse(in.Data.Value)
+	out.AddOptionsResponse("calls", "", neo.Option{Text: "again", Action: "again", IsPostBack: true})
+	return out, nil
+}
+
+func (s *counter) OnContextIsDone(callback func(c *neo.Context)) {
+	s.done = append(s.done, &callback)
+}
+
+func newTestContext(cassette *Cognitive, id string) *neo.Context {
+	ctx := context.Background()
+	return cassette.CreateNewContext(&ctx, neo.PersonInfo{ID: id})
+}
+
+func ask(t *testing.T, cassette *Cognitive, c *neo.Context, text string) *neo.Output {
+	t.Helper()
+
+	out, err := cassette.GetProtoResponse(c, &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: text}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// record writes a cassette where "hi" is asked twice in a row and the conversation ends with "bye"
+func record(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "cassette.jsonl")
+	cassette, err := New(&counter{}, Options{Path: path, Mode: Record, IgnoreVariables: []string{"calls"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestContext(cassette, "42")
+	ask(t, cassette, c, "hi")
+	ask(t, cassette, c, "Hi ")
+	ask(t, cassette, c, "bye")
+
+	if err = cassette.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newReplay(t *testing.T, path string) *Cognitive {
+	t.Helper()
+
+	cassette, err := New(nil, Options{Path: path, Mode: Replay, IgnoreVariables: []string{"calls"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cassette
+}
+
+func TestReplay(t *testing.T) {
+	cassette := newReplay(t, record(t))
+
+	done := 0
+	cassette.OnContextIsDone(func(c *neo.Context) { done++ })
+
+	c := newTestContext(cassette, "42")
+	out := ask(t, cassette, c, "HI")
+	if out.Responses[0].Value != "hi" || c.Variables["calls"] != 1.0 {
+		t.Errorf("expected the first exchange, got %+v and %+v", out.Responses, c.Variables)
+	}
+	if _, ok := out.Responses[1].Value.(neo.OptionsResponse); !ok {
+		t.Errorf("the options must be decoded, got %T", out.Responses[1].Value)
+	}
+
+	ask(t, cassette, c, "hi")
+	if c.Variables["calls"] != 2.0 {
+		t.Errorf("expected the second exchange, got %+v", c.Variables)
+	}
+
+	ask(t, cassette, c, "hi")
+	if c.Variables["calls"] != 2.0 {
+		t.Errorf("the last exchange must be repeated, got %+v", c.Variables)
+	}
+
+	ask(t, cassette, c, "bye")
+	if done != 1 {
+		t.Error("the recorded end of the context must be replayed")
+	}
+}
+
+func TestTheExchangesAreServedBySession(t *testing.T) {
+	cassette := newReplay(t, record(t))
+
+	first, second := newTestContext(cassette, "1"), newTestContext(cassette, "2")
+	ask(t, cassette, first, "hi")
+	ask(t, cassette, second, "hi")
+	if first.Variables["calls"] != 1.0 || second.Variables["calls"] != 1.0 {
+		t.Errorf("every session must start from the first exchange, got %v and %v", first.Variables["calls"], second.Variables["calls"])
+	}
+
+	ask(t, cassette, first, "hi")
+	if first.Variables["calls"] != 2.0 {
+		t.Errorf("expected the second exchange, got %v", first.Variables["calls"])
+	}
+
+	cassette.CallContextDone(first)
+	third := newTestContext(cassette, "3")
+	third.SessionID = first.SessionID
+	ask(t, cassette, third, "hi")
+	if third.Variables["calls"] != 1.0 {
+		t.Errorf("a closed session must be forgotten, got %v", third.Variables["calls"])
+	}
+}
+
+func TestTheReplayedOutputsAreCopies(t *testing.T) {
+	cassette := newReplay(t, record(t))
+
+	out := ask(t, cassette, newTestContext(cassette, "1"), "hi")
+	out.Intents[0].Intent = "changed"
+	out.Responses[1].Value.(neo.OptionsResponse).Options[0].Text = "changed"
+	out.AddTextResponse("extra")
+
+	again := ask(t, cassette, newTestContext(cassette, "2"), "hi")
+	options := again.Responses[1].Value.(neo.OptionsResponse)
+	if again.Intents[0].Intent != "count" || options.Options[0].Text != "again" || len(again.Responses) != 2 {
+		t.Errorf("the changes of a replayed output can't reach the cassette, got %+v", again)
+	}
+}
+
+func TestMiss(t *testing.T) {
+	path := record(t)
+	cassette, err := New(nil, Options{Path: path, Mode: Replay})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestContext(cassette, "42")
+	c.Variables["calls"] = 7
+	_, err = cassette.GetProtoResponse(c, &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: "hi"}})
+
+	miss := new(MissError)
+	if !errors.Is(err, ErrCassetteMiss) || !errors.As(err, &miss) {
+		t.Fatalf("expected a miss, got %v", err)
+	}
+	if miss.Text != "hi" || len(miss.Candidates) != 2 || len(cassette.Misses()) != 1 {
+		t.Errorf("the miss must list the recorded variables of the text, got %+v", miss)
+	}
+}
+
+func TestAuto(t *testing.T) {
+	path := record(t)
+	service := &counter{}
+	cassette, err := New(service, Options{Path: path, Mode: Auto, IgnoreVariables: []string{"calls"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestContext(cassette, "42")
+	ask(t, cassette, c, "hi")
+	ask(t, cassette, c, "how are you")
+	if service.calls != 1 {
+		t.Errorf("only the missing exchanges must be recorded, got %d calls", service.calls)
+	}
+	if err = cassette.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := newReplay(t, path)
+	ask(t, replay, newTestContext(replay, "43"), "how are you")
+}
+
+func TestInvalidOptions(t *testing.T) {
+	if _, err := New(nil, Options{Mode: "live"}); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("expected ErrInvalidMode, got %v", err)
+	}
+	if _, err := New(nil, Options{Mode: Record}); !errors.Is(err, ErrNoService) {
+		t.Errorf("expected ErrNoService, got %v", err)
+	}
+}
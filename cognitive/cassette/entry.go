@@ -0,0 +1,82 @@
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// entry is one line of the cassette
+type entry struct {
+	Text           string                 `json:"text"`
+	Variables      map[string]interface{} `json:"variables"`
+	Input          *neo.Input             `json:"input"`
+	Output         *neo.Output            `json:"output,omitempty"`
+	VariablesAfter map[string]interface{} `json:"variables_after"`
+	Error          string                 `json:"error,omitempty"`
+	Done           bool                   `json:"done,omitempty"`
+	RecordedAt     time.Time              `json:"recorded_at"`
+}
+
+// normalizeText lowercases the text and collapses the spaces
+func normalizeText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), unicode.IsSpace), " ")
+}
+
+// canonical is the json of the value, the maps are encoded with sorted keys
+func canonical(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+var knownErrors = []error{
+	neo.ErrSessionNotExist,
+	neo.ErrInvalidResponseFromCognitiveService,
+	neo.ErrInvalidInputType,
+	neo.ErrContextNotExist,
+}
+
+// decodeError returns the neocortex error of the message if it is one of them
+func decodeError(msg string) error {
+	for _, err := range knownErrors {
+		if err.Error() == msg {
+			return err
+		}
+	}
+	return errors.New(msg)
+}
+
+// decodeOutput restores the types of the response values lost into the json
+func decodeOutput(out *neo.Output) {
+	if out == nil {
+		return
+	}
+
+	for i, r := range out.Responses {
+		switch r.Type {
+		case neo.Pause:
+			if d, ok := r.Value.(float64); ok {
+				out.Responses[i].Value = time.Duration(d)
+			}
+		case neo.Options:
+			if _, isList := r.Value.([]interface{}); isList {
+				list := make([]neo.OptionsResponse, 0)
+				if json.Unmarshal([]byte(canonical(r.Value)), &list) == nil {
+					out.Responses[i].Value = list
+				}
+				continue
+			}
+			opt := neo.OptionsResponse{}
+			if json.Unmarshal([]byte(canonical(r.Value)), &opt) == nil {
+				out.Responses[i].Value = opt
+			}
+		}
+	}
+}
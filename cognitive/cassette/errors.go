@@ -0,0 +1,39 @@
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNoService = errors.New("the record mode needs a cognitive service")
+var ErrInvalidMode = errors.New("invalid mode, must be record, replay or auto")
+var ErrCassetteMiss = errors.New("the cassette doesn't have the exchange")
+
+// MissError describes an input that is not in the cassette, Candidates are the recorded
+// exchanges with the same text (but other variables), usually the cassette is stale
+type MissError struct {
+	Text       string
+	Variables  map[string]interface{}
+	Candidates []map[string]interface{}
+}
+
+func (e *MissError) Error() string {
+	vars, _ := json.Marshal(e.Variables)
+	msg := fmt.Sprintf("%s: text %q with variables %s", ErrCassetteMiss, e.Text, vars)
+	if len(e.Candidates) == 0 {
+		return msg + ", the text was never recorded"
+	}
+
+	candidates := make([]string, 0, len(e.Candidates))
+	for _, c := range e.Candidates {
+		data, _ := json.Marshal(c)
+		candidates = append(candidates, string(data))
+	}
+	return msg + ", the text was recorded with variables " + strings.Join(candidates, " or ")
+}
+
+func (e *MissError) Unwrap() error {
+	return ErrCassetteMiss
+}
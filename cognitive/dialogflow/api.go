@@ -0,0 +1,178 @@
+package dialogflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// DetectIntentRequest is the body of the detectIntent method of the dialogflow api
+type DetectIntentRequest struct {
+	QueryInput  QueryInput   `json:"queryInput"`
+	QueryParams *QueryParams `json:"queryParams,omitempty"`
+}
+
+type QueryInput struct {
+	Text  *TextInput  `json:"text,omitempty"`
+	Event *EventInput `json:"event,omitempty"`
+}
+
+type TextInput struct {
+	Text         string `json:"text"`
+	LanguageCode string `json:"languageCode"`
+}
+
+type EventInput struct {
+	Name         string                 `json:"name"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty"`
+	LanguageCode string                 `json:"languageCode"`
+}
+
+type QueryParams struct {
+	TimeZone string          `json:"timeZone,omitempty"`
+	Contexts []*QueryContext `json:"contexts,omitempty"`
+}
+
+// QueryContext is a dialogflow context, the name has the form
+// projects/<project>/agent/sessions/<session>/contexts/<context>
+type QueryContext struct {
+	Name          string                 `json:"name"`
+	LifespanCount int                    `json:"lifespanCount,omitempty"`
+	Parameters    map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// DetectIntentResponse is the response of the detectIntent method
+type DetectIntentResponse struct {
+	ResponseID    string       `json:"responseId"`
+	QueryResult   *QueryResult `json:"queryResult"`
+	WebhookStatus *Status      `json:"webhookStatus,omitempty"`
+}
+
+type QueryResult struct {
+	QueryText                 string                 `json:"queryText"`
+	LanguageCode              string                 `json:"languageCode"`
+	Action                    string                 `json:"action"`
+	Parameters                map[string]interface{} `json:"parameters"`
+	AllRequiredParamsPresent  bool                   `json:"allRequiredParamsPresent"`
+	FulfillmentText           string                 `json:"fulfillmentText"`
+	FulfillmentMessages       []*Message             `json:"fulfillmentMessages"`
+	OutputContexts            []*QueryContext        `json:"outputContexts"`
+	Intent                    *Intent                `json:"intent"`
+	IntentDetectionConfidence float64                `json:"intentDetectionConfidence"`
+}
+
+type Intent struct {
+	Name           string `json:"name"`
+	DisplayName    string `json:"displayName"`
+	IsFallback     bool   `json:"isFallback"`
+	EndInteraction bool   `json:"endInteraction"`
+}
+
+// Message is a fulfillment message, only one of the fields is filled
+type Message struct {
+	Platform     string                 `json:"platform,omitempty"`
+	Text         *MessageText           `json:"text,omitempty"`
+	Image        *MessageImage          `json:"image,omitempty"`
+	QuickReplies *MessageQuickReplies   `json:"quickReplies,omitempty"`
+	Card         *MessageCard           `json:"card,omitempty"`
+	Payload      map[string]interface{} `json:"payload,omitempty"`
+}
+
+type MessageText struct {
+	Text []string `json:"text"`
+}
+
+type MessageImage struct {
+	ImageURI          string `json:"imageUri"`
+	AccessibilityText string `json:"accessibilityText"`
+}
+
+type MessageQuickReplies struct {
+	Title        string   `json:"title"`
+	QuickReplies []string `json:"quickReplies"`
+}
+
+type MessageCard struct {
+	Title    string        `json:"title"`
+	Subtitle string        `json:"subtitle"`
+	ImageURI string        `json:"imageUri"`
+	Buttons  []*CardButton `json:"buttons"`
+}
+
+type CardButton struct {
+	Text     string `json:"text"`
+	Postback string `json:"postback"`
+}
+
+type Status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Error *Status `json:"error"`
+}
+
+func (df *Cognitive) sessionPath(sessionID string) string {
+	return "projects/" + df.projectID + "/agent/sessions/" + sessionID
+}
+
+func (df *Cognitive) detectIntent(c *neo.Context, body *DetectIntentRequest) (*DetectIntentResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if c.Context != nil && *c.Context != nil {
+		ctx = *c.Context
+	}
+
+	url := df.Url + "/" + df.sessionPath(c.SessionID) + ":detectIntent"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+df.tokenIdentify)
+
+	res, err := df.client.Do(req)
+	if err != nil {
+		df.logger.Log(neo.Error, "error calling dialogflow", neo.Fields{neo.SessionIDField: c.SessionID, neo.ErrorField: err})
+		return nil, neo.ErrInvalidResponseFromCognitiveService
+	}
+	defer res.Body.Close()
+
+	data, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		apiErr := new(errorResponse)
+		if json.Unmarshal(data, apiErr) == nil && apiErr.Error != nil {
+			df.logger.Log(neo.Error, "dialogflow error", neo.Fields{
+				neo.SessionIDField: c.SessionID,
+				"code":             apiErr.Error.Code,
+				"message":          apiErr.Error.Message,
+			})
+		}
+		return nil, neo.ErrInvalidResponseFromCognitiveService
+	}
+
+	response := new(DetectIntentResponse)
+	if err = json.Unmarshal(data, response); err != nil {
+		return nil, err
+	}
+
+	if response.QueryResult == nil {
+		return nil, neo.ErrInvalidResponseFromCognitiveService
+	}
+
+	return response, nil
+}
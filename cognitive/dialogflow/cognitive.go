@@ -1,20 +1,44 @@
 package dialogflow
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"reflect"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/rs/xid"
 )
 
 type Cognitive struct {
-	tokenIdentify string
-	Url           string
+	tokenIdentify        string
+	Url                  string
+	projectID            string
+	languageCode         string
+	contextName          string
+	contextLifespan      int
+	client               *http.Client
+	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
 }
 
 type NewCognitiveParams struct {
-	Url         string
+	// Url of the dialogflow api, by default https://dialogflow.googleapis.com
+	Url string
+	// AccessToken is an oauth2 token with access to the dialogflow agent
 	AccessToken string
-	Version     string
-	sessionId   string
+	// Version of the api, by default v2
+	Version   string
+	ProjectID string
+	// LanguageCode of the queries, by default en
+	LanguageCode string
+	// ContextName is the dialogflow context used to send the context variables, by default neocortex
+	ContextName string
+	// ContextLifespan of the ContextName context (in turns), by default 50
+	ContextLifespan int
+	// Timeout of every request, by default 30 seconds
+	Timeout time.Duration
 }
 
 func NewCognitive(params NewCognitiveParams) (*Cognitive, error) {
@@ -22,9 +46,98 @@ func NewCognitive(params NewCognitiveParams) (*Cognitive, error) {
 		return nil, errors.New("No token found")
 	}
 
+	if params.ProjectID == "" {
+		return nil, errors.New("No project id found")
+	}
+
+	if params.Url == "" {
+		params.Url = "https://dialogflow.googleapis.com"
+	}
+
+	if params.Version == "" {
+		params.Version = "v2"
+	}
+
+	if params.LanguageCode == "" {
+		params.LanguageCode = "en"
+	}
+
+	if params.ContextName == "" {
+		params.ContextName = "neocortex"
+	}
+
+	if params.ContextLifespan <= 0 {
+		params.ContextLifespan = 50
+	}
+
+	if params.Timeout == 0 {
+		params.Timeout = 30 * time.Second
+	}
+
 	client := &Cognitive{
-		Url: params.Url,
+		tokenIdentify:   params.AccessToken,
+		Url:             params.Url + "/" + params.Version,
+		projectID:       params.ProjectID,
+		languageCode:    params.LanguageCode,
+		contextName:     params.ContextName,
+		contextLifespan: params.ContextLifespan,
+		client:          &http.Client{Timeout: params.Timeout},
+		logger:          neo.DefaultLogger(),
 	}
 
 	return client, nil
 }
+
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (df *Cognitive) SetLogger(logger neo.Logger) {
+	df.logger = logger
+}
+
+func (df *Cognitive) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	// dialogflow creates the sessions on the first query, so a new id is enough
+	id := xid.New()
+	return &neo.Context{
+		Context:   c,
+		SessionID: id.String(),
+		Person:    info,
+		Variables: map[string]interface{}{},
+	}
+}
+
+func (df *Cognitive) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if c == nil {
+		return nil, neo.ErrContextNotExist
+	}
+
+	var req *DetectIntentRequest
+	switch in.Data.Type {
+
+	// Dialogflow text queries, the emojis are sent as text too
+	case neo.InputText, neo.InputEmoji:
+		_, req = df.NewInputText(in.Data.Value, c, in.Intents, in.Entities)
+	default:
+		return nil, neo.ErrInvalidInputType
+	}
+
+	res, err := df.detectIntent(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := df.NewOutput(c, res)
+
+	if res.QueryResult.Intent != nil && res.QueryResult.Intent.EndInteraction {
+		for _, call := range df.doneContextCallbacks {
+			(*call)(c)
+		}
+	}
+
+	return out, nil
+}
+
+func (df *Cognitive) OnContextIsDone(callback func(c *neo.Context)) {
+	if df.doneContextCallbacks == nil {
+		df.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	df.doneContextCallbacks = append(df.doneContextCallbacks, &callback)
+}
@@ -0,0 +1,253 @@
+package dialogflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// fakeDialogflow answers the detectIntent requests with the response of the test
+type fakeDialogflow struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	path     string
+	auth     string
+	request  DetectIntentRequest
+	status   int
+	response interface{}
+}
+
+func newFakeDialogflow(t *testing.T) *fakeDialogflow {
+	fake := &fakeDialogflow{status: http.StatusOK}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.path = r.URL.Path
+		fake.auth = r.Header.Get("Authorization")
+		fake.request = DetectIntentRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&fake.request)
+
+		w.WriteHeader(fake.status)
+		_ = json.NewEncoder(w).Encode(fake.response)
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (fake *fakeDialogflow) answer(status int, response interface{}) {
+	fake.mu.Lock()
+	fake.status = status
+	fake.response = response
+	fake.mu.Unlock()
+}
+
+func newTestCognitive(t *testing.T, fake *fakeDialogflow) *Cognitive {
+	t.Helper()
+
+	df, err := NewCognitive(NewCognitiveParams{Url: fake.URL, AccessToken: "token", ProjectID: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	df.SetLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat))
+	return df
+}
+
+func newTestContext(df *Cognitive) *neo.Context {
+	ctx := context.Background()
+	c := df.CreateNewContext(&ctx, neo.PersonInfo{ID: "42", Locale: "es_PE", Timezone: "America/Lima"})
+	c.Variables["plan"] = "pro"
+	return c
+}
+
+func textInput(text string) *neo.Input {
+	return &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: text}}
+}
+
+func TestIntentsAndEntities(t *testing.T) {
+	fake := newFakeDialogflow(t)
+	df := newTestCognitive(t, fake)
+	c := newTestContext(df)
+
+	fake.answer(http.StatusOK, DetectIntentResponse{
+		QueryResult: &QueryResult{
+			Action: "order.create",
+			Parameters: map[string]interface{}{
+				"product":  []interface{}{"laptop", "mouse"},
+				"quantity": 2.0,
+				"gift":     true,
+				"date":     map[string]interface{}{"startDate": "2020-01-01", "endDate": "2020-01-31"},
+				"color":    "",
+			},
+			Intent:                    &Intent{Name: "projects/shop/agent/intents/1", DisplayName: "order", IsFallback: true},
+			IntentDetectionConfidence: 0.9,
+			FulfillmentMessages: []*Message{
+				{Text: &MessageText{Text: []string{"sure", ""}}},
+				{Platform: "FACEBOOK", Text: &MessageText{Text: []string{"only for facebook"}}},
+				{Image: &MessageImage{ImageURI: "https://example.com/laptop.png"}},
+				{QuickReplies: &MessageQuickReplies{Title: "color?", QuickReplies: []string{"black", "white"}}},
+				{Card: &MessageCard{Title: "laptop", Buttons: []*CardButton{{Text: "buy"}, {Text: "docs", Postback: "https://example.com"}}}},
+				{Payload: map[string]interface{}{"custom": true}},
+			},
+		},
+		WebhookStatus: &Status{Code: 14, Message: "unavailable"},
+	})
+
+	out, err := df.GetProtoResponse(c, textInput("two laptops and a mouse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.mu.Lock()
+	if want := "/v2/projects/shop/agent/sessions/" + c.SessionID + ":detectIntent"; fake.path != want {
+		t.Errorf("expected the path %q, got %q", want, fake.path)
+	}
+	if fake.auth != "Bearer token" {
+		t.Errorf("the access token must be sent, got %q", fake.auth)
+	}
+	if text := fake.request.QueryInput.Text; text == nil || text.Text != "two laptops and a mouse" || text.LanguageCode != "es-PE" {
+		t.Errorf("unexpected query input %+v", text)
+	}
+	if fake.request.QueryParams == nil || fake.request.QueryParams.TimeZone != "America/Lima" {
+		t.Errorf("the timezone of the person must be sent, got %+v", fake.request.QueryParams)
+	}
+	fake.mu.Unlock()
+
+	if len(out.Intents) != 1 || out.Intents[0].Intent != "order" || out.Intents[0].Confidence != 0.9 {
+		t.Errorf("unexpected intents %+v", out.Intents)
+	}
+	if len(out.VisitedNodes) != 1 || out.VisitedNodes[0].Title != "order" || out.VisitedNodes[0].Conditions != "order.create" {
+		t.Errorf("unexpected nodes %+v", out.VisitedNodes)
+	}
+
+	entities := map[string][]string{}
+	for _, e := range out.Entities {
+		entities[e.Entity] = append(entities[e.Entity], e.Value)
+	}
+	if len(entities) != 4 || len(entities["product"]) != 2 || entities["product"][1] != "mouse" ||
+		entities["quantity"][0] != "2" || entities["gift"][0] != "true" {
+		t.Errorf("unexpected entities %+v", entities)
+	}
+	for _, e := range out.Entities {
+		if e.Entity == "date" && e.Metadata["startDate"] != "2020-01-01" {
+			t.Errorf("the composite values must be kept into the metadata, got %+v", e.Metadata)
+		}
+	}
+
+	if len(out.Logs) != 2 || out.Logs[0].Level != neo.Error || out.Logs[1].Level != neo.Warn {
+		t.Errorf("the webhook error and the fallback must be logged into the output, got %d logs", len(out.Logs))
+	}
+
+	types := []neo.ResponseType{}
+	for _, r := range out.Responses {
+		types = append(types, r.Type)
+	}
+	want := []neo.ResponseType{neo.Text, neo.Image, neo.Options, neo.Options, neo.Unknown}
+	if len(types) != len(want) {
+		t.Fatalf("expected the responses %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("expected the responses %v, got %v", want, types)
+		}
+	}
+
+	card := out.Responses[3].Value.(neo.OptionsResponse)
+	if !card.Options[0].IsPostBack || card.Options[0].Action != "buy" || card.Options[1].IsPostBack {
+		t.Errorf("the buttons of the card must be postbacks unless they are links, got %+v %+v", card.Options[0], card.Options[1])
+	}
+}
+
+func TestContextVariables(t *testing.T) {
+	fake := newFakeDialogflow(t)
+	df := newTestCognitive(t, fake)
+	c := newTestContext(df)
+	c.Variables["cart"] = 1.0
+
+	fake.answer(http.StatusOK, DetectIntentResponse{
+		QueryResult: &QueryResult{
+			FulfillmentText: "added",
+			OutputContexts: []*QueryContext{
+				// the intents set their parameters into other contexts, they overwrite the neocortex context
+				{Name: df.contextPath(c, "order"), Parameters: map[string]interface{}{"cart": 2.0, "product": "laptop", "product.original": "laptops"}},
+				{Name: df.contextPath(c, "neocortex"), Parameters: map[string]interface{}{"cart": 1.0, "plan": "pro"}},
+			},
+		},
+	})
+
+	out, err := df.GetProtoResponse(c, textInput("add a laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.mu.Lock()
+	contexts := fake.request.QueryParams.Contexts
+	fake.mu.Unlock()
+	if len(contexts) != 1 || contexts[0].Name != df.contextPath(c, "neocortex") || contexts[0].LifespanCount != 50 ||
+		contexts[0].Parameters["plan"] != "pro" || contexts[0].Parameters["cart"] != 1.0 {
+		t.Fatalf("the context variables must be sent into the neocortex context, got %+v", contexts)
+	}
+
+	if len(out.Responses) != 1 || out.Responses[0].Value != "added" {
+		t.Errorf("without messages the fulfillment text must be answered, got %+v", out.Responses)
+	}
+
+	if c.Variables["cart"] != 2.0 || c.Variables["product"] != "laptop" || c.Variables["plan"] != "pro" {
+		t.Errorf("the parameters of the output contexts must be merged into the variables, got %+v", c.Variables)
+	}
+	if _, exist := c.Variables["product.original"]; exist {
+		t.Error("the original texts of the parameters can't be merged")
+	}
+}
+
+func TestEndInteraction(t *testing.T) {
+	fake := newFakeDialogflow(t)
+	df := newTestCognitive(t, fake)
+	c := newTestContext(df)
+
+	var done *neo.Context
+	df.OnContextIsDone(func(c *neo.Context) { done = c })
+
+	fake.answer(http.StatusOK, DetectIntentResponse{QueryResult: &QueryResult{Intent: &Intent{DisplayName: "bye"}}})
+	if _, err := df.GetProtoResponse(c, textInput("hi")); err != nil || done != nil {
+		t.Fatalf("the context can't be done, got %v", err)
+	}
+
+	fake.answer(http.StatusOK, DetectIntentResponse{QueryResult: &QueryResult{Intent: &Intent{DisplayName: "bye", EndInteraction: true}}})
+	if _, err := df.GetProtoResponse(c, textInput("bye")); err != nil || done != c {
+		t.Fatalf("the end of the interaction must finish the context, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	fake := newFakeDialogflow(t)
+	df := newTestCognitive(t, fake)
+	c := newTestContext(df)
+
+	fake.answer(http.StatusForbidden, errorResponse{Error: &Status{Code: 403, Message: "denied"}})
+	if _, err := df.GetProtoResponse(c, textInput("hi")); !errors.Is(err, neo.ErrInvalidResponseFromCognitiveService) {
+		t.Errorf("expected ErrInvalidResponseFromCognitiveService, got %v", err)
+	}
+
+	fake.answer(http.StatusOK, DetectIntentResponse{})
+	if _, err := df.GetProtoResponse(c, textInput("hi")); !errors.Is(err, neo.ErrInvalidResponseFromCognitiveService) {
+		t.Errorf("a response without query result must be invalid, got %v", err)
+	}
+
+	audio := &neo.Input{Data: neo.InputData{Type: neo.InputAudio, Data: []byte{1}}}
+	if _, err := df.GetProtoResponse(c, audio); !errors.Is(err, neo.ErrInvalidInputType) {
+		t.Errorf("expected ErrInvalidInputType, got %v", err)
+	}
+
+	if _, err := NewCognitive(NewCognitiveParams{AccessToken: "token"}); err == nil {
+		t.Error("the project id is required")
+	}
+}
@@ -0,0 +1,64 @@
+package dialogflow
+
+import (
+	"sort"
+	"strings"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func (df *Cognitive) contextPath(c *neo.Context, name string) string {
+	return df.sessionPath(c.SessionID) + "/contexts/" + name
+}
+
+func contextShortName(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// contextsFromVariables puts the context variables as parameters of the neocortex context
+func (df *Cognitive) contextsFromVariables(c *neo.Context) []*QueryContext {
+	if len(c.Variables) == 0 {
+		return nil
+	}
+
+	params := make(map[string]interface{}, len(c.Variables))
+	for k, v := range c.Variables {
+		params[k] = v
+	}
+
+	return []*QueryContext{{
+		Name:          df.contextPath(c, df.contextName),
+		LifespanCount: df.contextLifespan,
+		Parameters:    params,
+	}}
+}
+
+// syncVariables merges the parameters of the output contexts into the context variables,
+// the parameters of the other contexts (set by the intents) overwrite the neocortex context
+func (df *Cognitive) syncVariables(c *neo.Context, contexts []*QueryContext) {
+	if c.Variables == nil {
+		c.Variables = map[string]interface{}{}
+	}
+
+	active := make([]*QueryContext, 0, len(contexts))
+	for _, ctx := range contexts {
+		if ctx == nil {
+			continue
+		}
+		active = append(active, ctx)
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return contextShortName(active[i].Name) == df.contextName && contextShortName(active[j].Name) != df.contextName
+	})
+
+	for _, ctx := range active {
+		for k, v := range ctx.Parameters {
+			// dialogflow adds the raw text of every parameter as <name>.original
+			if strings.HasSuffix(k, ".original") {
+				continue
+			}
+			c.Variables[k] = v
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package dialogflow
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+func (df *Cognitive) NewInput(data neo.InputData, intents []neo.Intent, entities []neo.Entity) *neo.Input {
+	if intents == nil {
+		intents = make([]neo.Intent, 0)
+	}
+
+	if entities == nil {
+		entities = make([]neo.Entity, 0)
+	}
+
+	return &neo.Input{
+		Data:     data,
+		Intents:  intents,
+		Entities: entities,
+	}
+}
@@ -0,0 +1,45 @@
+package dialogflow
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+// NewInputText builds the detectIntent request of a text, the context variables are sent into the
+// dialogflow context configured by ContextName
+func (df *Cognitive) NewInputText(text string, c *neo.Context, intents []neo.Intent, entities []neo.Entity) (*neo.Input, *DetectIntentRequest) {
+	req := &DetectIntentRequest{
+		QueryInput: QueryInput{
+			Text: &TextInput{
+				Text:         text,
+				LanguageCode: df.languageFor(c),
+			},
+		},
+		QueryParams: &QueryParams{
+			TimeZone: c.Person.Timezone,
+			Contexts: df.contextsFromVariables(c),
+		},
+	}
+
+	data := neo.InputData{
+		Type:  neo.InputText,
+		Value: text,
+		Data:  []byte(text),
+	}
+
+	return df.NewInput(data, intents, entities), req
+}
+
+// languageFor uses the locale of the person (e.g. es_PE -> es-PE) if it exists
+func (df *Cognitive) languageFor(c *neo.Context) string {
+	if c.Person.Locale == "" {
+		return df.languageCode
+	}
+
+	locale := []byte(c.Person.Locale)
+	for i, b := range locale {
+		if b == '_' {
+			locale[i] = '-'
+		}
+	}
+	return string(locale)
+}
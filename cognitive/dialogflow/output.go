@@ -0,0 +1,144 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func (df *Cognitive) NewOutput(c *neo.Context, r *DetectIntentResponse) *neo.Output {
+	result := r.QueryResult
+
+	intents := make([]neo.Intent, 0)
+	nodes := make([]*neo.DialogNode, 0)
+	if result.Intent != nil {
+		intents = append(intents, neo.Intent{
+			Intent:     result.Intent.DisplayName,
+			Confidence: result.IntentDetectionConfidence,
+		})
+
+		nodes = append(nodes, &neo.DialogNode{
+			Name:       result.Intent.Name,
+			Title:      result.Intent.DisplayName,
+			Conditions: result.Action,
+		})
+	}
+
+	entities := getNeocortexEntities(result.Parameters, result.IntentDetectionConfidence)
+
+	logs := make([]*neo.LogMessage, 0)
+	if r.WebhookStatus != nil && r.WebhookStatus.Code != 0 {
+		logs = append(logs, &neo.LogMessage{
+			Level:   neo.Error,
+			Message: fmt.Sprintf("webhook error %d: %s", r.WebhookStatus.Code, r.WebhookStatus.Message),
+		})
+	}
+
+	if result.Intent != nil && result.Intent.IsFallback {
+		logs = append(logs, &neo.LogMessage{
+			Level:   neo.Warn,
+			Message: "fallback intent: " + result.Intent.DisplayName,
+		})
+	}
+
+	responses := make([]neo.Response, 0)
+	for _, m := range result.FulfillmentMessages {
+		// only the generic messages, the messages of other platforms are ignored
+		if m == nil || (m.Platform != "" && m.Platform != "PLATFORM_UNSPECIFIED") {
+			continue
+		}
+
+		switch {
+		case m.Text != nil:
+			responses = append(responses, df.newTextResponses(m.Text)...)
+		case m.Image != nil:
+			responses = append(responses, df.newImageResponse(m.Image))
+		case m.QuickReplies != nil:
+			responses = append(responses, df.newQuickRepliesResponse(m.QuickReplies))
+		case m.Card != nil:
+			responses = append(responses, df.newCardResponse(m.Card))
+		default:
+			responses = append(responses, df.newUnknownResponse(m))
+		}
+	}
+
+	if len(responses) == 0 && result.FulfillmentText != "" {
+		responses = append(responses, neo.Response{
+			Type:     neo.Text,
+			Value:    result.FulfillmentText,
+			IsTyping: false,
+		})
+	}
+
+	df.syncVariables(c, result.OutputContexts)
+
+	return &neo.Output{
+		Logs:         logs,
+		VisitedNodes: nodes,
+		Intents:      intents,
+		Entities:     entities,
+		Responses:    responses,
+	}
+}
+
+// getNeocortexEntities converts the parameters of the intent into entities, the lists produce one
+// entity per item and the composite values (e.g. date-period) keep the original value into the metadata
+func getNeocortexEntities(params map[string]interface{}, confidence float64) []neo.Entity {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entities := make([]neo.Entity, 0)
+	for _, name := range names {
+		values, isList := params[name].([]interface{})
+		if !isList {
+			values = []interface{}{params[name]}
+		}
+
+		for _, v := range values {
+			value, metadata := parameterValue(v)
+			if value == "" {
+				continue
+			}
+
+			entities = append(entities, neo.Entity{
+				Entity:     name,
+				Value:      value,
+				Confidence: confidence,
+				Location:   []int64{},
+				Metadata:   metadata,
+			})
+		}
+	}
+
+	return entities
+}
+
+func parameterValue(v interface{}) (string, map[string]interface{}) {
+	switch value := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return "", nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value), value
+		}
+		return string(data), value
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
@@ -0,0 +1,13 @@
+package dialogflow
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+func (df *Cognitive) newImageResponse(m *MessageImage) neo.Response {
+	return neo.Response{
+		Type:     neo.Image,
+		Value:    m.ImageURI,
+		IsTyping: false,
+	}
+}
@@ -0,0 +1,58 @@
+package dialogflow
+
+import (
+	"strings"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func (df *Cognitive) newQuickRepliesResponse(m *MessageQuickReplies) neo.Response {
+	options := make([]*neo.Option, 0)
+	for _, reply := range m.QuickReplies {
+		options = append(options, &neo.Option{
+			Text:       reply,
+			Action:     reply,
+			IsPostBack: true,
+		})
+	}
+
+	return neo.Response{
+		Type: neo.Options,
+		Value: neo.OptionsResponse{
+			Title:   m.Title,
+			Options: options,
+		},
+		IsTyping: false,
+	}
+}
+
+func (df *Cognitive) newCardResponse(m *MessageCard) neo.Response {
+	options := make([]*neo.Option, 0)
+	for _, b := range m.Buttons {
+		if b == nil {
+			continue
+		}
+
+		action := b.Postback
+		if action == "" {
+			action = b.Text
+		}
+
+		options = append(options, &neo.Option{
+			Text:       b.Text,
+			Action:     action,
+			IsPostBack: !strings.HasPrefix(action, "http"),
+		})
+	}
+
+	return neo.Response{
+		Type: neo.Options,
+		Value: neo.OptionsResponse{
+			Title:       m.Title,
+			Description: m.Subtitle,
+			Image:       m.ImageURI,
+			Options:     options,
+		},
+		IsTyping: false,
+	}
+}
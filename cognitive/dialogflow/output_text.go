@@ -0,0 +1,20 @@
+package dialogflow
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+func (df *Cognitive) newTextResponses(m *MessageText) []neo.Response {
+	responses := make([]neo.Response, 0)
+	for _, text := range m.Text {
+		if text == "" {
+			continue
+		}
+		responses = append(responses, neo.Response{
+			Type:     neo.Text,
+			Value:    text,
+			IsTyping: false,
+		})
+	}
+	return responses
+}
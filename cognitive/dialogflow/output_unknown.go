@@ -0,0 +1,22 @@
+package dialogflow
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+// newUnknownResponse keeps the custom payloads as they are
+func (df *Cognitive) newUnknownResponse(m *Message) neo.Response {
+	if m.Payload != nil {
+		return neo.Response{
+			Type:     neo.Unknown,
+			Value:    m.Payload,
+			IsTyping: false,
+		}
+	}
+
+	return neo.Response{
+		Type:     neo.Unknown,
+		Value:    "unknown or not implemented type of message",
+		IsTyping: false,
+	}
+}
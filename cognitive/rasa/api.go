@@ -0,0 +1,164 @@
+package rasa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// WebhookMessage is the body of the rest channel of rasa (/webhooks/rest/webhook)
+type WebhookMessage struct {
+	Sender  string `json:"sender"`
+	Message string `json:"message"`
+}
+
+// Utterance is a message of the bot returned by the rest channel
+type Utterance struct {
+	RecipientID string                 `json:"recipient_id"`
+	Text        string                 `json:"text,omitempty"`
+	Image       string                 `json:"image,omitempty"`
+	Buttons     []*Button              `json:"buttons,omitempty"`
+	Custom      map[string]interface{} `json:"custom,omitempty"`
+	Attachment  interface{}            `json:"attachment,omitempty"`
+}
+
+type Button struct {
+	Title   string `json:"title"`
+	Payload string `json:"payload"`
+}
+
+// ParseResult is the response of /model/parse
+type ParseResult struct {
+	Text          string         `json:"text"`
+	Intent        *ParsedIntent  `json:"intent"`
+	Entities      []ParsedEntity `json:"entities"`
+	IntentRanking []ParsedIntent `json:"intent_ranking"`
+}
+
+type ParsedIntent struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+type ParsedEntity struct {
+	Entity           string      `json:"entity"`
+	Value            interface{} `json:"value"`
+	Start            int64       `json:"start"`
+	End              int64       `json:"end"`
+	Confidence       *float64    `json:"confidence,omitempty"`
+	ConfidenceEntity *float64    `json:"confidence_entity,omitempty"`
+	Extractor        string      `json:"extractor,omitempty"`
+	Role             string      `json:"role,omitempty"`
+	Group            string      `json:"group,omitempty"`
+}
+
+type tracker struct {
+	Slots map[string]interface{} `json:"slots"`
+}
+
+func (rasa *Cognitive) endpoint(path string) string {
+	if rasa.token == "" {
+		return rasa.url + path
+	}
+	return rasa.url + path + "?token=" + url.QueryEscape(rasa.token)
+}
+
+func (rasa *Cognitive) call(c *neo.Context, method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	ctx := context.Background()
+	if c.Context != nil && *c.Context != nil {
+		ctx = *c.Context
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rasa.endpoint(path), reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := rasa.client.Do(req)
+	if err != nil {
+		rasa.logger.Log(neo.Error, "error calling rasa", neo.Fields{neo.SessionIDField: c.SessionID, "path": path, neo.ErrorField: err})
+		return neo.ErrInvalidResponseFromCognitiveService
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		rasa.logger.Log(neo.Error, "rasa error", neo.Fields{
+			neo.SessionIDField: c.SessionID,
+			"path":             path,
+			"status":           res.StatusCode,
+			"body":             string(data),
+		})
+		return neo.ErrInvalidResponseFromCognitiveService
+	}
+
+	if err = json.Unmarshal(data, result); err != nil {
+		rasa.logger.Log(neo.Error, "invalid response of rasa", neo.Fields{neo.SessionIDField: c.SessionID, "path": path, neo.ErrorField: err})
+		return neo.ErrInvalidResponseFromCognitiveService
+	}
+
+	return nil
+}
+
+func (rasa *Cognitive) parseMessage(c *neo.Context, text string) (*ParseResult, error) {
+	result := new(ParseResult)
+	body := map[string]string{"text": text, "message_id": c.SessionID}
+	if err := rasa.call(c, http.MethodPost, "/model/parse", body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (rasa *Cognitive) sendMessage(c *neo.Context, msg *WebhookMessage) ([]*Utterance, error) {
+	utterances := make([]*Utterance, 0)
+	if err := rasa.call(c, http.MethodPost, "/webhooks/rest/webhook", msg, &utterances); err != nil {
+		return nil, err
+	}
+	return utterances, nil
+}
+
+func (rasa *Cognitive) trackerSlots(c *neo.Context) (map[string]interface{}, error) {
+	t := new(tracker)
+	path := "/conversations/" + url.PathEscape(c.SessionID) + "/tracker"
+	if err := rasa.call(c, http.MethodGet, path, nil, t); err != nil {
+		return nil, err
+	}
+	return t.Slots, nil
+}
+
+// syncSlots puts the slots with value into the context variables
+func (rasa *Cognitive) syncSlots(c *neo.Context, slots map[string]interface{}) {
+	if c.Variables == nil {
+		c.Variables = map[string]interface{}{}
+	}
+
+	for k, v := range slots {
+		if v == nil {
+			continue
+		}
+		c.Variables[k] = v
+	}
+}
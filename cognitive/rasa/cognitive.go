@@ -0,0 +1,135 @@
+package rasa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+type Cognitive struct {
+	url                  string
+	token                string
+	parse                bool
+	trackSlots           bool
+	endIntents           map[string]bool
+	client               *http.Client
+	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
+}
+
+type NewCognitiveParams struct {
+	// Url of the rasa server, e.g. http://localhost:5005
+	Url string
+	// Token of the rasa http api (rasa run --auth-token), optional
+	Token string
+	// DisableParse skips the call to /model/parse, then the output doesn't have intents and entities
+	DisableParse bool
+	// TrackSlots reads the slots of the tracker after every message and puts them into the context variables
+	TrackSlots bool
+	// EndIntents finish the context when one of them is the best intent of the message (e.g. goodbye),
+	// rasa doesn't tell the end of the conversation so they need the parse
+	EndIntents []string
+	// Timeout of every request, by default 30 seconds
+	Timeout time.Duration
+}
+
+func NewCognitive(params NewCognitiveParams) (*Cognitive, error) {
+	if params.Url == "" {
+		return nil, errors.New("No rasa url found")
+	}
+
+	if params.Timeout == 0 {
+		params.Timeout = 30 * time.Second
+	}
+
+	endIntents := make(map[string]bool, len(params.EndIntents))
+	for _, intent := range params.EndIntents {
+		endIntents[intent] = true
+	}
+
+	return &Cognitive{
+		url:        strings.TrimSuffix(params.Url, "/"),
+		token:      params.Token,
+		parse:      !params.DisableParse,
+		trackSlots: params.TrackSlots,
+		endIntents: endIntents,
+		client:     &http.Client{Timeout: params.Timeout},
+		logger:     neo.DefaultLogger(),
+	}, nil
+}
+
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (rasa *Cognitive) SetLogger(logger neo.Logger) {
+	rasa.logger = logger
+}
+
+// CreateNewContext creates a new context, its session id is used as the sender id of rasa
+func (rasa *Cognitive) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	id := xid.New()
+	return &neo.Context{
+		Context:   c,
+		SessionID: id.String(),
+		Person:    info,
+		Variables: map[string]interface{}{},
+	}
+}
+
+func (rasa *Cognitive) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if c == nil {
+		return nil, neo.ErrContextNotExist
+	}
+
+	var msg *WebhookMessage
+	switch in.Data.Type {
+
+	// Rasa only understands text, the emojis are text too
+	case neo.InputText, neo.InputEmoji:
+		_, msg = rasa.NewInputText(in.Data.Value, c, in.Intents, in.Entities)
+	default:
+		return nil, neo.ErrInvalidInputType
+	}
+
+	var parsed *ParseResult
+	if rasa.parse {
+		var err error
+		parsed, err = rasa.parseMessage(c, msg.Message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	utterances, err := rasa.sendMessage(c, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if rasa.trackSlots {
+		slots, err := rasa.trackerSlots(c)
+		if err != nil {
+			return nil, err
+		}
+		rasa.syncSlots(c, slots)
+	}
+
+	out := rasa.NewOutput(c, parsed, utterances)
+
+	if len(out.Intents) > 0 && rasa.endIntents[out.Intents[0].Intent] {
+		for _, call := range rasa.doneContextCallbacks {
+			(*call)(c)
+		}
+	}
+
+	return out, nil
+}
+
+func (rasa *Cognitive) OnContextIsDone(callback func(c *neo.Context)) {
+	if rasa.doneContextCallbacks == nil {
+		rasa.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	rasa.doneContextCallbacks = append(rasa.doneContextCallbacks, &callback)
+}
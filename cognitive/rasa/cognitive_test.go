@@ -0,0 +1,192 @@
+package rasa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// fakeRasa answers the http api of rasa with the responses of the test
+type fakeRasa struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	tokens     []string
+	message    WebhookMessage
+	parse      ParseResult
+	utterances []*Utterance
+	slots      map[string]interface{}
+}
+
+func newFakeRasa(t *testing.T) *fakeRasa {
+	fake := &fakeRasa{}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.tokens = append(fake.tokens, r.URL.Query().Get("token"))
+		switch r.URL.Path {
+		case "/model/parse":
+			_ = json.NewEncoder(w).Encode(fake.parse)
+		case "/webhooks/rest/webhook":
+			_ = json.NewDecoder(r.Body).Decode(&fake.message)
+			_ = json.NewEncoder(w).Encode(fake.utterances)
+		case "/conversations/" + fake.message.Sender + "/tracker":
+			_ = json.NewEncoder(w).Encode(tracker{Slots: fake.slots})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func newTestCognitive(t *testing.T, fake *fakeRasa, params NewCognitiveParams) *Cognitive {
+	t.Helper()
+
+	params.Url = fake.URL + "/"
+	rasa, err := NewCognitive(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rasa.SetLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat))
+	return rasa
+}
+
+func newTestContext(rasa *Cognitive) *neo.Context {
+	ctx := context.Background()
+	return rasa.CreateNewContext(&ctx, neo.PersonInfo{ID: "42"})
+}
+
+func textInput(text string) *neo.Input {
+	return &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: text}}
+}
+
+func TestIntentsEntitiesAndResponses(t *testing.T) {
+	fake := newFakeRasa(t)
+	rasa := newTestCognitive(t, fake, NewCognitiveParams{Token: "s3cret", TrackSlots: true})
+	c := newTestContext(rasa)
+
+	text := "quiero un café en Lima"
+	confidence := 0.7
+	fake.parse = ParseResult{
+		Text:          text,
+		Intent:        &ParsedIntent{Name: "order", Confidence: 0.9},
+		IntentRanking: []ParsedIntent{{Name: "order", Confidence: 0.9}, {Name: "greet", Confidence: 0.1}},
+		Entities: []ParsedEntity{
+			{Entity: "drink", Value: "café", Start: 10, End: 14, ConfidenceEntity: &confidence, Extractor: "DIETClassifier"},
+			{Entity: "city", Value: "Lima", Start: 18, End: 22},
+			{Entity: "amount", Value: 1.0, Start: 7, End: 9},
+		},
+	}
+	fake.utterances = []*Utterance{
+		{Text: "which size?", Buttons: []*Button{{Title: "big", Payload: `/size{"size": "big"}`}, {Title: "menu", Payload: "https://example.com"}}},
+		{Text: "coming", Image: "https://example.com/coffee.png"},
+		{Custom: map[string]interface{}{"eta": 5.0}},
+	}
+	fake.slots = map[string]interface{}{"city": "Lima", "size": nil}
+
+	out, err := rasa.GetProtoResponse(c, textInput(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.mu.Lock()
+	if fake.message.Sender != c.SessionID || fake.message.Message != text {
+		t.Errorf("the session id must be the sender, got %+v", fake.message)
+	}
+	for _, token := range fake.tokens {
+		if token != "s3cret" {
+			t.Errorf("the token must be sent into every call, got %q", fake.tokens)
+			break
+		}
+	}
+	fake.mu.Unlock()
+
+	if len(out.Intents) != 2 || out.Intents[0].Intent != "order" || out.Intents[1].Intent != "greet" {
+		t.Errorf("the intents must follow the ranking, got %+v", out.Intents)
+	}
+
+	if len(out.Entities) != 3 {
+		t.Fatalf("expected 3 entities, got %+v", out.Entities)
+	}
+	for _, e := range out.Entities {
+		// the locations are character offsets, the text of the location is the value of the entity
+		if got := string([]rune(text)[e.Location[0]:e.Location[1]]); e.Entity != "amount" && got != e.Value {
+			t.Errorf("the location of %s points to %q", e.Entity, got)
+		}
+	}
+	if drink := out.Entities[0]; drink.Confidence != 0.7 || drink.Metadata["extractor"] != "DIETClassifier" {
+		t.Errorf("unexpected entity %+v", drink)
+	}
+	if amount := out.Entities[2]; amount.Value != "1" || amount.Metadata["value"] != 1.0 || amount.Confidence != 1 {
+		t.Errorf("the values that aren't strings must be kept into the metadata, got %+v", amount)
+	}
+
+	want := []neo.ResponseType{neo.Options, neo.Text, neo.Image, neo.Unknown}
+	if len(out.Responses) != len(want) {
+		t.Fatalf("expected %d responses, got %+v", len(want), out.Responses)
+	}
+	for i := range want {
+		if out.Responses[i].Type != want[i] {
+			t.Fatalf("response %d: expected %s, got %s", i, want[i], out.Responses[i].Type)
+		}
+	}
+	options := out.Responses[0].Value.(neo.OptionsResponse)
+	if !options.Options[0].IsPostBack || options.Options[1].IsPostBack {
+		t.Errorf("the payloads must be postbacks unless they are links, got %+v %+v", options.Options[0], options.Options[1])
+	}
+
+	if c.Variables["city"] != "Lima" {
+		t.Errorf("the slots must be put into the variables, got %+v", c.Variables)
+	}
+	if _, exist := c.Variables["size"]; exist {
+		t.Error("the slots without value can't be put into the variables")
+	}
+}
+
+func TestEndIntents(t *testing.T) {
+	fake := newFakeRasa(t)
+	rasa := newTestCognitive(t, fake, NewCognitiveParams{EndIntents: []string{"goodbye"}})
+	c := newTestContext(rasa)
+
+	done := 0
+	rasa.OnContextIsDone(func(ended *neo.Context) {
+		if ended == c {
+			done++
+		}
+	})
+
+	fake.parse = ParseResult{Text: "thanks", IntentRanking: []ParsedIntent{{Name: "thank", Confidence: 0.8}, {Name: "goodbye", Confidence: 0.2}}}
+	if _, err := rasa.GetProtoResponse(c, textInput("thanks")); err != nil || done != 0 {
+		t.Fatalf("the context can't be done, got %d (%v)", done, err)
+	}
+
+	fake.parse = ParseResult{Text: "bye", IntentRanking: []ParsedIntent{{Name: "goodbye", Confidence: 0.9}}}
+	if _, err := rasa.GetProtoResponse(c, textInput("bye")); err != nil || done != 1 {
+		t.Fatalf("the end intent must finish the context, got %d (%v)", done, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	fake := newFakeRasa(t)
+	rasa := newTestCognitive(t, fake, NewCognitiveParams{})
+	c := newTestContext(rasa)
+
+	fake.Close()
+	if _, err := rasa.GetProtoResponse(c, textInput("hi")); !errors.Is(err, neo.ErrInvalidResponseFromCognitiveService) {
+		t.Errorf("expected ErrInvalidResponseFromCognitiveService, got %v", err)
+	}
+
+	audio := &neo.Input{Data: neo.InputData{Type: neo.InputAudio, Data: []byte{1}}}
+	if _, err := rasa.GetProtoResponse(c, audio); !errors.Is(err, neo.ErrInvalidInputType) {
+		t.Errorf("expected ErrInvalidInputType, got %v", err)
+	}
+}
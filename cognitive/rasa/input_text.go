@@ -0,0 +1,21 @@
+package rasa
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+// NewInputText builds the message for the rest channel, the sender is the session id of the context
+func (rasa *Cognitive) NewInputText(text string, c *neo.Context, intents []neo.Intent, entities []neo.Entity) (*neo.Input, *WebhookMessage) {
+	msg := &WebhookMessage{
+		Sender:  c.SessionID,
+		Message: text,
+	}
+
+	data := neo.InputData{
+		Type:  neo.InputText,
+		Value: text,
+		Data:  []byte(text),
+	}
+
+	return rasa.NewInput(data, intents, entities), msg
+}
@@ -0,0 +1,103 @@
+package rasa
+
+import (
+	"fmt"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func (rasa *Cognitive) NewOutput(c *neo.Context, parsed *ParseResult, utterances []*Utterance) *neo.Output {
+	intents := make([]neo.Intent, 0)
+	entities := make([]neo.Entity, 0)
+
+	if parsed != nil {
+		if len(parsed.IntentRanking) > 0 {
+			for _, i := range parsed.IntentRanking {
+				intents = append(intents, getNeocortexIntent(i))
+			}
+		} else if parsed.Intent != nil && parsed.Intent.Name != "" {
+			intents = append(intents, getNeocortexIntent(*parsed.Intent))
+		}
+
+		for _, e := range parsed.Entities {
+			entities = append(entities, getNeocortexEntity(e))
+		}
+	}
+
+	responses := make([]neo.Response, 0)
+	for _, u := range utterances {
+		if u == nil {
+			continue
+		}
+
+		// an utterance can have text, image and buttons at the same time
+		if u.Text != "" {
+			if len(u.Buttons) > 0 {
+				responses = append(responses, rasa.newOptionResponse(u))
+			} else {
+				responses = append(responses, rasa.newTextResponse(u))
+			}
+		} else if len(u.Buttons) > 0 {
+			responses = append(responses, rasa.newOptionResponse(u))
+		}
+
+		if u.Image != "" {
+			responses = append(responses, rasa.newImageResponse(u))
+		}
+
+		if u.Custom != nil || u.Attachment != nil {
+			responses = append(responses, rasa.newUnknownResponse(u))
+		}
+	}
+
+	return &neo.Output{
+		Logs:         []*neo.LogMessage{},
+		VisitedNodes: []*neo.DialogNode{},
+		Intents:      intents,
+		Entities:     entities,
+		Responses:    responses,
+	}
+}
+
+func getNeocortexIntent(i ParsedIntent) neo.Intent {
+	return neo.Intent{
+		Intent:     i.Name,
+		Confidence: i.Confidence,
+	}
+}
+
+// getNeocortexEntity keeps the span of the entity as location and the rasa details as metadata,
+// rasa counts the characters of the text like the locations of neocortex
+func getNeocortexEntity(e ParsedEntity) neo.Entity {
+	confidence := 1.0
+	if e.ConfidenceEntity != nil {
+		confidence = *e.ConfidenceEntity
+	} else if e.Confidence != nil {
+		confidence = *e.Confidence
+	}
+
+	metadata := map[string]interface{}{}
+	if e.Extractor != "" {
+		metadata["extractor"] = e.Extractor
+	}
+	if e.Role != "" {
+		metadata["role"] = e.Role
+	}
+	if e.Group != "" {
+		metadata["group"] = e.Group
+	}
+
+	value, isString := e.Value.(string)
+	if !isString {
+		value = fmt.Sprint(e.Value)
+		metadata["value"] = e.Value
+	}
+
+	return neo.Entity{
+		Entity:     e.Entity,
+		Location:   []int64{e.Start, e.End},
+		Value:      value,
+		Confidence: confidence,
+		Metadata:   metadata,
+	}
+}
@@ -0,0 +1,13 @@
+package rasa
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+func (rasa *Cognitive) newImageResponse(u *Utterance) neo.Response {
+	return neo.Response{
+		Type:     neo.Image,
+		Value:    u.Image,
+		IsTyping: false,
+	}
+}
@@ -0,0 +1,32 @@
+package rasa
+
+import (
+	"strings"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// newOptionResponse converts the buttons, the payload (e.g. /greet{"name": "bob"}) is sent back as the postback
+func (rasa *Cognitive) newOptionResponse(u *Utterance) neo.Response {
+	options := make([]*neo.Option, 0)
+	for _, b := range u.Buttons {
+		if b == nil {
+			continue
+		}
+
+		options = append(options, &neo.Option{
+			Text:       b.Title,
+			Action:     b.Payload,
+			IsPostBack: !strings.HasPrefix(b.Payload, "http"),
+		})
+	}
+
+	return neo.Response{
+		Type: neo.Options,
+		Value: neo.OptionsResponse{
+			Title:   u.Text,
+			Options: options,
+		},
+		IsTyping: false,
+	}
+}
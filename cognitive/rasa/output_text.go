@@ -0,0 +1,13 @@
+package rasa
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+func (rasa *Cognitive) newTextResponse(u *Utterance) neo.Response {
+	return neo.Response{
+		Type:     neo.Text,
+		Value:    u.Text,
+		IsTyping: false,
+	}
+}
@@ -0,0 +1,19 @@
+package rasa
+
+import (
+	neo "github.com/minskylab/neocortex"
+)
+
+// newUnknownResponse keeps the custom payloads and attachments as they are
+func (rasa *Cognitive) newUnknownResponse(u *Utterance) neo.Response {
+	var value interface{} = u.Custom
+	if u.Custom == nil {
+		value = u.Attachment
+	}
+
+	return neo.Response{
+		Type:     neo.Unknown,
+		Value:    value,
+		IsTyping: false,
+	}
+}
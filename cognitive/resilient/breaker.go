@@ -0,0 +1,148 @@
+package resilient
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker
+type BreakerState string
+
+// Closed lets pass all the calls
+const Closed BreakerState = "closed"
+
+// Open rejects all the calls until the open timeout passes
+const Open BreakerState = "open"
+
+// HalfOpen lets pass one call to probe the backend
+const HalfOpen BreakerState = "half-open"
+
+// BreakerStats is a snapshot of the breaker for monitoring
+type BreakerStats struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	TotalCalls          int64        `json:"total_calls"`
+	TotalFailures       int64        `json:"total_failures"`
+	TotalRetries        int64        `json:"total_retries"`
+	TotalRejected       int64        `json:"total_rejected"`
+	OpenedAt            time.Time    `json:"opened_at"`
+	LastError           string       `json:"last_error"`
+}
+
+type breaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	probing     bool
+	stats       BreakerStats
+	onChange    []func(from, to BreakerState)
+	changes     [][2]BreakerState
+}
+
+func newBreaker(threshold int, openTimeout time.Duration) *breaker {
+	return &breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		stats:       BreakerStats{State: Closed},
+	}
+}
+
+// allow returns false if the call must be rejected, after the open timeout one probe is allowed
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.notify()
+
+	switch b.stats.State {
+	case Open:
+		if now.Sub(b.stats.OpenedAt) < b.openTimeout {
+			b.stats.TotalRejected++
+			return false
+		}
+		b.setState(HalfOpen)
+		b.probing = true
+		return true
+	case HalfOpen:
+		if b.probing {
+			b.stats.TotalRejected++
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.notify()
+
+	b.stats.TotalCalls++
+	b.stats.ConsecutiveFailures = 0
+	b.probing = false
+	if b.stats.State != Closed {
+		b.setState(Closed)
+	}
+}
+
+func (b *breaker) failure(now time.Time, err error) {
+	b.mu.Lock()
+	defer b.notify()
+
+	b.stats.TotalCalls++
+	b.stats.TotalFailures++
+	b.stats.ConsecutiveFailures++
+	b.stats.LastError = err.Error()
+	b.probing = false
+
+	if b.stats.State == HalfOpen || b.stats.ConsecutiveFailures >= b.threshold {
+		b.stats.OpenedAt = now
+		if b.stats.State != Open {
+			b.setState(Open)
+		}
+	}
+}
+
+// release frees the probe of a half open breaker when the call didn't count (e.g. invalid input)
+func (b *breaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+func (b *breaker) retried() {
+	b.mu.Lock()
+	b.stats.TotalRetries++
+	b.mu.Unlock()
+}
+
+func (b *breaker) setState(to BreakerState) {
+	b.changes = append(b.changes, [2]BreakerState{b.stats.State, to})
+	b.stats.State = to
+}
+
+// notify unlocks the breaker and calls the callbacks of the state changes, in order
+func (b *breaker) notify() {
+	changes := b.changes
+	callbacks := b.onChange
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		for _, call := range callbacks {
+			call(change[0], change[1])
+		}
+	}
+}
+
+func (b *breaker) snapshot() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats
+	if stats.State == Open && time.Since(stats.OpenedAt) >= b.openTimeout {
+		// the next call will be a probe
+		stats.State = HalfOpen
+	}
+	return stats
+}
@@ -0,0 +1,275 @@
+// Package resilient wraps a cognitive service with timeouts, retries with backoff and a circuit breaker,
+// when the backend is down the person receives a degraded output instead of an error
+package resilient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+type Options struct {
+	// Timeout of every call, it is set as deadline of the Context.Context and the call is abandoned
+	// when it expires, by default 10 seconds
+	Timeout time.Duration
+	// Idempotent services are retried after a timeout, otherwise the timeouts are not retried
+	// because the backend could have applied the abandoned call (e.g. advanced the dialog)
+	Idempotent bool
+	// MaxRetries of a failed call, by default 2, use a negative value to disable the retries
+	MaxRetries int
+	// Backoff is the wait before the first retry, it is doubled on every retry, by default 200ms
+	Backoff time.Duration
+	// MaxBackoff caps the wait between retries, by default 2 seconds
+	MaxBackoff time.Duration
+	// FailureThreshold is the number of consecutive failures that opens the breaker, by default 5
+	FailureThreshold int
+	// OpenTimeout is the time that the breaker stays open before a probe, by default 30 seconds
+	OpenTimeout time.Duration
+	// Retryable decides which errors are transient, by default timeouts and network errors
+	Retryable func(err error) bool
+	// Degraded builds the output sent when the backend is down
+	Degraded func(c *neo.Context, in *neo.Input) *neo.Output
+	// DegradedText is used by the default Degraded output
+	DegradedText string
+}
+
+type Cognitive struct {
+	service neo.CognitiveService
+	options Options
+	breaker *breaker
+}
+
+// Wrap returns the resilient version of the cognitive service
+func Wrap(service neo.CognitiveService, options Options) *Cognitive {
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Second
+	}
+
+	if options.MaxRetries == 0 {
+		options.MaxRetries = 2
+	}
+
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+
+	if options.Backoff == 0 {
+		options.Backoff = 200 * time.Millisecond
+	}
+
+	if options.MaxBackoff == 0 {
+		options.MaxBackoff = 2 * time.Second
+	}
+
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = 5
+	}
+
+	if options.OpenTimeout == 0 {
+		options.OpenTimeout = 30 * time.Second
+	}
+
+	if options.Retryable == nil {
+		options.Retryable = IsTransient
+	}
+
+	if options.DegradedText == "" {
+		options.DegradedText = "We're having trouble right now, please try again in a few minutes"
+	}
+
+	if options.Degraded == nil {
+		text := options.DegradedText
+		options.Degraded = func(c *neo.Context, in *neo.Input) *neo.Output {
+			out := &neo.Output{
+				Entities:     []neo.Entity{},
+				Intents:      []neo.Intent{},
+				VisitedNodes: []*neo.DialogNode{},
+				Logs:         []*neo.LogMessage{},
+			}
+			return out.AddTextResponse(text)
+		}
+	}
+
+	return &Cognitive{
+		service: service,
+		options: options,
+		breaker: newBreaker(options.FailureThreshold, options.OpenTimeout),
+	}
+}
+
+// IsTransient is the default Retryable
+func IsTransient(err error) bool {
+	if errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (r *Cognitive) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return r.service.CreateNewContext(c, info)
+}
+
+func (r *Cognitive) OnContextIsDone(callback func(c *neo.Context)) {
+	r.service.OnContextIsDone(callback)
+}
+
+// CallContextDone passes the closed context to the wrapped service if it implements neo.ContextDoneCaller
+func (r *Cognitive) CallContextDone(c *neo.Context) {
+	if caller, ok := r.service.(neo.ContextDoneCaller); ok {
+		caller.CallContextDone(c)
+	}
+}
+
+// SetLogger passes the logger of the engine to the wrapped service if it implements neo.LoggerSetter
+func (r *Cognitive) SetLogger(logger neo.Logger) {
+	if setter, ok := r.service.(neo.LoggerSetter); ok {
+		setter.SetLogger(logger)
+	}
+}
+
+// GetProtoResponse calls the wrapped service, the transient errors are retried and when they are
+// exhausted (or the breaker is open) the degraded output is returned without error
+func (r *Cognitive) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if c == nil {
+		return nil, neo.ErrContextNotExist
+	}
+
+	if !r.breaker.allow(time.Now()) {
+		return r.degraded(c, in, ErrCircuitOpen), nil
+	}
+
+	backoff := r.options.Backoff
+	var err error
+	for attempt := 0; attempt <= r.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			r.breaker.retried()
+			if !sleep(c, jitter(backoff)) {
+				break
+			}
+			backoff *= 2
+			if backoff > r.options.MaxBackoff {
+				backoff = r.options.MaxBackoff
+			}
+		}
+
+		var out *neo.Output
+		out, err = r.call(c, in)
+		if err == nil {
+			r.breaker.success()
+			return out, nil
+		}
+
+		if !r.options.Retryable(err) {
+			r.breaker.release()
+			return nil, err
+		}
+
+		if errors.Is(err, ErrTimeout) && !r.options.Idempotent {
+			break
+		}
+	}
+
+	r.breaker.failure(time.Now(), err)
+	return r.degraded(c, in, err), nil
+}
+
+type result struct {
+	out *neo.Output
+	err error
+}
+
+// call sets the deadline into the Context.Context of the context and waits the service until the
+// deadline, the services that don't watch the Context.Context are abandoned when it expires
+func (r *Cognitive) call(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	original := c.Context
+
+	parent := context.Background()
+	if original != nil && *original != nil {
+		parent = *original
+	}
+
+	ctx, cancel := context.WithTimeout(parent, r.options.Timeout)
+	defer cancel()
+
+	c.Context = &ctx
+	defer func() { c.Context = original }()
+
+	// buffered, the abandoned call doesn't block its goroutine
+	done := make(chan result, 1)
+	go func() {
+		out, err := r.service.GetProtoResponse(c, in)
+		done <- result{out: out, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil && ctx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+			return nil, ErrTimeout
+		}
+		return res.out, res.err
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+		return nil, ErrTimeout
+	}
+}
+
+func (r *Cognitive) degraded(c *neo.Context, in *neo.Input, err error) *neo.Output {
+	out := r.options.Degraded(c, in)
+	out.Logs = append(out.Logs, &neo.LogMessage{Level: neo.Error, Message: "degraded output: " + err.Error()})
+	return out
+}
+
+// State returns the current state of the circuit breaker
+func (r *Cognitive) State() BreakerState {
+	return r.breaker.snapshot().State
+}
+
+// Stats returns a snapshot of the breaker counters
+func (r *Cognitive) Stats() BreakerStats {
+	return r.breaker.snapshot()
+}
+
+// Status reports the breaker to the engine, see neo.StatusReporter
+func (r *Cognitive) Status() interface{} {
+	return r.Stats()
+}
+
+// OnStateChange registers a callback called when the breaker changes its state
+func (r *Cognitive) OnStateChange(callback func(from, to BreakerState)) {
+	r.breaker.mu.Lock()
+	r.breaker.onChange = append(r.breaker.onChange, callback)
+	r.breaker.mu.Unlock()
+}
+
+// sleep waits the duration, it returns false if the context of the conversation is cancelled before
+func sleep(c *neo.Context, d time.Duration) bool {
+	if c.Context == nil || *c.Context == nil {
+		time.Sleep(d)
+		return true
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-(*c.Context).Done():
+		return false
+	}
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
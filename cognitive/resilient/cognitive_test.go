@@ -0,0 +1,264 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// flaky fails with the errors of its script in order, then it answers
+type flaky struct {
+	mu     sync.Mutex
+	script []error
+	delay  time.Duration
+	calls  int
+}
+
+func (f *flaky) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return &neo.Context{Context: c, SessionID: "session-" + info.ID, Person: info, Variables: map[string]interface{}{}}
+}
+
+func (f *flaky) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	f.mu.Lock()
+	f.calls++
+	var err error
+	if len(f.script) > 0 {
+		err, f.script = f.script[0], f.script[1:]
+	}
+	delay := f.delay
+	f.mu.Unlock()
+
+	// the delay ignores the Context.Context, like a service that doesn't watch it
+	time.Sleep(delay)
+	if err != nil {
+		return nil, err
+	}
+	return (&neo.Output{}).AddTextResponse("ok"), nil
+}
+
+func (f *flaky) OnContextIsDone(callback func(c *neo.Context)) {}
+
+func (f *flaky) fail(errs ...error) {
+	f.mu.Lock()
+	f.script = append(f.script, errs...)
+	f.mu.Unlock()
+}
+
+func (f *flaky) callsSoFar() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// netError is a transient network error
+type netError struct{}
+
+func (netError) Error() string   { return "connection reset" }
+func (netError) Timeout() bool   { return false }
+func (netError) Temporary() bool { return true }
+
+var _ net.Error = netError{}
+
+func newTestContext(r *Cognitive) *neo.Context {
+	ctx := context.Background()
+	return r.CreateNewContext(&ctx, neo.PersonInfo{ID: "42"})
+}
+
+func ask(t *testing.T, r *Cognitive, c *neo.Context) (string, error) {
+	t.Helper()
+
+	out, err := r.GetProtoResponse(c, &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: "hi"}})
+	if err != nil {
+		return "", err
+	}
+	return out.Responses[0].Value.(string), nil
+}
+
+func TestRetries(t *testing.T) {
+	service := &flaky{}
+	r := Wrap(service, Options{MaxRetries: 2, Backoff: time.Millisecond, DegradedText: "degraded"})
+	c := newTestContext(r)
+
+	service.fail(netError{}, netError{})
+	if got, err := ask(t, r, c); err != nil || got != "ok" {
+		t.Fatalf("the transient errors must be retried, got %q (%v)", got, err)
+	}
+	if service.callsSoFar() != 3 || r.Stats().TotalRetries != 2 || r.State() != Closed {
+		t.Errorf("expected 3 calls and 2 retries, got %d and %+v", service.callsSoFar(), r.Stats())
+	}
+
+	service.fail(netError{}, netError{}, netError{})
+	if got, err := ask(t, r, c); err != nil || got != "degraded" {
+		t.Fatalf("the degraded output must be answered when the retries are exhausted, got %q (%v)", got, err)
+	}
+	if r.Stats().ConsecutiveFailures != 1 {
+		t.Errorf("the exhausted retries are one failure, got %+v", r.Stats())
+	}
+}
+
+func TestNotTransientErrors(t *testing.T) {
+	service := &flaky{}
+	r := Wrap(service, Options{Backoff: time.Millisecond})
+	c := newTestContext(r)
+
+	for _, failure := range []error{errors.New("boom"), neo.ErrInvalidResponseFromCognitiveService} {
+		before := service.callsSoFar()
+		service.fail(failure)
+		if _, err := ask(t, r, c); !errors.Is(err, failure) {
+			t.Fatalf("the error must be returned, got %v", err)
+		}
+		if service.callsSoFar()-before != 1 {
+			t.Errorf("%v can't be retried", failure)
+		}
+	}
+
+	if r.Stats().TotalFailures != 0 {
+		t.Errorf("the errors that aren't transient don't count for the breaker, got %+v", r.Stats())
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{ErrTimeout, true},
+		{context.DeadlineExceeded, true},
+		{netError{}, true},
+		{neo.ErrInvalidResponseFromCognitiveService, false},
+		{errors.New("boom"), false},
+	}
+	for _, tc := range cases {
+		if got := IsTransient(tc.err); got != tc.want {
+			t.Errorf("%v: expected %v, got %v", tc.err, tc.want, got)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	service := &flaky{}
+	r := Wrap(service, Options{MaxRetries: -1, FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond, DegradedText: "degraded"})
+	c := newTestContext(r)
+
+	var mu sync.Mutex
+	changes := []BreakerState{}
+	r.OnStateChange(func(from, to BreakerState) {
+		mu.Lock()
+		changes = append(changes, to)
+		mu.Unlock()
+	})
+
+	service.fail(netError{}, netError{})
+	ask(t, r, c)
+	if r.State() != Closed {
+		t.Fatal("the breaker must be closed under the threshold")
+	}
+	ask(t, r, c)
+	if r.State() != Open {
+		t.Fatal("the breaker must be open at the threshold")
+	}
+
+	calls := service.callsSoFar()
+	if got, _ := ask(t, r, c); got != "degraded" || service.callsSoFar() != calls || r.Stats().TotalRejected != 1 {
+		t.Fatalf("the open breaker must reject the calls, got %q and %+v", got, r.Stats())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if r.State() != HalfOpen {
+		t.Fatalf("the breaker must be half open after the open timeout, got %s", r.State())
+	}
+
+	// a failed probe opens it again
+	service.fail(netError{})
+	ask(t, r, c)
+	if r.State() != Open {
+		t.Fatalf("a failed probe must open the breaker, got %s", r.State())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if got, _ := ask(t, r, c); got != "ok" || r.State() != Closed {
+		t.Fatalf("a successful probe must close the breaker, got %q and %s", got, r.State())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []BreakerState{Open, HalfOpen, Open, HalfOpen, Closed}
+	if len(changes) != len(want) {
+		t.Fatalf("expected the changes %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("expected the changes %v, got %v", want, changes)
+		}
+	}
+
+	if stats, ok := r.Status().(BreakerStats); !ok || stats.State != Closed || stats.TotalRejected != 1 {
+		t.Errorf("the status must be the stats of the breaker, got %+v", r.Status())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	service := &flaky{delay: 300 * time.Millisecond}
+	r := Wrap(service, Options{Timeout: 20 * time.Millisecond, Backoff: time.Millisecond, DegradedText: "degraded"})
+	c := newTestContext(r)
+	original := c.Context
+
+	start := time.Now()
+	got, err := ask(t, r, c)
+	if err != nil || got != "degraded" {
+		t.Fatalf("the timeout must answer the degraded output, got %q (%v)", got, err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("the call must be abandoned at the timeout, it took %s", elapsed)
+	}
+	if service.callsSoFar() != 1 {
+		t.Errorf("the timeouts of a service that isn't idempotent can't be retried, got %d calls", service.callsSoFar())
+	}
+	if r.Stats().LastError != ErrTimeout.Error() {
+		t.Errorf("expected the timeout as last error, got %q", r.Stats().LastError)
+	}
+	if c.Context != original {
+		t.Error("the Context.Context of the context must be restored")
+	}
+}
+
+func TestTimeoutOfAnIdempotentService(t *testing.T) {
+	service := &flaky{delay: 300 * time.Millisecond}
+	r := Wrap(service, Options{Timeout: 20 * time.Millisecond, MaxRetries: 2, Backoff: time.Millisecond, Idempotent: true})
+	c := newTestContext(r)
+
+	ask(t, r, c)
+	if service.callsSoFar() != 3 {
+		t.Errorf("the timeouts of an idempotent service must be retried, got %d calls", service.callsSoFar())
+	}
+}
+
+func TestTheDeadlineIsSetIntoTheContext(t *testing.T) {
+	var deadline time.Time
+	service := &watcher{onCall: func(c *neo.Context) { deadline, _ = (*c.Context).Deadline() }}
+	r := Wrap(service, Options{Timeout: time.Second})
+	c := newTestContext(r)
+
+	if _, err := r.GetProtoResponse(c, &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+	if deadline.IsZero() || time.Until(deadline) > time.Second {
+		t.Errorf("the deadline of the call must be set into the Context.Context, got %s", deadline)
+	}
+}
+
+// watcher inspects the context of the call
+type watcher struct {
+	flaky
+	onCall func(c *neo.Context)
+}
+
+func (w *watcher) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	w.onCall(c)
+	return w.flaky.GetProtoResponse(c, in)
+}
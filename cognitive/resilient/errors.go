@@ -0,0 +1,6 @@
+package resilient
+
+import "errors"
+
+var ErrTimeout = errors.New("cognitive service timeout")
+var ErrCircuitOpen = errors.New("the circuit breaker is open")
@@ -0,0 +1,235 @@
+// Package router is a composite cognitive service, every input is routed to one of its backends
+// (by matcher, locale or context variable) and falls back to the next backends when the
+// response is invalid or its confidence is low
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+// Backend is a named cognitive service of the router
+type Backend struct {
+	Name    string
+	Service neo.CognitiveService
+}
+
+type Options struct {
+	// Default backend when no route matches, by default the first backend
+	Default string
+	// Fallbacks are tried in order after the routed backend fails
+	Fallbacks []string
+	// MinConfidence of the best intent, under it the next fallback is tried, 0 disables it
+	MinConfidence float64
+	// RejectWithoutIntents tries the next fallback when the output doesn't have intents, by default these
+	// outputs are accepted because its backend doesn't classify the inputs (e.g. rasa without parse)
+	RejectWithoutIntents bool
+	// SessionTimeout is the max idle time of the backend sessions of a context, by default 30 minutes
+	SessionTimeout time.Duration
+}
+
+type Cognitive struct {
+	options              Options
+	backends             map[string]neo.CognitiveService
+	routes               []*route
+	mu                   sync.Mutex
+	sessions             map[*neo.Context]*session
+	owners               map[*neo.Context]*neo.Context
+	doneContextCallbacks []*func(c *neo.Context)
+}
+
+func NewCognitive(options Options, backends ...Backend) (*Cognitive, error) {
+	if len(backends) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	if options.Default == "" {
+		options.Default = backends[0].Name
+	}
+
+	if options.SessionTimeout == 0 {
+		options.SessionTimeout = 30 * time.Minute
+	}
+
+	router := &Cognitive{
+		options:  options,
+		backends: map[string]neo.CognitiveService{},
+		routes:   []*route{},
+		sessions: map[*neo.Context]*session{},
+		owners:   map[*neo.Context]*neo.Context{},
+	}
+
+	for _, b := range backends {
+		if _, exist := router.backends[b.Name]; exist {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatedBackend, b.Name)
+		}
+		router.backends[b.Name] = b.Service
+
+		name := b.Name
+		b.Service.OnContextIsDone(func(sub *neo.Context) {
+			router.onBackendDone(name, sub)
+		})
+	}
+
+	for _, name := range append([]string{options.Default}, options.Fallbacks...) {
+		if _, exist := router.backends[name]; !exist {
+			return nil, fmt.Errorf("%w: %s", ErrBackendNotExist, name)
+		}
+	}
+
+	return router, nil
+}
+
+func (router *Cognitive) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	router.pruneSessions(time.Now())
+
+	id := xid.New()
+	return &neo.Context{
+		Context:   c,
+		SessionID: id.String(),
+		Person:    info,
+		Variables: map[string]interface{}{},
+	}
+}
+
+// GetProtoResponse tries the routed backend and the fallbacks, the first confident response is returned,
+// if all of them are under MinConfidence the most confident one is returned
+func (router *Cognitive) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if c == nil {
+		return nil, neo.ErrContextNotExist
+	}
+
+	chain := router.chain(router.resolve(c, in))
+
+	var best *neo.Output
+	var bestName string
+	var lastErr error
+	logs := make([]*neo.LogMessage, 0)
+
+	for _, name := range chain {
+		out, err := router.ask(name, c, in)
+		if err != nil {
+			if !errors.Is(err, neo.ErrInvalidResponseFromCognitiveService) {
+				router.finish(c, name)
+				return nil, err
+			}
+			lastErr = err
+			logs = append(logs, &neo.LogMessage{Level: neo.Warn, Message: name + ": " + err.Error()})
+			continue
+		}
+
+		if best == nil || confidence(out) > confidence(best) {
+			best, bestName = out, name
+		}
+
+		if router.confident(out) {
+			best, bestName = out, name
+			break
+		}
+
+		message := fmt.Sprintf("%s: low confidence (%.2f)", name, confidence(out))
+		if len(out.Intents) == 0 {
+			message = name + ": without intents"
+		}
+		logs = append(logs, &neo.LogMessage{Level: neo.Warn, Message: message})
+	}
+
+	router.finish(c, bestName)
+
+	if best == nil {
+		return nil, lastErr
+	}
+
+	best.Logs = append(append(logs, &neo.LogMessage{Level: neo.Info, Message: "routed to " + bestName}), best.Logs...)
+
+	return best, nil
+}
+
+func (router *Cognitive) OnContextIsDone(callback func(c *neo.Context)) {
+	if router.doneContextCallbacks == nil {
+		router.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	router.doneContextCallbacks = append(router.doneContextCallbacks, &callback)
+}
+
+// CallContextDone drops the backend contexts of the context closed by the engine, the backends
+// that implement neo.ContextDoneCaller are notified with its own context
+func (router *Cognitive) CallContextDone(c *neo.Context) {
+	router.mu.Lock()
+	subs := map[string]*neo.Context{}
+	if s, exist := router.sessions[c]; exist {
+		for name, sub := range s.contexts {
+			subs[name] = sub
+		}
+	}
+	router.forget(c)
+	router.mu.Unlock()
+
+	for name, sub := range subs {
+		if caller, ok := router.backends[name].(neo.ContextDoneCaller); ok {
+			caller.CallContextDone(sub)
+		}
+	}
+}
+
+// Status reports the status of the backends that implement neo.StatusReporter, by backend name
+func (router *Cognitive) Status() interface{} {
+	status := map[string]interface{}{}
+	for name, backend := range router.backends {
+		if reporter, ok := backend.(neo.StatusReporter); ok {
+			status[name] = reporter.Status()
+		}
+	}
+	return status
+}
+
+// SetLogger passes the logger of the engine to the backends that implement neo.LoggerSetter
+func (router *Cognitive) SetLogger(logger neo.Logger) {
+	for name, backend := range router.backends {
+		if setter, ok := backend.(neo.LoggerSetter); ok {
+			setter.SetLogger(neo.AddFields(logger, neo.Fields{"backend": name}))
+		}
+	}
+}
+
+// chain returns the routed backend followed by the fallbacks, without repetitions
+func (router *Cognitive) chain(first string) []string {
+	chain := []string{first}
+	seen := map[string]bool{first: true}
+	for _, name := range append([]string{router.options.Default}, router.options.Fallbacks...) {
+		if !seen[name] {
+			chain = append(chain, name)
+			seen[name] = true
+		}
+	}
+	return chain
+}
+
+// confident tells if the output is accepted, the outputs without intents weren't classified
+// so they are accepted unless RejectWithoutIntents
+func (router *Cognitive) confident(out *neo.Output) bool {
+	if router.options.MinConfidence <= 0 {
+		return true
+	}
+	if len(out.Intents) == 0 {
+		return !router.options.RejectWithoutIntents
+	}
+	return confidence(out) >= router.options.MinConfidence
+}
+
+// confidence is the confidence of the best intent of the output
+func confidence(out *neo.Output) float64 {
+	best := 0.0
+	for _, i := range out.Intents {
+		if i.Confidence > best {
+			best = i.Confidence
+		}
+	}
+	return best
+}
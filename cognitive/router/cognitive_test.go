@@ -0,0 +1,276 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// backend answers its name with a fixed confidence, it records the contexts it creates and closes
+type backend struct {
+	name       string
+	confidence float64
+	err        error
+	endOn      string
+
+	mu     sync.Mutex
+	closed []*neo.Context
+	done   []*func(c *neo.Context)
+}
+
+func (b *backend) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	return &neo.Context{Context: c, SessionID: b.name + "-" + info.ID, Person: info, Variables: map[string]interface{}{}}
+}
+
+func (b *backend) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	out := &neo.Output{Responses: []neo.Response{{Type: neo.Text, Value: b.name}}}
+	if b.confidence > 0 {
+		out.Intents = []neo.Intent{{Intent: "any", Confidence: b.confidence}}
+	}
+
+	if in.Data.Value == b.endOn {
+		for _, call := range b.done {
+			(*call)(c)
+		}
+	}
+	return out, nil
+}
+
+func (b *backend) OnContextIsDone(callback func(c *neo.Context)) {
+	b.done = append(b.done, &callback)
+}
+
+func (b *backend) CallContextDone(c *neo.Context) {
+	b.mu.Lock()
+	b.closed = append(b.closed, c)
+	b.mu.Unlock()
+}
+
+func newContext(router *Cognitive, id string) *neo.Context {
+	ctx := context.Background()
+	return router.CreateNewContext(&ctx, neo.PersonInfo{ID: id, Locale: "es_PE"})
+}
+
+func ask(t *testing.T, router *Cognitive, c *neo.Context, text string) string {
+	t.Helper()
+
+	out, err := router.GetProtoResponse(c, &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: text}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out.Responses[0].Value.(string)
+}
+
+func TestRoutes(t *testing.T) {
+	router, err := NewCognitive(Options{},
+		Backend{Name: "main", Service: &backend{name: "main"}},
+		Backend{Name: "spanish", Service: &backend{name: "spanish"}},
+		Backend{Name: "billing", Service: &backend{name: "billing"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = router.RouteByMatcher(neo.IfTextContains("invoice"), "billing"); err != nil {
+		t.Fatal(err)
+	}
+	if err = router.RouteByVariable("department", "billing", "billing"); err != nil {
+		t.Fatal(err)
+	}
+	if err = router.RouteByLocale("es", "spanish"); err != nil {
+		t.Fatal(err)
+	}
+	if err = router.RouteByLocale("fr", "unknown"); !errors.Is(err, ErrBackendNotExist) {
+		t.Errorf("expected ErrBackendNotExist, got %v", err)
+	}
+
+	c := newContext(router, "42")
+	if got := ask(t, router, c, "my invoice"); got != "billing" {
+		t.Errorf("the first route that matches must be used, got %s", got)
+	}
+	if got := ask(t, router, c, "hola"); got != "spanish" {
+		t.Errorf("expected the route by locale, got %s", got)
+	}
+
+	c.Variables["department"] = "billing"
+	if got := ask(t, router, c, "hola"); got != "billing" {
+		t.Errorf("expected the route by variable, got %s", got)
+	}
+
+	other := newContext(router, "43")
+	other.Person.Locale = "en"
+	if got := ask(t, router, other, "hello"); got != "main" {
+		t.Errorf("expected the default backend, got %s", got)
+	}
+
+	if ids := router.Sessions(c); ids["billing"] != "billing-42" || ids["spanish"] != "spanish-42" || len(ids) != 2 {
+		t.Errorf("every backend must have its own context, got %v", ids)
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	cases := []struct {
+		name    string
+		options Options
+		first   *backend
+		want    string
+	}{
+		{"confident", Options{MinConfidence: 0.5}, &backend{name: "first", confidence: 0.9}, "first"},
+		{"low confidence", Options{MinConfidence: 0.5}, &backend{name: "first", confidence: 0.2}, "second"},
+		{"invalid response", Options{}, &backend{name: "first", err: neo.ErrInvalidResponseFromCognitiveService}, "second"},
+		{"without intents", Options{MinConfidence: 0.5}, &backend{name: "first"}, "first"},
+		{"rejected without intents", Options{MinConfidence: 0.5, RejectWithoutIntents: true}, &backend{name: "first"}, "second"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.Fallbacks = []string{"second"}
+			router, err := NewCognitive(tc.options,
+				Backend{Name: "first", Service: tc.first},
+				Backend{Name: "second", Service: &backend{name: "second", confidence: 0.8}},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := ask(t, router, newContext(router, "42"), "hi"); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTheMostConfidentWhenAllAreLow(t *testing.T) {
+	router, err := NewCognitive(Options{MinConfidence: 0.9, Fallbacks: []string{"second", "third"}},
+		Backend{Name: "first", Service: &backend{name: "first", confidence: 0.3}},
+		Backend{Name: "second", Service: &backend{name: "second", confidence: 0.6}},
+		Backend{Name: "third", Service: &backend{name: "third", confidence: 0.4}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ask(t, router, newContext(router, "42"), "hi"); got != "second" {
+		t.Errorf("expected the most confident output, got %s", got)
+	}
+}
+
+func TestErrorsThatAreNotInvalidResponses(t *testing.T) {
+	failure := errors.New("boom")
+	router, err := NewCognitive(Options{Fallbacks: []string{"second"}},
+		Backend{Name: "first", Service: &backend{name: "first", err: failure}},
+		Backend{Name: "second", Service: &backend{name: "second"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = router.GetProtoResponse(newContext(router, "42"), &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: "hi"}})
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the error of the backend, got %v", err)
+	}
+}
+
+func TestBackendEndsTheContext(t *testing.T) {
+	main := &backend{name: "main", endOn: "bye"}
+	other := &backend{name: "other", endOn: "bye"}
+	router, err := NewCognitive(Options{},
+		Backend{Name: "main", Service: main},
+		Backend{Name: "other", Service: other},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = router.RouteByMatcher(neo.IfTextContains("other"), "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	var done []*neo.Context
+	router.OnContextIsDone(func(c *neo.Context) { done = append(done, c) })
+
+	c := newContext(router, "42")
+	ask(t, router, c, "hi")
+	ask(t, router, c, "bye")
+	if len(done) != 1 || done[0] != c {
+		t.Fatalf("the context must be done when the backend of the response ends its context, got %v", done)
+	}
+	if len(router.Sessions(c)) != 0 {
+		t.Error("the backend contexts of a done context must be dropped")
+	}
+}
+
+func TestCallContextDone(t *testing.T) {
+	main := &backend{name: "main"}
+	router, err := NewCognitive(Options{}, Backend{Name: "main", Service: main})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contexts := make([]*neo.Context, 0)
+	for i := 0; i < 10; i++ {
+		c := newContext(router, fmt.Sprint(i))
+		ask(t, router, c, "hi")
+		contexts = append(contexts, c)
+	}
+
+	for _, c := range contexts {
+		router.CallContextDone(c)
+	}
+
+	router.mu.Lock()
+	sessions, owners := len(router.sessions), len(router.owners)
+	router.mu.Unlock()
+	if sessions != 0 || owners != 0 {
+		t.Errorf("the closed contexts must be dropped, got %d sessions and %d owners", sessions, owners)
+	}
+
+	main.mu.Lock()
+	defer main.mu.Unlock()
+	if len(main.closed) != 10 || main.closed[0].SessionID != "main-0" {
+		t.Errorf("the backends must be notified with its own contexts, got %d", len(main.closed))
+	}
+}
+
+func TestPruneSessions(t *testing.T) {
+	router, err := NewCognitive(Options{SessionTimeout: time.Minute}, Backend{Name: "main", Service: &backend{name: "main"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newContext(router, "42")
+	ask(t, router, c, "hi")
+
+	router.pruneSessions(time.Now())
+	if len(router.Sessions(c)) != 1 {
+		t.Fatal("a session in use can't be pruned")
+	}
+
+	router.pruneSessions(time.Now().Add(2 * time.Minute))
+	if len(router.Sessions(c)) != 0 {
+		t.Error("an idle session must be pruned")
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	if _, err := NewCognitive(Options{}); !errors.Is(err, ErrNoBackends) {
+		t.Errorf("expected ErrNoBackends, got %v", err)
+	}
+
+	b := Backend{Name: "main", Service: &backend{name: "main"}}
+	if _, err := NewCognitive(Options{}, b, b); !errors.Is(err, ErrDuplicatedBackend) {
+		t.Errorf("expected ErrDuplicatedBackend, got %v", err)
+	}
+	if _, err := NewCognitive(Options{Fallbacks: []string{"missing"}}, b); !errors.Is(err, ErrBackendNotExist) {
+		t.Errorf("expected ErrBackendNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+package router
+
+import "errors"
+
+var ErrNoBackends = errors.New("the router needs at least one backend")
+var ErrBackendNotExist = errors.New("backend not exist on this router")
+var ErrDuplicatedBackend = errors.New("duplicated backend name")
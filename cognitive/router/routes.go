@@ -0,0 +1,71 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+type route struct {
+	backend string
+	matcher *neo.Matcher
+	locale  string
+}
+
+func (r *route) matches(c *neo.Context, in *neo.Input) bool {
+	if r.locale != "" {
+		return localeMatches(r.locale, c.Person.Locale)
+	}
+	return r.matcher.Matches(c, in, nil)
+}
+
+func (router *Cognitive) addRoute(r *route) error {
+	if _, exist := router.backends[r.backend]; !exist {
+		return fmt.Errorf("%w: %s", ErrBackendNotExist, r.backend)
+	}
+
+	router.mu.Lock()
+	router.routes = append(router.routes, r)
+	router.mu.Unlock()
+	return nil
+}
+
+// RouteByMatcher sends the inputs that match to the backend, the matcher is evaluated
+// with the context and the input (there is not output yet), the routes are evaluated in order
+func (router *Cognitive) RouteByMatcher(matcher *neo.Matcher, backend string) error {
+	return router.addRoute(&route{backend: backend, matcher: matcher})
+}
+
+// RouteByLocale sends the inputs of the persons with the locale (e.g. es matches es_PE and es-PE) to the backend
+func (router *Cognitive) RouteByLocale(locale string, backend string) error {
+	return router.addRoute(&route{backend: backend, locale: locale})
+}
+
+// RouteByVariable sends the inputs to the backend when the context variable is equal to the value
+func (router *Cognitive) RouteByVariable(name string, value interface{}, backend string) error {
+	return router.addRoute(&route{backend: backend, matcher: neo.IfContextVariable(name, neo.Equal, value)})
+}
+
+// resolve returns the backend of the first route that matches
+func (router *Cognitive) resolve(c *neo.Context, in *neo.Input) string {
+	router.mu.Lock()
+	routes := append([]*route{}, router.routes...)
+	router.mu.Unlock()
+
+	for _, r := range routes {
+		if r.matches(c, in) {
+			return r.backend
+		}
+	}
+	return router.options.Default
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(locale, "_", "-", -1))
+}
+
+func localeMatches(route, locale string) bool {
+	route, locale = normalizeLocale(route), normalizeLocale(locale)
+	return locale == route || strings.HasPrefix(locale, route+"-")
+}
@@ -0,0 +1,146 @@
+package router
+
+import (
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// session keeps the contexts of every backend for one neocortex context
+type session struct {
+	contexts map[string]*neo.Context
+	done     map[string]bool
+	lastUse  time.Time
+}
+
+// backendContext returns the context of the backend, it is created the first time
+func (router *Cognitive) backendContext(name string, c *neo.Context) *neo.Context {
+	router.mu.Lock()
+	s, exist := router.sessions[c]
+	if !exist {
+		s = &session{contexts: map[string]*neo.Context{}, done: map[string]bool{}}
+		router.sessions[c] = s
+	}
+	s.lastUse = time.Now()
+	sub, exist := s.contexts[name]
+	router.mu.Unlock()
+
+	if exist {
+		return sub
+	}
+
+	// the backend can take its time (e.g. watson creates the session remotely)
+	sub = router.backends[name].CreateNewContext(c.Context, c.Person)
+
+	router.mu.Lock()
+	s.contexts[name] = sub
+	router.owners[sub] = c
+	router.mu.Unlock()
+
+	return sub
+}
+
+// ask calls the backend with its own context, the variables are shared with the neocortex context
+func (router *Cognitive) ask(name string, c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	sub := router.backendContext(name, c)
+
+	if c.Variables == nil {
+		c.Variables = map[string]interface{}{}
+	}
+
+	sub.Context = c.Context
+	sub.Person = c.Person
+	sub.Variables = c.Variables
+
+	out, err := router.backends[name].GetProtoResponse(sub, in)
+
+	if sub.Variables != nil {
+		c.Variables = sub.Variables
+	}
+
+	return out, err
+}
+
+// onBackendDone forgets the context of the backend, a new one is created the next time,
+// the neocortex context is closed only if the backend gave the response (see finish)
+func (router *Cognitive) onBackendDone(name string, sub *neo.Context) {
+	router.mu.Lock()
+	c, exist := router.owners[sub]
+	if !exist {
+		router.mu.Unlock()
+		return
+	}
+	delete(router.owners, sub)
+
+	if s, ok := router.sessions[c]; ok {
+		if s.contexts[name] == sub {
+			delete(s.contexts, name)
+		}
+		s.done[name] = true
+	}
+	router.mu.Unlock()
+}
+
+// finish closes the neocortex context if the backend of the response closed its own context
+func (router *Cognitive) finish(c *neo.Context, name string) {
+	router.mu.Lock()
+	s, exist := router.sessions[c]
+	closed := false
+	if exist {
+		closed = s.done[name]
+		s.done = map[string]bool{}
+		if closed {
+			router.forget(c)
+		}
+	}
+	router.mu.Unlock()
+
+	if closed {
+		for _, call := range router.doneContextCallbacks {
+			(*call)(c)
+		}
+	}
+}
+
+// Sessions returns the session id of every backend used by the context
+func (router *Cognitive) Sessions(c *neo.Context) map[string]string {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	ids := map[string]string{}
+	if s, exist := router.sessions[c]; exist {
+		for name, sub := range s.contexts {
+			ids[name] = sub.SessionID
+		}
+	}
+	return ids
+}
+
+// Forget drops the backend contexts of a neocortex context
+func (router *Cognitive) Forget(c *neo.Context) {
+	router.mu.Lock()
+	router.forget(c)
+	router.mu.Unlock()
+}
+
+func (router *Cognitive) forget(c *neo.Context) {
+	if s, exist := router.sessions[c]; exist {
+		for _, sub := range s.contexts {
+			delete(router.owners, sub)
+		}
+		delete(router.sessions, c)
+	}
+}
+
+// pruneSessions drops the sessions without use since SessionTimeout, the contexts closed by the
+// engine are dropped by CallContextDone but the router can be used without engine
+func (router *Cognitive) pruneSessions(now time.Time) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+
+	for c, s := range router.sessions {
+		if now.Sub(s.lastUse) > router.options.SessionTimeout {
+			router.forget(c)
+		}
+	}
+}
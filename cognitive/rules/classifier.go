@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"regexp"
+	"sort"
+)
+
+const (
+	patternScore = 1.0
+	keywordScore = 0.9
+	fuzzyScore   = 0.7
+)
+
+type compiledIntent struct {
+	spec      IntentSpec
+	keywords  [][]string
+	patterns  []*regexp.Regexp
+	responses []*compiledResponse
+}
+
+// score is the confidence of one intent and the reason of it
+type score struct {
+	intent     int
+	confidence float64
+	reason     string
+}
+
+// rulesScores evaluates the patterns, keywords and fuzzy keywords of every intent
+func (rules *Cognitive) rulesScores(text string, tokens []token) []score {
+	scores := make([]score, len(rules.intents))
+	for i, intent := range rules.intents {
+		scores[i].intent = i
+
+		for _, p := range intent.patterns {
+			if p.MatchString(text) {
+				scores[i].confidence = patternScore
+				scores[i].reason = "pattern: " + p.String()
+				break
+			}
+		}
+		if scores[i].confidence >= keywordScore {
+			continue
+		}
+
+		for j, k := range intent.keywords {
+			if findPhrase(tokens, k, 0) >= 0 {
+				scores[i].confidence = keywordScore
+				scores[i].reason = "keyword: " + intent.spec.Keywords[j]
+				break
+			}
+		}
+		if scores[i].confidence >= fuzzyScore || !rules.fuzzy {
+			continue
+		}
+
+		for j, k := range intent.keywords {
+			if len(k) != 1 || maxTypos(k[0]) == 0 {
+				continue
+			}
+			for _, t := range tokens {
+				if levenshtein(t.word, k[0]) <= maxTypos(k[0]) {
+					scores[i].confidence = fuzzyScore
+					scores[i].reason = "fuzzy keyword: " + intent.spec.Keywords[j]
+					break
+				}
+			}
+			if scores[i].confidence > 0 {
+				break
+			}
+		}
+	}
+	return scores
+}
+
+// classify returns the intents with some confidence, sorted from the best one
+func (rules *Cognitive) classify(text string, tokens []token) []score {
+	scores := make([]score, len(rules.intents))
+	for i := range scores {
+		scores[i].intent = i
+	}
+
+	if rules.classifier != "tfidf" {
+		scores = rules.rulesScores(text, tokens)
+	}
+
+	if rules.classifier != "rules" {
+		doc := make([]string, len(tokens))
+		for i, t := range tokens {
+			doc[i] = t.word
+		}
+		for i, sim := range rules.model.scores(doc, len(rules.intents)) {
+			if sim > scores[i].confidence {
+				scores[i].confidence = sim
+				scores[i].reason = "tfidf"
+			}
+		}
+	}
+
+	ranked := make([]score, 0)
+	for _, s := range scores {
+		if s.confidence > 0 {
+			ranked = append(ranked, s)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].confidence > ranked[j].confidence
+	})
+
+	return ranked
+}
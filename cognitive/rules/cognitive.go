@@ -0,0 +1,242 @@
+// Package rules is an offline cognitive service, the intents, entities and responses are
+// loaded from a skill file and classified with keywords, patterns, fuzzy keywords or tf-idf
+package rules
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+type Cognitive struct {
+	name                 string
+	classifier           string
+	threshold            float64
+	fuzzy                bool
+	intents              []*compiledIntent
+	entities             []*compiledEntity
+	fallback             []*compiledResponse
+	model                *tfidf
+	doneContextCallbacks []*func(c *neo.Context)
+	logger               neo.Logger
+}
+
+// NewCognitiveFromFile loads the skill file (yaml or json) and creates the cognitive service
+func NewCognitiveFromFile(path string) (*Cognitive, error) {
+	skill, err := LoadSkill(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCognitive(skill)
+}
+
+// NewCognitive validates and compiles the skill, the skill of the caller is not modified
+func NewCognitive(skill *Skill) (*Cognitive, error) {
+	if skill == nil || len(skill.Intents) == 0 {
+		return nil, ErrEmptySkill
+	}
+
+	// the defaults are filled into a copy, the same skill can create many cognitive services
+	copied := *skill
+	skill = &copied
+
+	if skill.Classifier == "" {
+		skill.Classifier = "hybrid"
+	}
+
+	if skill.Classifier != "rules" && skill.Classifier != "tfidf" && skill.Classifier != "hybrid" {
+		return nil, ErrInvalidClassifier
+	}
+
+	if skill.Threshold <= 0 {
+		skill.Threshold = 0.3
+	}
+
+	rules := &Cognitive{
+		name:       skill.Name,
+		classifier: skill.Classifier,
+		threshold:  skill.Threshold,
+		fuzzy:      skill.Fuzzy == nil || *skill.Fuzzy,
+		intents:    make([]*compiledIntent, 0, len(skill.Intents)),
+		entities:   make([]*compiledEntity, 0, len(skill.Entities)),
+		model:      newTFIDF(skill.Intents),
+		logger:     neo.DefaultLogger(),
+	}
+
+	names := map[string]bool{}
+	for _, spec := range skill.Intents {
+		if names[spec.Name] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatedIntent, spec.Name)
+		}
+		names[spec.Name] = true
+
+		intent, err := compileIntent(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules.intents = append(rules.intents, intent)
+	}
+
+	for _, spec := range skill.Entities {
+		entity, err := compileEntity(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules.entities = append(rules.entities, entity)
+	}
+
+	if skill.Fallback != nil {
+		fallback, err := compileResponses(skill.Fallback.Responses)
+		if err != nil {
+			return nil, err
+		}
+		rules.fallback = fallback
+	}
+
+	return rules, nil
+}
+
+func compileIntent(spec IntentSpec) (*compiledIntent, error) {
+	intent := &compiledIntent{spec: spec}
+
+	for _, k := range spec.Keywords {
+		intent.keywords = append(intent.keywords, words(k))
+	}
+
+	for _, p := range spec.Patterns {
+		r, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("intent %s: %w", spec.Name, err)
+		}
+		intent.patterns = append(intent.patterns, r)
+	}
+
+	responses, err := compileResponses(spec.Responses)
+	if err != nil {
+		return nil, fmt.Errorf("intent %s: %w", spec.Name, err)
+	}
+	intent.responses = responses
+
+	return intent, nil
+}
+
+func compileEntity(spec EntitySpec) (*compiledEntity, error) {
+	entity := &compiledEntity{name: spec.Name}
+
+	for _, v := range spec.Values {
+		value := compiledValue{value: v.Value, phrases: [][]string{}}
+		for _, s := range append([]string{v.Value}, v.Synonyms...) {
+			if phrase := words(s); len(phrase) > 0 {
+				value.phrases = append(value.phrases, phrase)
+			}
+		}
+		entity.values = append(entity.values, value)
+	}
+
+	for _, p := range spec.Patterns {
+		r, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %w", spec.Name, err)
+		}
+		entity.patterns = append(entity.patterns, r)
+	}
+
+	return entity, nil
+}
+
+func compileResponses(specs []ResponseSpec) ([]*compiledResponse, error) {
+	responses := make([]*compiledResponse, 0, len(specs))
+	for _, spec := range specs {
+		filled := 0
+		for _, f := range []bool{spec.Text != "", spec.Image != "", spec.Pause != "", spec.Options != nil} {
+			if f {
+				filled++
+			}
+		}
+		if filled != 1 {
+			return nil, ErrInvalidResponse
+		}
+
+		res := &compiledResponse{spec: spec}
+
+		if spec.Text != "" {
+			t, err := template.New("text").Option("missingkey=zero").Parse(spec.Text)
+			if err != nil {
+				return nil, err
+			}
+			res.text = t
+		}
+
+		if spec.Pause != "" {
+			d, err := time.ParseDuration(spec.Pause)
+			if err != nil {
+				return nil, err
+			}
+			res.pause = d
+		}
+
+		responses = append(responses, res)
+	}
+	return responses, nil
+}
+
+func (rules *Cognitive) CreateNewContext(c *context.Context, info neo.PersonInfo) *neo.Context {
+	id := xid.New()
+	return &neo.Context{
+		Context:   c,
+		SessionID: id.String(),
+		Person:    info,
+		Variables: map[string]interface{}{},
+	}
+}
+
+func (rules *Cognitive) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if c == nil {
+		return nil, neo.ErrContextNotExist
+	}
+
+	switch in.Data.Type {
+	case neo.InputText, neo.InputEmoji:
+	default:
+		return nil, neo.ErrInvalidInputType
+	}
+
+	text := strings.TrimSpace(in.Data.Value)
+	tokens := tokenize(text)
+
+	// the locations of the entities are into the value of the input, the trimmed spaces are added back
+	shift := utf8.RuneCountInString(in.Data.Value) - utf8.RuneCountInString(strings.TrimLeftFunc(in.Data.Value, unicode.IsSpace))
+
+	ranked := rules.classify(text, tokens)
+	entities := rules.extractEntities(text, shift, tokens)
+
+	out, end := rules.NewOutput(c, ranked, entities)
+
+	if end {
+		for _, call := range rules.doneContextCallbacks {
+			(*call)(c)
+		}
+	}
+
+	return out, nil
+}
+
+// SetLogger is called by the engine with its logger, the default logger is used until then
+func (rules *Cognitive) SetLogger(logger neo.Logger) {
+	rules.logger = logger
+}
+
+func (rules *Cognitive) OnContextIsDone(callback func(c *neo.Context)) {
+	if rules.doneContextCallbacks == nil {
+		rules.doneContextCallbacks = []*func(c *neo.Context){}
+	}
+	rules.doneContextCallbacks = append(rules.doneContextCallbacks, &callback)
+}
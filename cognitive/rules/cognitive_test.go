@@ -0,0 +1,305 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func testSkill() *Skill {
+	return &Skill{
+		Name: "shop",
+		Intents: []IntentSpec{
+			{
+				Name:      "greet",
+				Keywords:  []string{"hello", "good morning"},
+				Examples:  []string{"hi there", "hey how are you"},
+				Responses: []ResponseSpec{{Text: "hi {{.Person.Name}}"}},
+			},
+			{
+				Name:      "order",
+				Patterns:  []string{`\bi want (a|an|some)\b`},
+				Keywords:  []string{"delivery"},
+				Examples:  []string{"can you send me a pizza", "bring me food"},
+				Responses: []ResponseSpec{{Text: "one {{.Entities.food}} for {{.Variables.address}}"}, {Pause: "1s"}},
+				Set:       map[string]interface{}{"ordering": true},
+			},
+			{
+				Name:      "bye",
+				Keywords:  []string{"goodbye"},
+				Responses: []ResponseSpec{{Options: &OptionsSpec{Title: "rate us", Options: []OptionSpec{{Text: "good"}, {Text: "web", Action: "https://example.com"}}}}},
+				End:       true,
+			},
+		},
+		Entities: []EntitySpec{
+			{Name: "food", Values: []ValueSpec{{Value: "pizza", Synonyms: []string{"pizzas", "pepperoni pizza"}}, {Value: "burger"}}},
+			{Name: "phone", Patterns: []string{`phone (\d{3}-\d{4})`}},
+		},
+		Fallback: &FallbackSpec{Responses: []ResponseSpec{{Text: "sorry?"}}},
+	}
+}
+
+func newTestContext(rules *Cognitive) *neo.Context {
+	ctx := context.Background()
+	c := rules.CreateNewContext(&ctx, neo.PersonInfo{ID: "42", Name: "Ann"})
+	c.Variables["address"] = "Main St"
+	return c
+}
+
+func respond(t *testing.T, rules *Cognitive, c *neo.Context, text string) *neo.Output {
+	t.Helper()
+
+	out, err := rules.GetProtoResponse(c, &neo.Input{Data: neo.InputData{Type: neo.InputText, Value: text}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func bestIntent(out *neo.Output) string {
+	if len(out.Intents) == 0 {
+		return ""
+	}
+	return out.Intents[0].Intent
+}
+
+func TestClassifiers(t *testing.T) {
+	cases := []struct {
+		classifier string
+		text       string
+		intent     string
+		confidence float64
+	}{
+		{"rules", "I want a pizza", "order", patternScore},
+		{"rules", "Hello!", "greet", keywordScore},
+		{"rules", "GOOD MORNING friends", "greet", keywordScore},
+		{"rules", "is there delivry?", "order", fuzzyScore},
+		{"rules", "hey how are you", "", 0},
+		{"tfidf", "I want a pizza", "order", -1},
+		{"tfidf", "hey there", "greet", -1},
+		{"tfidf", "goodbye", "", 0},
+		{"hybrid", "hello", "greet", keywordScore},
+		{"hybrid", "hey how are you", "greet", -1},
+		{"hybrid", "the weather is nice", "", 0},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.classifier+"/"+tc.text, func(t *testing.T) {
+			skill := testSkill()
+			skill.Classifier = tc.classifier
+			rules, err := NewCognitive(skill)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out := respond(t, rules, newTestContext(rules), tc.text)
+			if got := bestIntent(out); got != tc.intent {
+				t.Fatalf("expected the intent %q, got %q (%+v)", tc.intent, got, out.Intents)
+			}
+			if tc.confidence >= 0 && tc.intent != "" && out.Intents[0].Confidence != tc.confidence {
+				t.Errorf("expected the confidence %v, got %v", tc.confidence, out.Intents[0].Confidence)
+			}
+		})
+	}
+}
+
+func TestFuzzyCanBeDisabled(t *testing.T) {
+	skill := testSkill()
+	skill.Classifier = "rules"
+	fuzzy := false
+	skill.Fuzzy = &fuzzy
+
+	rules, err := NewCognitive(skill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bestIntent(respond(t, rules, newTestContext(rules), "is there delivry?")); got != "" {
+		t.Errorf("the typos can't be matched without fuzzy, got %q", got)
+	}
+}
+
+func TestThresholdAndFallback(t *testing.T) {
+	skill := testSkill()
+	skill.Classifier = "rules"
+	skill.Threshold = 0.8
+
+	rules, err := NewCognitive(skill)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := respond(t, rules, newTestContext(rules), "is there delivry?")
+	if len(out.Intents) != 0 {
+		t.Errorf("the intents under the threshold must be discarded, got %+v", out.Intents)
+	}
+	if len(out.Responses) != 1 || out.Responses[0].Value != "sorry?" {
+		t.Errorf("the fallback must be answered, got %+v", out.Responses)
+	}
+}
+
+func TestResponsesAndEntities(t *testing.T) {
+	rules, err := NewCognitive(testSkill())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestContext(rules)
+
+	out := respond(t, rules, c, "I want a Pepperoni Pizza and a burger, my phone 555-1234")
+
+	if len(out.Responses) != 2 || out.Responses[0].Value != "one pizza for Main St" || out.Responses[1].Value != time.Second {
+		t.Errorf("unexpected responses %+v", out.Responses)
+	}
+	if c.Variables["ordering"] != true {
+		t.Errorf("the intent must set its variables, got %+v", c.Variables)
+	}
+	if len(out.VisitedNodes) != 1 || out.VisitedNodes[0].Name != "order" || !strings.HasPrefix(out.VisitedNodes[0].Conditions, "pattern:") {
+		t.Errorf("the node must tell the reason of the intent, got %+v", out.VisitedNodes)
+	}
+
+	want := []struct{ entity, value, literal string }{
+		{"food", "pizza", "Pepperoni Pizza"},
+		{"food", "burger", "burger"},
+		{"phone", "555-1234", "phone 555-1234"},
+	}
+	if len(out.Entities) != len(want) {
+		t.Fatalf("expected %d entities, got %+v", len(want), out.Entities)
+	}
+	text := "I want a Pepperoni Pizza and a burger, my phone 555-1234"
+	for i, w := range want {
+		e := out.Entities[i]
+		if e.Entity != w.entity || e.Value != w.value || e.Metadata["literal"] != w.literal || string([]rune(text)[e.Location[0]:e.Location[1]]) != w.literal {
+			t.Errorf("entity %d: expected %+v, got %+v", i, w, e)
+		}
+	}
+}
+
+func TestEntityLocationsAreCharactersOfTheInput(t *testing.T) {
+	rules, err := NewCognitive(testSkill())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "  \t¿qué tal? una pizza, phone 555-1234  "
+	out := respond(t, rules, newTestContext(rules), text)
+
+	want := []string{"pizza", "phone 555-1234"}
+	if len(out.Entities) != len(want) {
+		t.Fatalf("expected %d entities, got %+v", len(want), out.Entities)
+	}
+	for i, literal := range want {
+		e := out.Entities[i]
+		if got := string([]rune(text)[e.Location[0]:e.Location[1]]); got != literal {
+			t.Errorf("entity %d: the location %v points to %q", i, e.Location, got)
+		}
+	}
+}
+
+func TestRenderErrorsAreLogged(t *testing.T) {
+	skill := testSkill()
+	skill.Fallback = &FallbackSpec{Responses: []ResponseSpec{{Text: "sorry {{.Person.Name.First}}"}}}
+	rules, err := NewCognitive(skill)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logged := 0
+	rules.SetLogger(loggerFunc(func(level neo.LogLevelType, message string, fields neo.Fields) {
+		if level == neo.Error && fields[neo.ErrorField] != nil {
+			logged++
+		}
+	}))
+
+	out := respond(t, rules, newTestContext(rules), "qwerty")
+	if logged != 1 || out.Responses[0].Value != "sorry {{.Person.Name.First}}" {
+		t.Errorf("the error must be logged with the logger of the engine and the raw text answered, logged %d", logged)
+	}
+}
+
+type loggerFunc func(level neo.LogLevelType, message string, fields neo.Fields)
+
+func (f loggerFunc) Log(level neo.LogLevelType, message string, fields neo.Fields) {
+	f(level, message, fields)
+}
+
+func TestEnd(t *testing.T) {
+	rules, err := NewCognitive(testSkill())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestContext(rules)
+
+	done := 0
+	rules.OnContextIsDone(func(ended *neo.Context) {
+		if ended == c {
+			done++
+		}
+	})
+
+	respond(t, rules, c, "hello")
+	if done != 0 {
+		t.Fatal("the context can't be done")
+	}
+
+	out := respond(t, rules, c, "goodbye")
+	if done != 1 {
+		t.Fatal("the end intent must finish the context")
+	}
+	options := out.Responses[0].Value.(neo.OptionsResponse)
+	if options.Options[0].Action != "good" || !options.Options[0].IsPostBack {
+		t.Errorf("the options are postbacks of its text by default, got %+v", options.Options[0])
+	}
+}
+
+func TestNewCognitiveDoesNotChangeTheSkill(t *testing.T) {
+	skill := testSkill()
+	if _, err := NewCognitive(skill); err != nil {
+		t.Fatal(err)
+	}
+	if skill.Classifier != "" || skill.Threshold != 0 {
+		t.Errorf("the defaults can't be written into the skill, got %q and %v", skill.Classifier, skill.Threshold)
+	}
+
+	skill.Classifier = "rules"
+	skill.Threshold = 0.95
+	if _, err := NewCognitive(skill); err != nil {
+		t.Fatal(err)
+	}
+	if skill.Classifier != "rules" || skill.Threshold != 0.95 {
+		t.Errorf("the skill must keep its values, got %q and %v", skill.Classifier, skill.Threshold)
+	}
+}
+
+func TestInvalidSkills(t *testing.T) {
+	if _, err := NewCognitive(&Skill{}); !errors.Is(err, ErrEmptySkill) {
+		t.Errorf("expected ErrEmptySkill, got %v", err)
+	}
+
+	skill := testSkill()
+	skill.Classifier = "magic"
+	if _, err := NewCognitive(skill); !errors.Is(err, ErrInvalidClassifier) {
+		t.Errorf("expected ErrInvalidClassifier, got %v", err)
+	}
+
+	skill = testSkill()
+	skill.Intents = append(skill.Intents, IntentSpec{Name: "greet"})
+	if _, err := NewCognitive(skill); !errors.Is(err, ErrDuplicatedIntent) {
+		t.Errorf("expected ErrDuplicatedIntent, got %v", err)
+	}
+
+	skill = testSkill()
+	skill.Intents[0].Responses = []ResponseSpec{{Text: "hi", Image: "https://example.com/hi.png"}}
+	if _, err := NewCognitive(skill); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("expected ErrInvalidResponse, got %v", err)
+	}
+
+	skill = testSkill()
+	skill.Intents[0].Patterns = []string{"("}
+	if _, err := NewCognitive(skill); err == nil {
+		t.Error("an invalid pattern must be rejected")
+	}
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+type compiledValue struct {
+	value   string
+	phrases [][]string
+}
+
+type compiledEntity struct {
+	name     string
+	values   []compiledValue
+	patterns []*regexp.Regexp
+}
+
+type phraseOf struct {
+	value  string
+	phrase []string
+}
+
+// location converts the byte offsets of the text into the character offsets of the entity,
+// shift is the number of characters before the text
+func location(text string, start, end, shift int) []int64 {
+	first := shift + utf8.RuneCountInString(text[:start])
+	return []int64{int64(first), int64(first + utf8.RuneCountInString(text[start:end]))}
+}
+
+// extractEntities finds the values and synonyms of every entity (the longest synonyms first,
+// without overlaps into the same entity) and the matches of its patterns
+func (rules *Cognitive) extractEntities(text string, shift int, tokens []token) []neo.Entity {
+	entities := make([]neo.Entity, 0)
+
+	for _, e := range rules.entities {
+		phrases := make([]phraseOf, 0)
+		for _, v := range e.values {
+			for _, p := range v.phrases {
+				phrases = append(phrases, phraseOf{value: v.value, phrase: p})
+			}
+		}
+		sort.SliceStable(phrases, func(i, j int) bool {
+			return len(phrases[i].phrase) > len(phrases[j].phrase)
+		})
+
+		used := make([]bool, len(tokens))
+		found := make([]neo.Entity, 0)
+		for _, p := range phrases {
+			for at := findPhrase(tokens, p.phrase, 0); at >= 0; at = findPhrase(tokens, p.phrase, at+1) {
+				overlaps := false
+				for k := at; k < at+len(p.phrase); k++ {
+					overlaps = overlaps || used[k]
+				}
+				if overlaps {
+					continue
+				}
+				for k := at; k < at+len(p.phrase); k++ {
+					used[k] = true
+				}
+
+				start, end := tokens[at].start, tokens[at+len(p.phrase)-1].end
+				found = append(found, neo.Entity{
+					Entity:     e.name,
+					Value:      p.value,
+					Location:   location(text, start, end, shift),
+					Confidence: 1.0,
+					Metadata:   map[string]interface{}{"literal": text[start:end]},
+				})
+			}
+		}
+
+		for _, p := range e.patterns {
+			for _, loc := range p.FindAllStringSubmatchIndex(text, -1) {
+				start, end := loc[0], loc[1]
+				// the first group is the value if the pattern has groups
+				value := text[start:end]
+				if len(loc) >= 4 && loc[2] >= 0 {
+					value = text[loc[2]:loc[3]]
+				}
+				found = append(found, neo.Entity{
+					Entity:     e.name,
+					Value:      value,
+					Location:   location(text, start, end, shift),
+					Confidence: 1.0,
+					Metadata:   map[string]interface{}{"literal": text[start:end]},
+				})
+			}
+		}
+
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].Location[0] < found[j].Location[0]
+		})
+		entities = append(entities, found...)
+	}
+
+	return entities
+}
@@ -0,0 +1,8 @@
+package rules
+
+import "errors"
+
+var ErrEmptySkill = errors.New("the skill doesn't have intents")
+var ErrInvalidClassifier = errors.New("invalid classifier, must be rules, tfidf or hybrid")
+var ErrDuplicatedIntent = errors.New("duplicated intent")
+var ErrInvalidResponse = errors.New("invalid response, only one of text, image, pause or options")
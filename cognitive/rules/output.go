@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+type compiledResponse struct {
+	spec  ResponseSpec
+	text  *template.Template
+	pause time.Duration
+}
+
+// templateData is available into the text responses, e.g. {{.Entities.size}} or {{.Person.Name}}
+type templateData struct {
+	Entities  map[string]string
+	Variables map[string]interface{}
+	Person    neo.PersonInfo
+}
+
+// NewOutput builds the output of the ranked intents, the responses are of the best intent
+// or of the fallback if there is not an intent over the threshold
+func (rules *Cognitive) NewOutput(c *neo.Context, ranked []score, entities []neo.Entity) (*neo.Output, bool) {
+	intents := make([]neo.Intent, 0)
+	for _, s := range ranked {
+		if s.confidence < rules.threshold {
+			continue
+		}
+		intents = append(intents, neo.Intent{
+			Intent:     rules.intents[s.intent].spec.Name,
+			Confidence: s.confidence,
+		})
+	}
+
+	if c.Variables == nil {
+		c.Variables = map[string]interface{}{}
+	}
+
+	logs := make([]*neo.LogMessage, 0)
+	nodes := make([]*neo.DialogNode, 0)
+	responses := rules.fallback
+	end := false
+
+	if len(intents) > 0 {
+		best := rules.intents[ranked[0].intent]
+		responses = best.responses
+		end = best.spec.End
+
+		for k, v := range best.spec.Set {
+			c.Variables[k] = v
+		}
+
+		nodes = append(nodes, &neo.DialogNode{
+			Name:       best.spec.Name,
+			Title:      rules.name,
+			Conditions: ranked[0].reason,
+		})
+	} else {
+		logs = append(logs, &neo.LogMessage{
+			Level:   neo.Warn,
+			Message: "there is not an intent over the threshold, using the fallback",
+		})
+	}
+
+	data := templateData{
+		Entities:  map[string]string{},
+		Variables: c.Variables,
+		Person:    c.Person,
+	}
+	for _, e := range entities {
+		if _, exist := data.Entities[e.Entity]; !exist {
+			data.Entities[e.Entity] = e.Value
+		}
+	}
+
+	res := make([]neo.Response, 0, len(responses))
+	for _, r := range responses {
+		response, err := r.render(data)
+		if err != nil {
+			rules.logger.Log(neo.Error, "error rendering the response", neo.Fields{neo.SessionIDField: c.SessionID, neo.ErrorField: err})
+			logs = append(logs, &neo.LogMessage{
+				Level:   neo.Error,
+				Message: "error rendering the response, using its raw text: " + err.Error(),
+			})
+		}
+		res = append(res, response)
+	}
+
+	return &neo.Output{
+		Logs:         logs,
+		VisitedNodes: nodes,
+		Intents:      intents,
+		Entities:     entities,
+		Responses:    res,
+	}, end
+}
+
+func (r *compiledResponse) render(data templateData) (neo.Response, error) {
+	switch {
+	case r.text != nil:
+		buf := new(bytes.Buffer)
+		if err := r.text.Execute(buf, data); err != nil {
+			return neo.Response{Type: neo.Text, Value: r.spec.Text, IsTyping: false}, err
+		}
+		return neo.Response{Type: neo.Text, Value: buf.String(), IsTyping: false}, nil
+	case r.spec.Image != "":
+		return neo.Response{Type: neo.Image, Value: r.spec.Image, IsTyping: false}, nil
+	case r.spec.Pause != "":
+		return neo.Response{Type: neo.Pause, Value: r.pause, IsTyping: true}, nil
+	default:
+		options := make([]*neo.Option, 0, len(r.spec.Options.Options))
+		for _, o := range r.spec.Options.Options {
+			action := o.Action
+			if action == "" {
+				action = o.Text
+			}
+			postBack := true
+			if o.IsPostBack != nil {
+				postBack = *o.IsPostBack
+			}
+			options = append(options, &neo.Option{Text: o.Text, Action: action, IsPostBack: postBack})
+		}
+		return neo.Response{
+			Type: neo.Options,
+			Value: neo.OptionsResponse{
+				Title:       r.spec.Options.Title,
+				Description: r.spec.Options.Description,
+				Image:       r.spec.Options.Image,
+				Options:     options,
+			},
+			IsTyping: false,
+		}, nil
+	}
+}
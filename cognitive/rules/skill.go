@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Skill describes the intents, entities and responses of an offline bot, it can be written in yaml or json
+type Skill struct {
+	Name string `yaml:"name" json:"name"`
+	// Classifier is rules (keywords, patterns and fuzzy keywords), tfidf (nearest example) or hybrid, by default hybrid
+	Classifier string `yaml:"classifier" json:"classifier"`
+	// Threshold is the minimum confidence of an intent, by default 0.3
+	Threshold float64 `yaml:"threshold" json:"threshold"`
+	// Fuzzy enables the fuzzy matching of keywords (typos), by default true
+	Fuzzy    *bool         `yaml:"fuzzy" json:"fuzzy"`
+	Intents  []IntentSpec  `yaml:"intents" json:"intents"`
+	Entities []EntitySpec  `yaml:"entities" json:"entities"`
+	Fallback *FallbackSpec `yaml:"fallback" json:"fallback"`
+}
+
+type IntentSpec struct {
+	Name string `yaml:"name" json:"name"`
+	// Keywords are words or phrases, if one of them appears into the text the intent is matched
+	Keywords []string `yaml:"keywords" json:"keywords"`
+	// Patterns are regular expressions evaluated over the text
+	Patterns []string `yaml:"patterns" json:"patterns"`
+	// Examples are used by the tfidf classifier
+	Examples  []string               `yaml:"examples" json:"examples"`
+	Responses []ResponseSpec         `yaml:"responses" json:"responses"`
+	Set       map[string]interface{} `yaml:"set" json:"set"`
+	// End closes the conversation after the responses
+	End bool `yaml:"end" json:"end"`
+}
+
+type EntitySpec struct {
+	Name     string      `yaml:"name" json:"name"`
+	Values   []ValueSpec `yaml:"values" json:"values"`
+	Patterns []string    `yaml:"patterns" json:"patterns"`
+}
+
+type ValueSpec struct {
+	Value    string   `yaml:"value" json:"value"`
+	Synonyms []string `yaml:"synonyms" json:"synonyms"`
+}
+
+type FallbackSpec struct {
+	Responses []ResponseSpec `yaml:"responses" json:"responses"`
+}
+
+// ResponseSpec is one response, only one of the fields must be filled. The texts are
+// go templates with .Entities, .Variables and .Person
+type ResponseSpec struct {
+	Text    string       `yaml:"text" json:"text"`
+	Image   string       `yaml:"image" json:"image"`
+	Pause   string       `yaml:"pause" json:"pause"`
+	Options *OptionsSpec `yaml:"options" json:"options"`
+}
+
+type OptionsSpec struct {
+	Title       string       `yaml:"title" json:"title"`
+	Description string       `yaml:"description" json:"description"`
+	Image       string       `yaml:"image" json:"image"`
+	Options     []OptionSpec `yaml:"options" json:"options"`
+}
+
+type OptionSpec struct {
+	Text       string `yaml:"text" json:"text"`
+	Action     string `yaml:"action" json:"action"`
+	IsPostBack *bool  `yaml:"is_post_back" json:"is_post_back"`
+}
+
+// LoadSkill reads a skill file, the format depends of the extension (.json, .yaml or .yml)
+func LoadSkill(path string) (*Skill, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return ParseSkillJSON(data)
+	}
+
+	return ParseSkillYAML(data)
+}
+
+func ParseSkillYAML(data []byte) (*Skill, error) {
+	skill := new(Skill)
+	if err := yaml.UnmarshalStrict(data, skill); err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
+
+func ParseSkillJSON(data []byte) (*Skill, error) {
+	skill := new(Skill)
+	if err := json.Unmarshal(data, skill); err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
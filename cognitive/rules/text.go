@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token is a word of the text, start and end are byte offsets into the original text
+type token struct {
+	word  string
+	start int
+	end   int
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+	"â", "a", "ê", "e", "î", "i", "ô", "o", "û", "u",
+	"ä", "a", "ë", "e", "ï", "i", "ö", "o", "ç", "c",
+)
+
+func normalize(word string) string {
+	return accents.Replace(strings.ToLower(word))
+}
+
+// tokenize splits the text in words (letters and digits), the words are normalized
+func tokenize(text string) []token {
+	tokens := make([]token, 0)
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, token{word: normalize(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: normalize(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+func words(text string) []string {
+	tokens := tokenize(text)
+	ws := make([]string, len(tokens))
+	for i, t := range tokens {
+		ws[i] = t.word
+	}
+	return ws
+}
+
+// findPhrase returns the index of the first token where the phrase starts, or -1
+func findPhrase(tokens []token, phrase []string, from int) int {
+	if len(phrase) == 0 {
+		return -1
+	}
+	for i := from; i+len(phrase) <= len(tokens); i++ {
+		found := true
+		for j, w := range phrase {
+			if tokens[i+j].word != w {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+// levenshtein is the edit distance between two words
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// maxTypos is the number of typos accepted for a word of that length
+func maxTypos(word string) int {
+	n := len([]rune(word))
+	switch {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"math"
+)
+
+type vector map[string]float64
+
+type example struct {
+	intent int
+	vec    vector
+}
+
+// tfidf is a nearest example classifier, every example is a normalized tf-idf vector
+type tfidf struct {
+	idf      map[string]float64
+	examples []example
+}
+
+func newTFIDF(intents []IntentSpec) *tfidf {
+	docs := make([][]string, 0)
+	owners := make([]int, 0)
+	for i, intent := range intents {
+		for _, e := range intent.Examples {
+			docs = append(docs, words(e))
+			owners = append(owners, i)
+		}
+	}
+
+	df := map[string]int{}
+	for _, doc := range docs {
+		seen := map[string]bool{}
+		for _, w := range doc {
+			if !seen[w] {
+				df[w]++
+				seen[w] = true
+			}
+		}
+	}
+
+	model := &tfidf{idf: map[string]float64{}, examples: make([]example, 0, len(docs))}
+	n := float64(len(docs))
+	for w, count := range df {
+		model.idf[w] = math.Log((1+n)/(1+float64(count))) + 1
+	}
+
+	for i, doc := range docs {
+		model.examples = append(model.examples, example{intent: owners[i], vec: model.vectorize(doc)})
+	}
+
+	return model
+}
+
+func (model *tfidf) vectorize(doc []string) vector {
+	vec := vector{}
+	for _, w := range doc {
+		if idf, ok := model.idf[w]; ok {
+			vec[w] += idf
+		}
+	}
+
+	norm := 0.0
+	for _, v := range vec {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	for w := range vec {
+		vec[w] /= norm
+	}
+
+	return vec
+}
+
+// scores returns the best cosine similarity of every intent
+func (model *tfidf) scores(doc []string, total int) []float64 {
+	scores := make([]float64, total)
+	vec := model.vectorize(doc)
+	if len(vec) == 0 {
+		return scores
+	}
+
+	for _, e := range model.examples {
+		sim := 0.0
+		for w, v := range vec {
+			sim += v * e.vec[w]
+		}
+		if sim > scores[e.intent] {
+			scores[e.intent] = sim
+		}
+	}
+
+	return scores
+}
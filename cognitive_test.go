@@ -0,0 +1,78 @@
+package neocortex_test
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/memory"
+	"github.com/minskylab/neocortex/cognitive/uselessbox"
+)
+
+// forgetfulBox records the contexts closed by the engine
+type forgetfulBox struct {
+	*uselessbox.Cognitive
+
+	mu     sync.Mutex
+	closed []string
+}
+
+func (box *forgetfulBox) CallContextDone(c *neo.Context) {
+	box.mu.Lock()
+	box.closed = append(box.closed, c.SessionID)
+	box.mu.Unlock()
+}
+
+func TestTheCognitiveIsNotifiedOfTheClosedContexts(t *testing.T) {
+	ch := memory.NewChannel()
+	box := &forgetfulBox{Cognitive: uselessbox.NewCognitive()}
+	engine, err := neo.New(box, []neo.CommunicationChannel{ch}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+
+	exchange, err := ch.Say(neo.PersonInfo{ID: "42"}, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.End("42")
+
+	box.mu.Lock()
+	defer box.mu.Unlock()
+	if len(box.closed) != 1 || box.closed[0] != exchange.Context.SessionID {
+		t.Errorf("the cognitive service must be notified of the closed context, got %q", box.closed)
+	}
+}
+
+// healthyBox reports its status
+type healthyBox struct {
+	*uselessbox.Cognitive
+}
+
+func (box *healthyBox) Status() interface{} {
+	return map[string]string{"state": "closed"}
+}
+
+func TestCognitiveStatus(t *testing.T) {
+	ch := memory.NewChannel()
+	engine, err := neo.New(uselessbox.NewCognitive(), []neo.CommunicationChannel{ch}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := engine.CognitiveStatus(); ok {
+		t.Error("the uselessbox doesn't report its status")
+	}
+
+	engine, err = neo.New(&healthyBox{uselessbox.NewCognitive()}, []neo.CommunicationChannel{memory.NewChannel()}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, ok := engine.CognitiveStatus()
+	if !ok || status.(map[string]string)["state"] != "closed" {
+		t.Errorf("expected the status of the cognitive service, got %v", status)
+	}
+}
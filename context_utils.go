@@ -1,5 +1,15 @@
 package neocortex
 
+// snapshot copies the context with its own variables map, useful for keep records of it
+func (c *Context) snapshot() Context {
+	s := *c
+	s.Variables = make(map[string]interface{}, len(c.Variables))
+	for k, v := range c.Variables {
+		s.Variables[k] = v
+	}
+	return s
+}
+
 func (c *Context) SetContextVariable(name string, value interface{}) {
 	if c.Variables == nil {
 		c.Variables = map[string]interface{}{}
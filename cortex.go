@@ -2,8 +2,7 @@ package neocortex
 
 import (
 	"context"
-	"errors"
-	"log"
+	"sync"
 	"time"
 )
 
@@ -16,68 +15,135 @@ type Engine struct {
 	done                chan error
 	cognitive           CognitiveService
 	channels            []CommunicationChannel
-	registeredResolvers map[CommunicationChannel]map[*Matcher]*HandleResolver
+	registeredResolvers map[CommunicationChannel][]*resolver
 	generalResolver     map[CommunicationChannel]*HandleResolver
-	registeredInjection map[CommunicationChannel]map[*Matcher]*InInjection
+	registeredInjection map[CommunicationChannel][]*injection
 	generalInjection    map[CommunicationChannel]*InInjection
+	resolutionMode      ResolutionMode
 
-	Repository    Repository
-	ActiveDialogs map[*Context]*Dialog
-	api           *API
-	Register      map[string]string
+	registeredOutInjection map[CommunicationChannel][]*outInjection
+
+	Repository Repository
+	Sessions   *SessionManager
+	api        *API
+
+	// Register has the username and the password of the owners created when the engine runs.
+	//
+	// Deprecated: the admins have roles and they are kept by the AdminRepository, use RegisterAdmin
+	Register map[string]string
+
+	admins   AdminRepository
+	adminsMu sync.Mutex
+	tokens   TokenRepository
 
 	Analytics             *Analytics
 	dialogPerformanceFunc func(*Dialog) float64
 
-	secret string
-}
+	persistenceMode    PersistenceMode
+	checkpointInterval time.Duration
+	recoveryWindow     time.Duration
 
-func (engine *Engine) onNewContextCreated(c *Context) {
-	log.Println("creating new context: ", c.SessionID)
-	engine.ActiveDialogs[c] = newDialog()
+	auth AuthConfig
+
+	port           string
+	prefix         string
+	corsOrigins    []string
+	sessionTimeout time.Duration
+	gcTick         time.Duration
+
+	logger   Logger
+	logLevel LogLevelType
 }
 
-func (engine *Engine) onContextIsDone(c *Context) {
-	for _, ch := range engine.channels {
-		ch.CallContextDone(c)
-	}
-	log.Println("closing context: ", c.SessionID)
-	if dialog, ok := engine.ActiveDialogs[c]; ok {
-		dialog.EndAt = time.Now()
-		if engine.Repository != nil {
-			dialog.Performance = engine.dialogPerformanceFunc(dialog)
-			err := engine.Repository.SaveDialog(dialog)
-			if err != nil {
-				engine.done <- err
-			}
+// ActiveDialogs returns a snapshot of the active dialogs by its context.
+//
+// Deprecated: it was a field of the engine (not safe for concurrent use), the active dialogs are kept
+// by the Sessions of the engine, use Sessions.Contexts and Sessions.Dialog
+func (engine *Engine) ActiveDialogs() map[*Context]*Dialog {
+	dialogs := map[*Context]*Dialog{}
+	for _, c := range engine.Sessions.Contexts() {
+		if dialog, ok := engine.Sessions.Dialog(c); ok {
+			dialogs[c] = dialog
 		}
+	}
+	return dialogs
+}
 
-		delete(engine.ActiveDialogs, c)
-		log.Println("finally deleting: ", c.SessionID)
+func (engine *Engine) onNewContextCreated(channel CommunicationChannel, c *Context) {
+	if _, ok := engine.Sessions.resume(c); ok {
+		engine.Sessions.setChannel(c, channelName(channel))
+		engine.log(Info, "resuming recovered dialog", engine.contextFields(c))
+		return
 	}
+
+	engine.Sessions.Open(c)
+	engine.Sessions.setChannel(c, channelName(channel))
+	engine.log(Info, "creating new context", engine.contextFields(c))
 }
 
-// RegisterAdmin you can register new admin for get info purpose
-func (engine *Engine) RegisterAdmin(Username, Password string) error {
-	if engine.Register != nil {
-		engine.Register[Username] = Password
+// expireContext closes the context if it's still idle, it waits for the message in progress of the context
+// (the message could have refreshed it meanwhile)
+func (engine *Engine) expireContext(c *Context, maxIdle time.Duration) {
+	release := engine.Sessions.acquire(c)
+	defer release()
+
+	if !engine.Sessions.isIdle(c, time.Now(), maxIdle) {
+		return
 	}
-	return nil
+	engine.onContextIsDone(c)
 }
 
-// getAdmin you can get the password of the admin
-func (engine *Engine) getAdmin(Username string) (string, error) {
-	if val, ok := engine.Register[Username]; ok {
-		return val, nil
+// closeContext closes the context once its message in progress (if any) is resolved
+func (engine *Engine) closeContext(c *Context) {
+	release := engine.Sessions.acquire(c)
+	defer release()
+
+	engine.onContextIsDone(c)
+}
+
+// contextDone closes a context finished by a channel or by the cognitive service, if the context has a
+// message in progress (e.g. the cognitive service ends it while it answers) the close waits for the message
+func (engine *Engine) contextDone(c *Context) {
+	deferred := engine.Sessions.deferClose(c, func() {
+		// the context could be closed meanwhile (e.g. by a channel notified of the close)
+		if engine.Sessions.IsActive(c) {
+			engine.onContextIsDone(c)
+		}
+	})
+	if deferred {
+		return
 	}
-	return "", nil
+	engine.closeContext(c)
 }
 
-// RemoveAdmin let you remove the admin
-func (engine *Engine) RemoveAdmin(Username string) error {
-	if _, ok := engine.Register[Username]; ok {
-		delete(engine.Register, Username)
+func (engine *Engine) onContextIsDone(c *Context) {
+	// the channels don't know the recovered contexts (its person didn't come back after the restart)
+	if !engine.Sessions.isRecovered(c) {
+		for _, ch := range engine.channels {
+			ch.CallContextDone(c)
+		}
+	}
+
+	if caller, ok := engine.cognitive.(ContextDoneCaller); ok {
+		caller.CallContextDone(c)
 	}
 
-	return errors.New("no user found")
+	fields := engine.contextFields(c)
+	engine.log(Debug, "closing context", fields)
+	closed, err := engine.Sessions.finish(c, func(dialog *Dialog) error {
+		dialog.EndAt = time.Now()
+		if engine.Repository == nil {
+			return nil
+		}
+		dialog.Performance = engine.dialogPerformanceFunc(dialog)
+		return engine.Repository.SaveDialog(dialog)
+	})
+
+	if err != nil {
+		engine.done <- err
+	}
+
+	if closed {
+		engine.log(Info, "finally deleting", fields)
+	}
 }
@@ -1,4 +1,117 @@
 package neocortex
 
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// envPrefix starts the names of the environment variables of the configuration
+const envPrefix = "NEOCORTEX_"
+
+// Configuration are the settings of the engine that can live outside of the code, the zero fields keep
+// the defaults. It's loaded from a YAML file and the environment (see LoadConfiguration), e.g.
+//
+//	port: ":4200"
+//	prefix: /api
+//	cors_origins: ["https://dashboard.example.com"]
+//	session_timeout: 10m
+//	gc_tick: 1s
+//	persistence_mode: periodic
+//	checkpoint_interval: 30s
+//	jwt_secret: a-secret-of-at-least-32-bytes...
+//	log_level: debug
+//	log_format: json
+//
+// Every field can be overwritten by its environment variable, e.g. NEOCORTEX_SESSION_TIMEOUT=15m
+// or NEOCORTEX_CORS_ORIGINS=https://a.example.com,https://b.example.com
 type Configuration struct {
+	Port        string   `yaml:"port" env:"PORT"`
+	Prefix      string   `yaml:"prefix" env:"PREFIX"`
+	CORSOrigins []string `yaml:"cors_origins" env:"CORS_ORIGINS"`
+
+	SessionTimeout time.Duration `yaml:"session_timeout" env:"SESSION_TIMEOUT"`
+	GCTick         time.Duration `yaml:"gc_tick" env:"GC_TICK"`
+
+	PersistenceMode    PersistenceMode `yaml:"persistence_mode" env:"PERSISTENCE_MODE"`
+	CheckpointInterval time.Duration   `yaml:"checkpoint_interval" env:"CHECKPOINT_INTERVAL"`
+	RecoveryWindow     time.Duration   `yaml:"recovery_window" env:"RECOVERY_WINDOW"`
+
+	JWTSecret     string        `yaml:"jwt_secret" env:"JWT_SECRET"`
+	JWTRealm      string        `yaml:"jwt_realm" env:"JWT_REALM"`
+	JWTTimeout    time.Duration `yaml:"jwt_timeout" env:"JWT_TIMEOUT"`
+	JWTMaxRefresh time.Duration `yaml:"jwt_max_refresh" env:"JWT_MAX_REFRESH"`
+
+	LogLevel  LogLevelType `yaml:"log_level" env:"LOG_LEVEL"`
+	LogFormat LogFormat    `yaml:"log_format" env:"LOG_FORMAT"`
+}
+
+// LoadConfiguration reads the YAML file (if path isn't empty) and then the environment,
+// the environment variables take precedence over the file
+func LoadConfiguration(path string) (*Configuration, error) {
+	config := new(Configuration)
+
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfiguration, path, err)
+		}
+	}
+
+	if err := config.LoadEnv(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadEnv overwrites the fields with its environment variables (see Configuration)
+func (config *Configuration) LoadEnv() error {
+	value := reflect.ValueOf(config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := envPrefix + field.Tag.Get("env")
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := setFromEnv(value.Field(i), raw); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfiguration, name, err)
+		}
+	}
+	return nil
+}
+
+func setFromEnv(field reflect.Value, raw string) error {
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		values := make([]string, 0)
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
 }
@@ -0,0 +1,175 @@
+package neocortex_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/cognitive/uselessbox"
+)
+
+// setEnv sets the variable for the test, the previous value is restored at the end of the test
+func setEnv(t *testing.T, name, value string) {
+	t.Helper()
+
+	previous, existed := os.LookupEnv(name)
+	if err := os.Setenv(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if existed {
+			os.Setenv(name, previous)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func TestLoadConfiguration(t *testing.T) {
+	const file = `
+port: ":4300"
+prefix: /bot
+cors_origins: ["https://dashboard.example.com"]
+session_timeout: 10m
+gc_tick: 2s
+persistence_mode: periodic
+checkpoint_interval: 30s
+jwt_realm: bot
+log_level: debug
+log_format: json
+`
+
+	cases := []struct {
+		name    string
+		yaml    string
+		env     map[string]string
+		want    *neo.Configuration
+		invalid bool
+	}{
+		{
+			name: "yaml",
+			yaml: file,
+			want: &neo.Configuration{
+				Port:               ":4300",
+				Prefix:             "/bot",
+				CORSOrigins:        []string{"https://dashboard.example.com"},
+				SessionTimeout:     10 * time.Minute,
+				GCTick:             2 * time.Second,
+				PersistenceMode:    neo.PersistPeriodic,
+				CheckpointInterval: 30 * time.Second,
+				JWTRealm:           "bot",
+				LogLevel:           neo.Debug,
+				LogFormat:          neo.JSONFormat,
+			},
+		},
+		{
+			name: "the environment overrides the yaml",
+			yaml: file,
+			env: map[string]string{
+				"NEOCORTEX_PORT":            ":5000",
+				"NEOCORTEX_CORS_ORIGINS":    "https://a.example.com, https://b.example.com,",
+				"NEOCORTEX_SESSION_TIMEOUT": "15m",
+				"NEOCORTEX_JWT_SECRET":      "from-the-environment",
+				"NEOCORTEX_LOG_LEVEL":       "warn",
+			},
+			want: &neo.Configuration{
+				Port:               ":5000",
+				Prefix:             "/bot",
+				CORSOrigins:        []string{"https://a.example.com", "https://b.example.com"},
+				SessionTimeout:     15 * time.Minute,
+				GCTick:             2 * time.Second,
+				PersistenceMode:    neo.PersistPeriodic,
+				CheckpointInterval: 30 * time.Second,
+				JWTSecret:          "from-the-environment",
+				JWTRealm:           "bot",
+				LogLevel:           neo.Warn,
+				LogFormat:          neo.JSONFormat,
+			},
+		},
+		{
+			name: "the environment without yaml",
+			env:  map[string]string{"NEOCORTEX_GC_TICK": "500ms", "NEOCORTEX_PREFIX": "/v2"},
+			want: &neo.Configuration{GCTick: 500 * time.Millisecond, Prefix: "/v2"},
+		},
+		{name: "invalid yaml duration", yaml: "session_timeout: ten minutes", invalid: true},
+		{name: "unknown yaml field", yaml: "session_timeot: 10m", invalid: true},
+		{name: "invalid environment duration", env: map[string]string{"NEOCORTEX_CHECKPOINT_INTERVAL": "often"}, invalid: true},
+		{name: "invalid environment duration over the yaml", yaml: file, env: map[string]string{"NEOCORTEX_GC_TICK": "1"}, invalid: true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			for name, value := range tc.env {
+				setEnv(t, name, value)
+			}
+
+			path := ""
+			if tc.yaml != "" {
+				path = filepath.Join(t.TempDir(), "neocortex.yaml")
+				if err := ioutil.WriteFile(path, []byte(tc.yaml), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config, err := neo.LoadConfiguration(path)
+			if tc.invalid {
+				if !errors.Is(err, neo.ErrInvalidConfiguration) {
+					t.Errorf("expected ErrInvalidConfiguration, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config, tc.want) {
+				t.Errorf("expected %+v, got %+v", tc.want, config)
+			}
+		})
+	}
+}
+
+func TestNewWithTheConfiguration(t *testing.T) {
+	cases := []struct {
+		name   string
+		config neo.Configuration
+		want   error
+	}{
+		{"zero values keep the defaults", neo.Configuration{}, nil},
+		{"port", neo.Configuration{Port: "4300"}, nil},
+		{"host and port", neo.Configuration{Port: "127.0.0.1:0"}, nil},
+		{"named port", neo.Configuration{Port: "http"}, neo.ErrInvalidConfiguration},
+		{"port out of range", neo.Configuration{Port: ":70000"}, neo.ErrInvalidConfiguration},
+		{"negative port", neo.Configuration{Port: ":-1"}, neo.ErrInvalidConfiguration},
+		{"prefix without slash", neo.Configuration{Prefix: "api"}, neo.ErrInvalidConfiguration},
+		{"origin without scheme", neo.Configuration{CORSOrigins: []string{"example.com"}}, neo.ErrInvalidConfiguration},
+		{"negative session timeout", neo.Configuration{SessionTimeout: -time.Second}, neo.ErrInvalidConfiguration},
+		{"negative gc tick", neo.Configuration{GCTick: -time.Second}, neo.ErrInvalidConfiguration},
+		{"unknown persistence mode", neo.Configuration{PersistenceMode: "sometimes"}, neo.ErrInvalidConfiguration},
+		{"unknown log level", neo.Configuration{LogLevel: "verbose"}, neo.ErrInvalidConfiguration},
+		{"unknown log format", neo.Configuration{LogFormat: "xml"}, neo.ErrInvalidConfiguration},
+		{"weak secret", neo.Configuration{JWTSecret: "short"}, neo.ErrWeakSecret},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			_, err := neo.New(uselessbox.NewCognitive(), nil,
+				neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)),
+				neo.WithConfiguration(&config),
+			)
+			if tc.want == nil && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
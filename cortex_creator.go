@@ -2,8 +2,6 @@ package neocortex
 
 import (
 	"context"
-	"log"
-	"time"
 
 	"os"
 	"os/signal"
@@ -14,30 +12,68 @@ func newDefaultEngine(cognitive CognitiveService, channels ...CommunicationChann
 	engine := &Engine{}
 	engine.channels = channels
 	engine.cognitive = cognitive
-	engine.registeredResolvers = map[CommunicationChannel]map[*Matcher]*HandleResolver{}
+	engine.registeredResolvers = map[CommunicationChannel][]*resolver{}
 	engine.generalResolver = map[CommunicationChannel]*HandleResolver{}
-	engine.registeredInjection = map[CommunicationChannel]map[*Matcher]*InInjection{}
+	engine.registeredInjection = map[CommunicationChannel][]*injection{}
 	engine.generalInjection = map[CommunicationChannel]*InInjection{}
+	engine.registeredOutInjection = map[CommunicationChannel][]*outInjection{}
+	engine.resolutionMode = AllMatches
 	engine.done = make(chan error, 1)
 	engine.Register = map[string]string{}
-	// engine.logger = logrus.StandardLogger() // In the future
-	engine.ActiveDialogs = map[*Context]*Dialog{}
+	engine.admins = newMemoryAdmins()
+	engine.tokens = newMemoryTokens()
+	engine.logger = StandardLogger()
+	engine.logLevel = defaultLogLevel
+	engine.Sessions = newSessionManager()
 	engine.dialogPerformanceFunc = defaultPerformance
-	engine.secret = "neocortex2019"
+	engine.persistenceMode = PersistOnClose
+	engine.checkpointInterval = defaultCheckpointInterval
+	engine.recoveryWindow = defaultRecoveryWindow
+	engine.auth = defaultAuthConfig()
+	engine.port = defaultPort
+	engine.prefix = defaultPrefix
+	engine.sessionTimeout = defaultSessionTimeout
+	engine.gcTick = defaultGCTick
 
 	return engine
 }
 
-// Default ...
+// Default creates an engine with the repository and the default settings, see New for more settings
 func Default(repository Repository, cognitive CognitiveService, channels ...CommunicationChannel) (*Engine, error) {
-	var err error
+	return New(cognitive, channels, WithRepository(repository))
+}
+
+// New creates an engine over the cognitive service and the channels, the options change its defaults, e.g.
+//
+//	config, err := neocortex.LoadConfiguration("neocortex.yaml")
+//	...
+//	engine, err := neocortex.New(watson, []neocortex.CommunicationChannel{fb},
+//		neocortex.WithRepository(repo),
+//		neocortex.WithConfiguration(config),
+//	)
+func New(cognitive CognitiveService, channels []CommunicationChannel, opts ...EngineOption) (*Engine, error) {
 	engine := newDefaultEngine(cognitive, channels...)
-	engine.Repository = repository
-	engine.Analytics, err = newDefaultAnalytics(engine.Repository, defaultPerformance)
-	if err != nil {
-		return nil, err
+	for _, opt := range opts {
+		if err := opt(engine); err != nil {
+			return nil, err
+		}
 	}
-	engine.api = newCortexAPI(repository, engine.Analytics, "/api", ":4200")
+
+	repository := engine.Repository
+	if admins, ok := repository.(AdminRepository); ok {
+		engine.admins = admins
+	}
+	if tokens, ok := repository.(TokenRepository); ok {
+		engine.tokens = tokens
+	}
+	if engine.Analytics == nil {
+		engine.Analytics = NewAnalytics(repository, defaultPerformance)
+	}
+	engine.api = newCortexAPI(repository, engine.Analytics, engine.prefix, engine.port)
+	engine.api.corsOrigins = engine.corsOrigins
+	engine.api.logger = engine.Logger()
+	engine.Analytics.logger = engine.Logger()
+	engine.injectLogger()
 
 	fabric := func(ctx context.Context, info PersonInfo) *Context {
 		newContext := cognitive.CreateNewContext(&ctx, info)
@@ -45,11 +81,12 @@ func Default(repository Repository, cognitive CognitiveService, channels ...Comm
 	}
 
 	cognitive.OnContextIsDone(func(c *Context) {
-		engine.onContextIsDone(c)
+		engine.contextDone(c)
 	})
 
 	for _, ch := range channels {
-		engine.registeredResolvers[ch] = map[*Matcher]*HandleResolver{}
+		ch := ch
+		engine.registeredResolvers[ch] = []*resolver{}
 		ch.SetContextFabric(fabric)
 		err := ch.RegisterMessageEndpoint(func(c *Context, message *Input, response OutputResponse) error {
 			return engine.onMessage(ch, c, message, response)
@@ -60,11 +97,11 @@ func Default(repository Repository, cognitive CognitiveService, channels ...Comm
 		}
 
 		ch.OnNewContextCreated(func(c *Context) {
-			engine.onNewContextCreated(c)
+			engine.onNewContextCreated(ch, c)
 		})
 
 		ch.OnContextIsDone(func(c *Context) {
-			engine.onContextIsDone(c)
+			engine.contextDone(c)
 		})
 
 		go func(ch *CommunicationChannel) {
@@ -82,13 +119,19 @@ func (engine *Engine) Run() error {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt)
 
-	gc := defaultGarbageCollector(10 * time.Minute)
+	gc := newGarbageCollector(engine.gcTick, engine.sessionTimeout)
+
+	engine.registerDeprecatedAdmins()
+
+	if err := engine.recoverDialogs(gc.maxLastResponse); err != nil {
+		engine.log(Error, "error recovering the active dialogs", Fields{ErrorField: err})
+	}
 
 	go func() {
 		<-signalChan
-		log.Println("Closing all dialogs, total: ", len(engine.ActiveDialogs))
-		for c := range engine.ActiveDialogs {
-			engine.onContextIsDone(c)
+		engine.log(Info, "closing all dialogs", Fields{"total": engine.Sessions.Len()})
+		for _, c := range engine.Sessions.Contexts() {
+			engine.closeContext(c)
 		}
 		engine.done <- nil
 	}()
@@ -99,6 +142,7 @@ func (engine *Engine) Run() error {
 	}()
 
 	engine.runGarbageCollector(gc)
+	engine.runCheckpoints()
 
 	return <-engine.done
 }
@@ -0,0 +1,98 @@
+package neocortex
+
+import (
+	"path"
+	"reflect"
+)
+
+// Logger returns the logger of the engine filtered by its level, it's the logger set into the channels
+// and the cognitive service that implement LoggerSetter
+func (engine *Engine) Logger() Logger {
+	return LevelLogger(engine.logger, engine.logLevel)
+}
+
+// log writes a log of the engine if its level isn't under the level of the engine
+func (engine *Engine) log(level LogLevelType, message string, fields Fields) {
+	if level.severity() < engine.logLevel.severity() {
+		return
+	}
+	if fields == nil {
+		fields = Fields{}
+	}
+	engine.logger.Log(level, message, fields)
+}
+
+// contextFields returns the fields of the logs about the context, with its channel and dialog if it's active
+func (engine *Engine) contextFields(c *Context) Fields {
+	fields := Fields{SessionIDField: c.SessionID}
+	if c.Person.ID != "" {
+		fields[PersonIDField] = c.Person.ID
+	}
+
+	if dialogID, channel, ok := engine.Sessions.describe(c); ok {
+		fields[DialogIDField] = dialogID
+		if channel != "" {
+			fields[ChannelField] = channel
+		}
+	}
+	return fields
+}
+
+// withError returns a copy of the fields with the error
+func withError(fields Fields, err error) Fields {
+	copied := make(Fields, len(fields)+1)
+	for key, value := range fields {
+		copied[key] = value
+	}
+	copied[ErrorField] = err
+	return copied
+}
+
+// forwardLogs writes the logs of the output (added by the cognitive service and the resolvers) into the logger
+func (engine *Engine) forwardLogs(c *Context, out *Output) {
+	if out == nil {
+		return
+	}
+
+	for _, l := range out.Logs {
+		if l == nil {
+			continue
+		}
+		fields := engine.contextFields(c)
+		fields["source"] = "output"
+		engine.log(l.Level, l.Message, fields)
+	}
+}
+
+// injectLogger sets the logger of the engine into the cognitive service and the channels that implement LoggerSetter
+func (engine *Engine) injectLogger() {
+	if setter, ok := engine.cognitive.(LoggerSetter); ok {
+		setter.SetLogger(engine.Logger())
+	}
+
+	for _, ch := range engine.channels {
+		if setter, ok := ch.(LoggerSetter); ok {
+			setter.SetLogger(AddFields(engine.Logger(), Fields{ChannelField: channelName(ch)}))
+		}
+	}
+}
+
+// channelName returns the name of the channel for the logs, the channels can define it with
+// a Name method, otherwise it's the name of its package (e.g. telegram)
+func channelName(ch CommunicationChannel) string {
+	if named, ok := ch.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+
+	t := reflect.TypeOf(ch)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return path.Base(t.PkgPath())
+}
@@ -1,5 +1,14 @@
 package neocortex
 
+// CognitiveStatus returns the status of the cognitive service, false if it doesn't implement StatusReporter
+func (engine *Engine) CognitiveStatus() (interface{}, bool) {
+	reporter, ok := engine.cognitive.(StatusReporter)
+	if !ok {
+		return nil, false
+	}
+	return reporter.Status(), true
+}
+
 // SetPerformanceMetric links a custom performance metric function
 func (engine *Engine) SetPerformanceMetric(perf func(*Dialog) float64) {
 	engine.dialogPerformanceFunc = perf
@@ -1,17 +1,17 @@
 package neocortex
 
 import (
-	"log"
 	"strings"
-	"time"
 )
 
 func (engine *Engine) onMessage(channel CommunicationChannel, c *Context, in *Input, response OutputResponse) error {
+	release := engine.Sessions.acquire(c)
+	defer release()
 
 	inMatched := false
-	for matcher, injector := range engine.registeredInjection[channel] {
-		if in.Match(nil, matcher) {
-			in = (*injector)(c, in)
+	for _, inj := range engine.registeredInjection[channel] {
+		if in.Match(c, inj.matcher) {
+			in = inj.middle(c, in)
 			inMatched = true
 		}
 	}
@@ -21,11 +21,7 @@ func (engine *Engine) onMessage(channel CommunicationChannel, c *Context, in *In
 		in = f(c, in)
 	}
 
-	if dialog, activeDialogExist := engine.ActiveDialogs[c]; activeDialogExist {
-		dialog.LastActivity = time.Now()
-		dialog.Contexts = append(dialog.Contexts, &ContextRecord{At: time.Now(), Context: *c})
-		dialog.Ins = append(dialog.Ins, &InputRecord{At: time.Now(), Input: *in})
-	}
+	engine.recordInput(c, in)
 
 	if in.Data.Type == InputText {
 		in.Data.Value = strings.ReplaceAll(in.Data.Value, "\n", " ")
@@ -35,7 +31,7 @@ func (engine *Engine) onMessage(channel CommunicationChannel, c *Context, in *In
 	if err != nil {
 		if err == ErrSessionNotExist {
 			// Creating new context
-			log.Println("calling to engine.cognitive.CreateNewContext")
+			engine.log(Debug, "the session doesn't exist, creating a new context", engine.contextFields(c))
 			c1 := engine.cognitive.CreateNewContext(c.Context, c.Person)
 			c = c1
 			if engine.generalInjection[channel] != nil && !inMatched {
@@ -43,7 +39,8 @@ func (engine *Engine) onMessage(channel CommunicationChannel, c *Context, in *In
 				in = f(c, in)
 			}
 
-			engine.ActiveDialogs[c] = newDialog()
+			engine.Sessions.Open(c)
+			engine.Sessions.setChannel(c, channelName(channel))
 
 			out, err = engine.cognitive.GetProtoResponse(c, in)
 			if err != nil {
@@ -55,64 +52,81 @@ func (engine *Engine) onMessage(channel CommunicationChannel, c *Context, in *In
 		}
 	}
 
-	go func(intents []Intent, entities []Entity, nodes []*DialogNode, vars map[string]interface{}) {
+	if out == nil {
+		return ErrInvalidResponseFromCognitiveService
+	}
+	out.Input = in
+
+	// the logs are forwarded once the resolvers are done, they can add their own logs to the output
+	defer engine.forwardLogs(c, out)
+
+	// the names are copied here because the resolvers can modify the context variables meanwhile
+	vars := make([]string, 0, len(c.Variables))
+	for v := range c.Variables {
+		vars = append(vars, v)
+	}
+
+	fields := engine.contextFields(c)
+	go func(intents []Intent, entities []Entity, nodes []*DialogNode, vars []string) {
 		var err error
 		if engine.Repository != nil {
 			for _, i := range intents {
 				if err = engine.Repository.RegisterIntent(i.Intent); err != nil {
-					log.Println(err)
+					engine.log(Error, "error registering the output", withError(fields, err))
 				}
 			}
 			for _, e := range entities {
 				if err = engine.Repository.RegisterEntity(e.Entity); err != nil {
-					log.Println(err)
+					engine.log(Error, "error registering the output", withError(fields, err))
 				}
 			}
 			for _, n := range nodes {
 				if err = engine.Repository.RegisterDialogNode(n.Title); err != nil {
-					log.Println(err)
+					engine.log(Error, "error registering the output", withError(fields, err))
 				}
 			}
-			for v := range vars {
+			for _, v := range vars {
 				if err = engine.Repository.RegisterContextVar(v); err != nil {
-					log.Println(err)
+					engine.log(Error, "error registering the output", withError(fields, err))
 				}
 			}
 		}
-	}(out.Intents, out.Entities, out.VisitedNodes, c.Variables)
+	}(out.Intents, out.Entities, out.VisitedNodes, vars)
 
 	resolvers, channelIsRegistered := engine.registeredResolvers[channel]
 	if !channelIsRegistered {
 		return ErrChannelIsNotRegistered
 	}
 
+	response = engine.outboundResponse(channel, in, response)
+
 	exist := false
-	for m, resolver := range resolvers {
-		if out.Match(c, m) {
-			if err = (*resolver)(c, in, out, response); err != nil {
-				return err
-			}
+	for _, r := range resolvers {
+		if !r.matcher.Matches(c, in, out) {
+			continue
+		}
 
-			if dialog, activeDialogExist := engine.ActiveDialogs[c]; activeDialogExist {
-				dialog.LastActivity = time.Now()
-				dialog.Contexts = append(dialog.Contexts, &ContextRecord{At: time.Now(), Context: *c})
-				dialog.Outs = append(dialog.Outs, &OutputRecord{At: time.Now(), Output: *out})
-			}
+		err = r.handler(c, in, out, response)
+		if err != nil && err != ErrStopPropagation {
+			return err
+		}
+
+		engine.recordOutput(c, out)
 
-			exist = true
+		exist = true
+
+		if err == ErrStopPropagation || engine.resolutionMode == FirstMatch {
+			break
 		}
 	}
 
 	if engine.generalResolver[channel] != nil && !exist {
-		if err = (*engine.generalResolver[channel])(c, in, out, response); err != nil {
+		err = (*engine.generalResolver[channel])(c, in, out, response)
+		if err != nil && err != ErrStopPropagation {
 			return err
 		}
 
-		if dialog, activeDialogExist := engine.ActiveDialogs[c]; activeDialogExist {
-			dialog.LastActivity = time.Now()
-			dialog.Contexts = append(dialog.Contexts, &ContextRecord{At: time.Now(), Context: *c})
-			dialog.Outs = append(dialog.Outs, &OutputRecord{At: time.Now(), Output: *out})
-		}
+		engine.recordOutput(c, out)
 	}
 
 	return nil
@@ -0,0 +1,213 @@
+package neocortex
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPort = ":4200"
+const defaultPrefix = "/api"
+const defaultSessionTimeout = 10 * time.Minute
+const defaultGCTick = time.Second
+
+// EngineOption configures the engine created by New
+type EngineOption func(engine *Engine) error
+
+// WithRepository persists the dialogs (and the admins and tokens if the repository implements
+// AdminRepository and TokenRepository), without a repository the API isn't launched
+func WithRepository(repository Repository) EngineOption {
+	return func(engine *Engine) error {
+		engine.Repository = repository
+		return nil
+	}
+}
+
+// WithAnalytics replaces the analytics of the API (see NewAnalytics), by default they use the repository
+func WithAnalytics(analytics *Analytics) EngineOption {
+	return func(engine *Engine) error {
+		engine.Analytics = analytics
+		return nil
+	}
+}
+
+// WithLogger replaces the logger of the engine (see NewLogger), the text logger over the standard error
+// is used by default. It's set into the channels and the cognitive service that implement LoggerSetter
+func WithLogger(logger Logger) EngineOption {
+	return func(engine *Engine) error {
+		if logger == nil {
+			return fmt.Errorf("%w: the logger can't be nil", ErrInvalidConfiguration)
+		}
+		engine.logger = logger
+		return nil
+	}
+}
+
+// WithLogLevel drops the logs under the level, info by default
+func WithLogLevel(level LogLevelType) EngineOption {
+	return func(engine *Engine) error {
+		level, err := ParseLogLevel(string(level))
+		if err != nil {
+			return err
+		}
+		engine.logLevel = level
+		return nil
+	}
+}
+
+// WithPort changes the address of the API (a port or host:port), ":4200" by default
+func WithPort(port string) EngineOption {
+	return func(engine *Engine) error {
+		if port == "" {
+			return fmt.Errorf("%w: the port can't be empty", ErrInvalidConfiguration)
+		}
+		if !strings.Contains(port, ":") {
+			port = ":" + port
+		}
+		_, number, err := net.SplitHostPort(port)
+		if n, convErr := strconv.Atoi(number); err != nil || convErr != nil || n < 0 || n > 65535 {
+			return fmt.Errorf("%w: invalid port %q", ErrInvalidConfiguration, port)
+		}
+		engine.port = port
+		return nil
+	}
+}
+
+// WithPrefix changes the prefix of the endpoints of the API, "/api" by default
+func WithPrefix(prefix string) EngineOption {
+	return func(engine *Engine) error {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("%w: the prefix must start with /", ErrInvalidConfiguration)
+		}
+		engine.prefix = strings.TrimSuffix(prefix, "/")
+		return nil
+	}
+}
+
+// WithCORSOrigins limits the origins allowed by the API (e.g. "https://dashboard.example.com"),
+// all origins are allowed by default or with "*"
+func WithCORSOrigins(origins ...string) EngineOption {
+	return func(engine *Engine) error {
+		for _, origin := range origins {
+			if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+				return fmt.Errorf("%w: the origin %q must be * or start with http:// or https://", ErrInvalidConfiguration, origin)
+			}
+		}
+		engine.corsOrigins = origins
+		return nil
+	}
+}
+
+// WithSessionTimeout changes how long a dialog can be idle before the engine closes it, 10 minutes by default
+func WithSessionTimeout(timeout time.Duration) EngineOption {
+	return func(engine *Engine) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: the session timeout must be positive", ErrInvalidConfiguration)
+		}
+		engine.sessionTimeout = timeout
+		return nil
+	}
+}
+
+// WithGCTick changes how often the engine looks for the idle dialogs, every second by default
+func WithGCTick(tick time.Duration) EngineOption {
+	return func(engine *Engine) error {
+		if tick <= 0 {
+			return fmt.Errorf("%w: the tick of the garbage collector must be positive", ErrInvalidConfiguration)
+		}
+		engine.gcTick = tick
+		return nil
+	}
+}
+
+// WithPersistence is the option of SetPersistenceMode
+func WithPersistence(mode PersistenceMode, interval ...time.Duration) EngineOption {
+	return func(engine *Engine) error {
+		switch mode {
+		case PersistIncremental, PersistPeriodic, PersistOnClose:
+		default:
+			return fmt.Errorf("%w: unknown persistence mode %q", ErrInvalidConfiguration, mode)
+		}
+		engine.SetPersistenceMode(mode, interval...)
+		return nil
+	}
+}
+
+// WithRecoveryWindow is the option of SetRecoveryWindow
+func WithRecoveryWindow(window time.Duration) EngineOption {
+	return func(engine *Engine) error {
+		engine.SetRecoveryWindow(window)
+		return nil
+	}
+}
+
+// WithAuth is the option of SetAuthConfig
+func WithAuth(config AuthConfig) EngineOption {
+	return func(engine *Engine) error {
+		return engine.SetAuthConfig(config)
+	}
+}
+
+// WithConfiguration applies the settings of the configuration, its zero fields keep the defaults
+func WithConfiguration(config *Configuration) EngineOption {
+	return func(engine *Engine) error {
+		if config == nil {
+			return nil
+		}
+
+		options := make([]EngineOption, 0)
+		if config.LogFormat != "" {
+			switch config.LogFormat {
+			case TextFormat, JSONFormat:
+			default:
+				return fmt.Errorf("%w: unknown log format %q", ErrInvalidConfiguration, config.LogFormat)
+			}
+			options = append(options, WithLogger(NewLogger(os.Stderr, config.LogFormat)))
+		}
+		if config.LogLevel != "" {
+			options = append(options, WithLogLevel(config.LogLevel))
+		}
+		if config.Port != "" {
+			options = append(options, WithPort(config.Port))
+		}
+		if config.Prefix != "" {
+			options = append(options, WithPrefix(config.Prefix))
+		}
+		if len(config.CORSOrigins) > 0 {
+			options = append(options, WithCORSOrigins(config.CORSOrigins...))
+		}
+		if config.SessionTimeout != 0 {
+			options = append(options, WithSessionTimeout(config.SessionTimeout))
+		}
+		if config.GCTick != 0 {
+			options = append(options, WithGCTick(config.GCTick))
+		}
+		if config.PersistenceMode != "" {
+			options = append(options, WithPersistence(config.PersistenceMode, config.CheckpointInterval))
+		} else if config.CheckpointInterval != 0 {
+			options = append(options, WithPersistence(engine.persistenceMode, config.CheckpointInterval))
+		}
+		if config.RecoveryWindow != 0 {
+			options = append(options, WithRecoveryWindow(config.RecoveryWindow))
+		}
+		auth := AuthConfig{
+			Secret:     config.JWTSecret,
+			Realm:      config.JWTRealm,
+			Timeout:    config.JWTTimeout,
+			MaxRefresh: config.JWTMaxRefresh,
+		}
+		if auth != (AuthConfig{}) {
+			options = append(options, WithAuth(auth))
+		}
+
+		for _, option := range options {
+			if err := option(engine); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -1,6 +1,7 @@
 package neocortex
 
-// Entity define any kind of object or entity
+// Entity define any kind of object or entity, its Location is the start and the end of the
+// entity into the text of the input counted in characters (runes), not in bytes
 type Entity struct {
 	Entity     string                 `json:"entity"`
 	Location   []int64                `json:"location"`
@@ -9,3 +9,31 @@ var ErrInvalidResponseFromCognitiveService = errors.New("invalid response from c
 var ErrInvalidInputType = errors.New("invalid or unimplemented input type")
 var ErrContextNotExist = errors.New("context is not valid or not exist")
 var ErrChannelIsNotRegistered = errors.New("channel not exist on this engine instance")
+
+// ErrStopPropagation can be returned by a HandleResolver to stop the execution of the next matched resolvers
+var ErrStopPropagation = errors.New("stop propagation of the resolvers")
+
+var ErrDialogNotExist = errors.New("dialog not exist")
+var ErrViewNotExist = errors.New("view not exist")
+
+var ErrAdminNotExist = errors.New("admin not exist")
+var ErrAdminExists = errors.New("admin already exists")
+var ErrAdminDisabled = errors.New("admin is disabled")
+var ErrInvalidUsername = errors.New("invalid username")
+var ErrInvalidRole = errors.New("invalid admin role")
+var ErrWeakPassword = errors.New("the password is too short")
+var ErrInvalidCredentials = errors.New("incorrect username or password")
+
+// ErrLastOwner is returned when a change would leave the API without an enabled owner
+var ErrLastOwner = errors.New("the last enabled owner can't be removed, disabled or demoted")
+var ErrUnknownPasswordAlgorithm = errors.New("unknown password algorithm")
+
+var ErrAPIKeyNotExist = errors.New("api key not exist")
+var ErrInvalidAPIKey = errors.New("invalid, expired or revoked api key")
+var ErrInvalidScope = errors.New("invalid api key scope")
+
+// ErrWeakSecret is returned when the secret of the tokens is shorter than MinSecretLength
+var ErrWeakSecret = errors.New("the secret of the tokens is too short")
+
+// ErrInvalidConfiguration is wrapped by the errors of the options and the configuration of the engine
+var ErrInvalidConfiguration = errors.New("invalid configuration")
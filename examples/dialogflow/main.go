@@ -0,0 +1,33 @@
+package main
+
+import (
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/terminal"
+	"github.com/minskylab/neocortex/cognitive/dialogflow"
+)
+
+func main() {
+	agent, err := dialogflow.NewCognitive(dialogflow.NewCognitiveParams{
+		AccessToken:  "<GCLOUD_ACCESS_TOKEN>",
+		ProjectID:    "<DIALOGFLOW_PROJECT_ID>",
+		LanguageCode: "en",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	term := terminal.NewChannel(nil)
+
+	engine, err := neo.Default(nil, agent, term)
+	if err != nil {
+		panic(err)
+	}
+
+	engine.ResolveAny(term, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+
+	if err := engine.Run(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/grpc"
+	"github.com/minskylab/neocortex/cognitive/uselessbox"
+)
+
+func main() {
+	box := uselessbox.NewCognitive()
+
+	g, err := grpc.NewChannel(grpc.ChannelOptions{
+		Addr: ":50051",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	engine, err := neo.Default(nil, box, g)
+	if err != nil {
+		panic(err)
+	}
+
+	engine.ResolveAny(g, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+
+	if err := engine.Run(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/terminal"
+	"github.com/minskylab/neocortex/cognitive/rules"
+)
+
+func main() {
+	bot, err := rules.NewCognitiveFromFile("skill.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	term := terminal.NewChannel(nil)
+
+	engine, err := neo.Default(nil, bot, term)
+	if err != nil {
+		panic(err)
+	}
+
+	registerResolvers(engine, term)
+
+	if err := engine.Run(); err != nil {
+		panic(err)
+	}
+}
+
+// registerResolvers is shared with the transcripts test, which runs the bot over a memory channel
+func registerResolvers(engine *neo.Engine, ch neo.CommunicationChannel) {
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/memory"
+	"github.com/minskylab/neocortex/cognitive/rules"
+	"github.com/minskylab/neocortex/harness"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func TestTranscripts(t *testing.T) {
+	bot, err := rules.NewCognitiveFromFile("skill.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := memory.NewChannel()
+	engine, err := neo.New(bot, []neo.CommunicationChannel{ch}, neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerResolvers(engine, ch)
+
+	harness.Test(t, ch, "transcripts/*.yaml")
+}
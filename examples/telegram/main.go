@@ -0,0 +1,32 @@
+package main
+
+import (
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/telegram"
+	"github.com/minskylab/neocortex/cognitive/uselessbox"
+)
+
+func main() {
+	box := uselessbox.NewCognitive()
+
+	tg, err := telegram.NewChannel(telegram.ChannelOptions{
+		Token: "<Your BOT_TOKEN>",
+		// WebhookURL: "https://example.com/telegram-channel", // without webhook the channel uses long polling
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	engine, err := neo.Default(nil, box, tg)
+	if err != nil {
+		panic(err)
+	}
+
+	engine.ResolveAny(tg, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+
+	if err := engine.Run(); err != nil {
+		panic(err)
+	}
+}
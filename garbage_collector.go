@@ -7,9 +7,9 @@ type garbageCollector struct {
 	maxLastResponse time.Duration
 }
 
-func defaultGarbageCollector(maxSessiontime time.Duration) *garbageCollector {
+func newGarbageCollector(tick, maxSessiontime time.Duration) *garbageCollector {
 	return &garbageCollector{
-		tickTime:        1 * time.Second,
+		tickTime:        tick,
 		maxLastResponse: maxSessiontime,
 	}
 }
@@ -18,10 +18,8 @@ func (engine *Engine) runGarbageCollector(g *garbageCollector) {
 	ticker := time.NewTicker(g.tickTime)
 	go func() {
 		for t := range ticker.C {
-			for c, diag := range engine.ActiveDialogs {
-				if t.Sub(diag.LastActivity) > g.maxLastResponse {
-					engine.onContextIsDone(c)
-				}
+			for _, c := range engine.Sessions.expired(t, g.maxLastResponse) {
+				engine.expireContext(c, g.maxLastResponse)
 			}
 		}
 	}()
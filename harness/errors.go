@@ -0,0 +1,5 @@
+package harness
+
+import "errors"
+
+var ErrNoTranscripts = errors.New("no transcripts found")
@@ -0,0 +1,56 @@
+package harness
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/minskylab/neocortex/channels/memory"
+)
+
+// Main is a command line runner, the bots can build their own runner with their resolvers:
+//
+//	func main() {
+//		ch := memory.NewChannel()
+//		engine, _ := neo.Default(nil, cognitive, ch)
+//		registerResolvers(engine, ch)
+//		harness.Main(ch)
+//	}
+//
+// the arguments are the transcript files (or glob patterns), it exits with 1 if a transcript fails
+func Main(ch *memory.Channel) {
+	os.Exit(run(ch, os.Args[1:]))
+}
+
+func run(ch *memory.Channel, args []string) int {
+	flags := flag.NewFlagSet("harness", flag.ContinueOnError)
+	quiet := flags.Bool("q", false, "only print the failed transcripts")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: [-q] transcript.yaml [transcripts/*.yaml ...]")
+		return 2
+	}
+
+	transcripts, err := LoadTranscripts(flags.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	report := RunAll(ch, transcripts)
+	for _, result := range report.Results {
+		if *quiet && result.Passed() {
+			continue
+		}
+		fmt.Print(result.String())
+	}
+	fmt.Print(report.Summary())
+
+	if !report.Passed() {
+		return 1
+	}
+	return 0
+}
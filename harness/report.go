@@ -0,0 +1,100 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+)
+
+type StepResult struct {
+	Index int
+	Say   string
+	Got   []string
+	Diffs []string
+}
+
+func (s *StepResult) Passed() bool {
+	return len(s.Diffs) == 0
+}
+
+type Result struct {
+	Transcript string
+	File       string
+	Error      string
+	Steps      []*StepResult
+}
+
+func (r *Result) Passed() bool {
+	if r.Error != "" {
+		return false
+	}
+	for _, s := range r.Steps {
+		if !s.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the failed steps with the expected and the received responses
+func (r *Result) String() string {
+	b := new(strings.Builder)
+	if r.Passed() {
+		fmt.Fprintf(b, "PASS %s\n", r.Transcript)
+		return b.String()
+	}
+
+	fmt.Fprintf(b, "FAIL %s", r.Transcript)
+	if r.File != "" {
+		fmt.Fprintf(b, " (%s)", r.File)
+	}
+	b.WriteString("\n")
+
+	if r.Error != "" {
+		fmt.Fprintf(b, "    error: %s\n", r.Error)
+	}
+
+	for _, s := range r.Steps {
+		if s.Passed() {
+			continue
+		}
+		fmt.Fprintf(b, "    step %d, say %q\n", s.Index, s.Say)
+		for _, d := range s.Diffs {
+			fmt.Fprintf(b, "        - %s\n", d)
+		}
+		fmt.Fprintf(b, "        got texts %q\n", s.Got)
+	}
+	return b.String()
+}
+
+type Report struct {
+	Results []*Result
+}
+
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary counts the passed and failed transcripts
+func (r *Report) Summary() string {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d transcripts, %d passed, %d failed\n", len(r.Results), len(r.Results)-failed, failed)
+}
+
+func (r *Report) String() string {
+	b := new(strings.Builder)
+	for _, result := range r.Results {
+		b.WriteString(result.String())
+	}
+	b.WriteString(r.Summary())
+	return b.String()
+}
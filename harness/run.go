@@ -0,0 +1,176 @@
+// Package harness runs scripted transcripts through an engine using the in-memory channel
+// and reports the differences between the expected and the real responses
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/minskylab/neocortex/channels/memory"
+	"github.com/rs/xid"
+)
+
+// Run plays the transcript, every run uses a new person id so the transcripts don't share sessions
+func Run(ch *memory.Channel, t *Transcript) *Result {
+	result := &Result{Transcript: t.Name, File: t.file, Steps: make([]*StepResult, 0, len(t.Steps))}
+
+	person := t.Person
+	person.ID = person.ID + "-" + xid.New().String()
+	defer ch.End(person.ID)
+
+	if len(t.Variables) > 0 {
+		c, err := ch.Open(person)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if c.Variables == nil {
+			c.Variables = map[string]interface{}{}
+		}
+		for k, v := range t.Variables {
+			c.Variables[k] = v
+		}
+	}
+
+	for i, step := range t.Steps {
+		exchange, err := ch.Say(person, step.Say)
+		sr := &StepResult{Index: i + 1, Say: step.Say}
+		result.Steps = append(result.Steps, sr)
+
+		if err != nil {
+			sr.Diffs = append(sr.Diffs, "engine error: "+err.Error())
+			continue
+		}
+
+		sr.Got = exchange.Texts()
+		sr.Diffs = check(step.Expect, exchange)
+	}
+
+	return result
+}
+
+// RunAll plays all the transcripts
+func RunAll(ch *memory.Channel, transcripts []*Transcript) *Report {
+	report := &Report{Results: make([]*Result, 0, len(transcripts))}
+	for _, t := range transcripts {
+		report.Results = append(report.Results, Run(ch, t))
+	}
+	return report
+}
+
+func check(expect Expect, exchange *memory.Exchange) []string {
+	diffs := make([]string, 0)
+	texts := exchange.Texts()
+
+	if expect.NoResponse && len(exchange.Responses()) > 0 {
+		diffs = append(diffs, fmt.Sprintf("expected no response, got %d responses", len(exchange.Responses())))
+	}
+
+	if expect.Text != "" && !anyText(texts, func(t string) bool { return t == expect.Text }) {
+		diffs = append(diffs, fmt.Sprintf("expected text %q", expect.Text))
+	}
+
+	if expect.TextContains != "" && !anyText(texts, func(t string) bool { return strings.Contains(t, expect.TextContains) }) {
+		diffs = append(diffs, fmt.Sprintf("expected a text containing %q", expect.TextContains))
+	}
+
+	if expect.TextMatches != "" {
+		r, err := regexp.Compile(expect.TextMatches)
+		if err != nil {
+			diffs = append(diffs, "invalid text_matches: "+err.Error())
+		} else if !anyText(texts, r.MatchString) {
+			diffs = append(diffs, fmt.Sprintf("expected a text matching %q", expect.TextMatches))
+		}
+	}
+
+	if expect.Texts != nil && !equalTexts(expect.Texts, texts) {
+		diffs = append(diffs, fmt.Sprintf("expected texts %q", expect.Texts))
+	}
+
+	if expect.Intent != "" {
+		intents := outputIntents(exchange)
+		if len(intents) == 0 || intents[0] != expect.Intent {
+			diffs = append(diffs, fmt.Sprintf("expected intent %q, got %q", expect.Intent, intents))
+		}
+	}
+
+	for name, value := range expect.Entities {
+		if !hasEntity(exchange, name, value) {
+			diffs = append(diffs, fmt.Sprintf("expected entity %s=%q", name, value))
+		}
+	}
+
+	for name, value := range expect.Variables {
+		got, exist := exchange.Context.Variables[name]
+		if !exist {
+			diffs = append(diffs, fmt.Sprintf("expected context variable %s=%s, it doesn't exist", name, canonical(value)))
+			continue
+		}
+		if canonical(got) != canonical(value) {
+			diffs = append(diffs, fmt.Sprintf("expected context variable %s=%s, got %s", name, canonical(value), canonical(got)))
+		}
+	}
+
+	if expect.Ended != nil && *expect.Ended != exchange.Ended {
+		diffs = append(diffs, fmt.Sprintf("expected ended=%t, got ended=%t", *expect.Ended, exchange.Ended))
+	}
+
+	return diffs
+}
+
+func anyText(texts []string, f func(t string) bool) bool {
+	for _, t := range texts {
+		if f(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalTexts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// outputIntents returns the intents of the first output that has intents
+func outputIntents(exchange *memory.Exchange) []string {
+	intents := make([]string, 0)
+	for _, out := range exchange.Outputs {
+		for _, i := range out.Intents {
+			intents = append(intents, i.Intent)
+		}
+		if len(intents) > 0 {
+			break
+		}
+	}
+	return intents
+}
+
+func hasEntity(exchange *memory.Exchange, name, value string) bool {
+	for _, out := range exchange.Outputs {
+		for _, e := range out.Entities {
+			if e.Entity == name && (value == "" || e.Value == value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// canonical compares the values as json, so 1 and 1.0 are equal
+func canonical(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,34 @@
+package harness
+
+import (
+	"testing"
+
+	"github.com/minskylab/neocortex/channels/memory"
+)
+
+// Test runs the transcripts of the files (or glob patterns) as subtests, e.g.
+//
+//	func TestBot(t *testing.T) {
+//		ch := memory.NewChannel()
+//		engine, _ := neo.Default(nil, cognitive, ch)
+//		registerResolvers(engine, ch)
+//		harness.Test(t, ch, "testdata/*.yaml")
+//	}
+func Test(t *testing.T, ch *memory.Channel, patterns ...string) {
+	t.Helper()
+
+	transcripts, err := LoadTranscripts(patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, transcript := range transcripts {
+		transcript := transcript
+		t.Run(transcript.Name, func(t *testing.T) {
+			result := Run(ch, transcript)
+			if !result.Passed() {
+				t.Error("\n" + result.String())
+			}
+		})
+	}
+}
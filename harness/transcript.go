@@ -0,0 +1,129 @@
+package harness
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	neo "github.com/minskylab/neocortex"
+	"gopkg.in/yaml.v2"
+)
+
+// Transcript is a scripted conversation, e.g.
+//
+//	name: order a pizza
+//	person: {name: Ana, locale: en}
+//	steps:
+//	  - say: hello
+//	    expect: {intent: greeting, text_matches: "^Hi Ana"}
+//	  - say: a big pizza
+//	    expect: {entities: {size: large}, variables: {step: ordering}}
+type Transcript struct {
+	Name      string                 `yaml:"name"`
+	Person    neo.PersonInfo         `yaml:"person"`
+	Variables map[string]interface{} `yaml:"variables"`
+	Steps     []Step                 `yaml:"steps"`
+	file      string
+}
+
+type Step struct {
+	Say    string `yaml:"say"`
+	Expect Expect `yaml:"expect"`
+}
+
+// Expect are the checks of a step, the empty fields are not checked
+type Expect struct {
+	// Text must be equal to one of the text responses
+	Text string `yaml:"text"`
+	// TextMatches is a regular expression, one of the text responses must match it
+	TextMatches string `yaml:"text_matches"`
+	// TextContains must be into one of the text responses
+	TextContains string `yaml:"text_contains"`
+	// Texts must be equal to all the text responses, in order
+	Texts      []string               `yaml:"texts"`
+	Intent     string                 `yaml:"intent"`
+	Entities   map[string]string      `yaml:"entities"`
+	Variables  map[string]interface{} `yaml:"variables"`
+	NoResponse bool                   `yaml:"no_response"`
+	Ended      *bool                  `yaml:"ended"`
+}
+
+// LoadTranscript reads a yaml file with one transcript
+func LoadTranscript(path string) (*Transcript, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := new(Transcript)
+	if err = yaml.UnmarshalStrict(data, t); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if t.Name == "" {
+		t.Name = filepath.Base(path)
+	}
+	t.file = path
+	t.Variables = normalizeMap(t.Variables)
+	for i := range t.Steps {
+		t.Steps[i].Expect.Variables = normalizeMap(t.Steps[i].Expect.Variables)
+	}
+
+	return t, nil
+}
+
+// LoadTranscripts reads the transcripts of the files or glob patterns
+func LoadTranscripts(patterns ...string) ([]*Transcript, error) {
+	transcripts := make([]*Transcript, 0)
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("%s: %w", pattern, ErrNoTranscripts)
+		}
+
+		for _, file := range files {
+			t, err := LoadTranscript(file)
+			if err != nil {
+				return nil, err
+			}
+			transcripts = append(transcripts, t)
+		}
+	}
+	return transcripts, nil
+}
+
+// normalizeMap converts the maps decoded by yaml (map[interface{}]interface{}) into json like maps
+func normalizeMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	normalized := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		normalized[k] = normalizeValue(v)
+	}
+	return normalized
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			m[fmt.Sprint(k)] = normalizeValue(item)
+		}
+		return m
+	case map[string]interface{}:
+		return normalizeMap(value)
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			list[i] = normalizeValue(item)
+		}
+		return list
+	default:
+		return v
+	}
+}
@@ -2,6 +2,20 @@ package neocortex
 
 type InInjection func(c *Context, in *Input) *Input
 
+type injection struct {
+	registration
+	middle InInjection
+}
+
+func insertInjection(injections []*injection, inj *injection) []*injection {
+	at := func(i int) registration { return injections[i].registration }
+	replaced, i := insertionPoint(len(injections), at, inj.registration)
+	if replaced >= 0 {
+		injections = append(injections[:replaced], injections[replaced+1:]...)
+	}
+	return append(injections[:i], append([]*injection{inj}, injections[i:]...)...)
+}
+
 func (engine *Engine) InjectAll(channel CommunicationChannel, middle InInjection) {
 	if engine.generalInjection == nil {
 		engine.generalInjection = map[CommunicationChannel]*InInjection{}
@@ -9,14 +23,21 @@ func (engine *Engine) InjectAll(channel CommunicationChannel, middle InInjection
 	engine.generalInjection[channel] = &middle
 }
 
-func (engine *Engine) Inject(channel CommunicationChannel, matcher *Matcher, middle InInjection) {
-	if engine.registeredInjection == nil {
-		engine.registeredInjection = map[CommunicationChannel]map[*Matcher]*InInjection{}
+// Inject registers a middleware for the inputs that match with the matcher, all the matched injections
+// are chained by priority (by default 0), injections with higher priority are executed first. Injecting the
+// same matcher again replaces its injection
+func (engine *Engine) Inject(channel CommunicationChannel, matcher *Matcher, middle InInjection, priority ...int) {
+	p := 0
+	if len(priority) > 0 {
+		p = priority[0]
 	}
 
-	if engine.registeredInjection[channel] == nil {
-		engine.registeredInjection[channel] = map[*Matcher]*InInjection{}
+	if engine.registeredInjection == nil {
+		engine.registeredInjection = map[CommunicationChannel][]*injection{}
 	}
 
-	engine.registeredInjection[channel][matcher] = &middle
+	engine.registeredInjection[channel] = insertInjection(engine.registeredInjection[channel], &injection{
+		registration: registration{matcher: matcher, priority: p},
+		middle:       middle,
+	})
 }
@@ -0,0 +1,91 @@
+package neocortex
+
+// OutInjection transforms an output before it is sent to the channel,
+// if it returns nil the output is discarded (e.g. to throttle the responses)
+type OutInjection func(c *Context, in *Input, out *Output) *Output
+
+type outInjection struct {
+	registration
+	middle OutInjection
+}
+
+func insertOutInjection(injections []*outInjection, inj *outInjection) []*outInjection {
+	at := func(i int) registration { return injections[i].registration }
+	replaced, i := insertionPoint(len(injections), at, inj.registration)
+	if replaced >= 0 {
+		injections = append(injections[:replaced], injections[replaced+1:]...)
+	}
+	return append(injections[:i], append([]*outInjection{inj}, injections[i:]...)...)
+}
+
+// InjectOut registers a middleware for the outputs that match with the matcher, it is executed between
+// the resolver and the channel. If channel is nil the middleware is applied to all the channels of the engine.
+// All the matched middlewares are chained by priority (by default 0), higher priority first. Injecting the same
+// matcher again replaces its middleware, the middlewares without matcher (see InjectOutAll) are always added
+func (engine *Engine) InjectOut(channel CommunicationChannel, matcher *Matcher, middle OutInjection, priority ...int) {
+	p := 0
+	if len(priority) > 0 {
+		p = priority[0]
+	}
+
+	if engine.registeredOutInjection == nil {
+		engine.registeredOutInjection = map[CommunicationChannel][]*outInjection{}
+	}
+
+	engine.registeredOutInjection[channel] = insertOutInjection(engine.registeredOutInjection[channel], &outInjection{
+		registration: registration{matcher: matcher, priority: p},
+		middle:       middle,
+	})
+}
+
+// InjectOutAll registers a middleware for all the outputs of the channel (or of all channels if it's nil)
+func (engine *Engine) InjectOutAll(channel CommunicationChannel, middle OutInjection, priority ...int) {
+	engine.InjectOut(channel, nil, middle, priority...)
+}
+
+// outInjectionsFor merges the engine-wide and the channel middlewares by priority
+func (engine *Engine) outInjectionsFor(channel CommunicationChannel) []*outInjection {
+	global := engine.registeredOutInjection[nil]
+	own := engine.registeredOutInjection[channel]
+	if channel == nil {
+		return global
+	}
+
+	merged := make([]*outInjection, 0, len(global)+len(own))
+	i, j := 0, 0
+	for i < len(global) && j < len(own) {
+		if global[i].priority >= own[j].priority {
+			merged = append(merged, global[i])
+			i++
+		} else {
+			merged = append(merged, own[j])
+			j++
+		}
+	}
+	merged = append(merged, global[i:]...)
+	return append(merged, own[j:]...)
+}
+
+// outboundResponse wraps the response of the channel with the registered middlewares
+func (engine *Engine) outboundResponse(channel CommunicationChannel, in *Input, response OutputResponse) OutputResponse {
+	injections := engine.outInjectionsFor(channel)
+	if len(injections) == 0 {
+		return response
+	}
+
+	return func(c *Context, out *Output) error {
+		// the middlewares work over a copy, the dialog keeps the output given by the resolver
+		out = out.Copy()
+		for _, inj := range injections {
+			if inj.matcher != nil && !inj.matcher.Matches(c, in, out) {
+				continue
+			}
+
+			out = inj.middle(c, in, out)
+			if out == nil {
+				return nil
+			}
+		}
+		return response(c, out)
+	}
+}
@@ -0,0 +1,41 @@
+package neocortex_test
+
+import (
+	"testing"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+func TestInjectOutDoesNotChangeTheRecordedOutput(t *testing.T) {
+	engine, ch := newUselessEngine(t, 0)
+
+	engine.InjectOutAll(nil, func(c *neo.Context, in *neo.Input, out *neo.Output) *neo.Output {
+		// the middleware changes the output in place
+		out.Responses[0].Value = "[bot] " + out.Responses[0].Value.(string)
+		return out.AddTextResponse("watermark")
+	})
+
+	person := neo.PersonInfo{ID: "watermarked"}
+	exchange, err := ch.Say(person, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := exchange.Texts()
+	if len(texts) != 2 || texts[0] != "[bot] I'm useless, you don't wait more from me" || texts[1] != "watermark" {
+		t.Fatalf("the channel must receive the transformed output, got %q", texts)
+	}
+
+	dialog, ok := engine.Sessions.Dialog(exchange.Context)
+	if !ok {
+		t.Fatal("the dialog must be active")
+	}
+	if len(dialog.Outs) != 1 {
+		t.Fatalf("expected 1 output, got %d", len(dialog.Outs))
+	}
+
+	recorded := dialog.Outs[0].Output.Responses
+	if len(recorded) != 1 || recorded[0].Value != "I'm useless, you don't wait more from me" {
+		t.Fatalf("the dialog must keep the output of the resolver, got %+v", recorded)
+	}
+}
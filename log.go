@@ -1,8 +1,23 @@
 package neocortex
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
 // LogLevelType is level of the logs
 type LogLevelType string
 
+// Debug is a level of log
+var Debug LogLevelType = "debug"
+
 // Info is a level of log
 var Info LogLevelType = "info"
 
@@ -17,3 +32,171 @@ type LogMessage struct {
 	Level   LogLevelType `json:"level"`
 	Message string       `json:"message"`
 }
+
+// severity orders the levels, the unknown levels are taken as info
+func (level LogLevelType) severity() int {
+	switch level {
+	case Debug:
+		return 0
+	case Warn:
+		return 2
+	case Error:
+		return 3
+	}
+	return 1
+}
+
+// ParseLogLevel returns the level with that name (debug, info, warn or error)
+func ParseLogLevel(name string) (LogLevelType, error) {
+	for _, level := range []LogLevelType{Debug, Info, Warn, Error} {
+		if strings.EqualFold(name, string(level)) {
+			return level, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown log level %q", ErrInvalidConfiguration, name)
+}
+
+// The keys of the fields that the engine adds to its logs
+const (
+	SessionIDField = "session_id"
+	PersonIDField  = "person_id"
+	ChannelField   = "channel"
+	DialogIDField  = "dialog_id"
+	ErrorField     = "error"
+)
+
+// Fields are the structured values of a log line
+type Fields map[string]interface{}
+
+// Logger receives the structured logs of the engine, its channels and its cognitive service,
+// it must be safe for concurrent use
+type Logger interface {
+	Log(level LogLevelType, message string, fields Fields)
+}
+
+// LoggerSetter is implemented by the channels and the cognitive services that log through the logger of
+// the engine, New sets it (with the channel field for the channels)
+type LoggerSetter interface {
+	SetLogger(logger Logger)
+}
+
+// LogFormat is the encoding of the lines of the loggers created by NewLogger
+type LogFormat string
+
+// TextFormat writes lines like: time=... level=info msg="creating new context" session_id=...
+const TextFormat LogFormat = "text"
+
+// JSONFormat writes a JSON object per line
+const JSONFormat LogFormat = "json"
+
+type writerLogger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format LogFormat
+}
+
+// NewLogger returns a logger that writes into out with the format, the fields are sorted by its key
+func NewLogger(out io.Writer, format LogFormat) Logger {
+	return &writerLogger{out: out, format: format}
+}
+
+var standardLogger = NewLogger(os.Stderr, TextFormat)
+
+// StandardLogger returns the text logger over the standard error, it's the logger by default
+func StandardLogger() Logger {
+	return standardLogger
+}
+
+// defaultLogLevel is the level of the engine by default
+var defaultLogLevel = Info
+
+// DefaultLogger returns the standard logger filtered by the default level of the engine, the channels
+// and the cognitive services log into it until the engine sets its own logger (see LoggerSetter)
+func DefaultLogger() Logger {
+	return LevelLogger(standardLogger, defaultLogLevel)
+}
+
+func (l *writerLogger) Log(level LogLevelType, message string, fields Fields) {
+	at := time.Now().UTC().Format(time.RFC3339Nano)
+
+	var line []byte
+	if l.format == JSONFormat {
+		entry := make(map[string]interface{}, len(fields)+3)
+		for key, value := range fields {
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			entry[key] = value
+		}
+		entry["time"] = at
+		entry["level"] = level
+		entry["msg"] = message
+
+		var err error
+		if line, err = json.Marshal(entry); err != nil {
+			line = []byte(fmt.Sprintf(`{"time":%q,"level":"error","msg":"can't encode the log line","error":%q}`, at, err))
+		}
+	} else {
+		var b strings.Builder
+		b.WriteString("time=" + at + " level=" + string(level) + " msg=" + logfmtValue(message))
+
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			b.WriteString(" " + key + "=" + logfmtValue(fmt.Sprint(fields[key])))
+		}
+		line = []byte(b.String())
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.out.Write(append(line, '\n'))
+}
+
+// logfmtValue quotes the value if it has spaces, quotes or equal signs
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \"=\t\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+type levelLogger struct {
+	next Logger
+	min  LogLevelType
+}
+
+// LevelLogger drops the logs under the level min
+func LevelLogger(logger Logger, min LogLevelType) Logger {
+	return &levelLogger{next: logger, min: min}
+}
+
+func (l *levelLogger) Log(level LogLevelType, message string, fields Fields) {
+	if level.severity() >= l.min.severity() {
+		l.next.Log(level, message, fields)
+	}
+}
+
+type fieldsLogger struct {
+	next   Logger
+	fields Fields
+}
+
+// AddFields returns a logger that adds the fields to every log, the fields of each log take precedence
+func AddFields(logger Logger, fields Fields) Logger {
+	return &fieldsLogger{next: logger, fields: fields}
+}
+
+func (l *fieldsLogger) Log(level LogLevelType, message string, fields Fields) {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	l.next.Log(level, message, merged)
+}
@@ -1,13 +1,22 @@
 package neocortex
 
+import (
+	"regexp"
+	"strings"
+)
+
+// Match checks an intent or an entity, Value is only used by the entities (empty means any value)
 type Match struct {
 	Is         string
 	Confidence float64
+	Value      string
 }
 
+// CMatch compares a context variable against a value, by default the operator is Equal
 type CMatch struct {
-	Name  string
-	Value interface{}
+	Name     string
+	Value    interface{}
+	Operator CompareOperator
 }
 
 type DialogNodeMatch struct {
@@ -15,40 +24,78 @@ type DialogNodeMatch struct {
 	Name  string
 }
 
+// TextMatch checks the raw value of the input, all the non empty fields must be satisfied
+type TextMatch struct {
+	Regex    *regexp.Regexp
+	Contains string
+	Prefix   string
+}
+
 type Matcher struct {
 	DialogNode      DialogNodeMatch
 	Entity          Match
 	Intent          Match
 	ContextVariable CMatch
+	Text            TextMatch
+	InputType       InputType
+	Group           *Matcher // evaluated as one predicate, like a parenthesis
+	NOT             *Matcher // the negated matcher must not match, it's AND-ed with the other predicates
 	AND             *Matcher
 	OR              *Matcher
 }
 
+// Match evaluates the matcher with the output and its input (see Output.Input), without input the
+// Text and InputType predicates never match
 func (out *Output) Match(c *Context, matcher *Matcher) bool {
+	return matcher.Matches(c, out.Input, out)
+}
+
+func (in *Input) Match(c *Context, matcher *Matcher) bool {
+	return matcher.Matches(c, in, nil)
+}
+
+// Matches evaluates the matcher, the input and the output can be nil. Intents and entities are
+// taken from the output if exists (otherwise from the input), text and input type from the input
+// and the dialog nodes from the output.
+func (matcher *Matcher) Matches(c *Context, in *Input, out *Output) bool {
+	var intents []Intent
+	var entities []Entity
+	if out != nil {
+		intents, entities = out.Intents, out.Entities
+	} else if in != nil {
+		intents, entities = in.Intents, in.Entities
+	}
+
 	ok := false
-	for _, i := range out.Intents {
+	for _, i := range intents {
 		if i.Intent == matcher.Intent.Is && i.Confidence > matcher.Intent.Confidence {
 			ok = true
 		}
 	}
 
-	for _, e := range out.Entities {
+	for _, e := range entities {
 		if e.Entity == matcher.Entity.Is && e.Confidence > matcher.Entity.Confidence {
-			ok = true
+			if matcher.Entity.Value == "" || matcher.Entity.Value == e.Value {
+				ok = true
+			}
 		}
 	}
 
-	if c.Variables != nil {
-		for varName, varValue := range c.Variables {
-			if matcher.ContextVariable.Name == varName {
-				if matcher.ContextVariable.Value == varValue {
-					ok = true
-				}
-			}
+	if matchContextVariable(c, matcher.ContextVariable) {
+		ok = true
+	}
+
+	if in != nil {
+		if matchText(in.Data.Value, matcher.Text) {
+			ok = true
+		}
+
+		if matcher.InputType != "" && in.Data.Type == matcher.InputType {
+			ok = true
 		}
 	}
 
-	if matcher.DialogNode.Title != "" || matcher.DialogNode.Name != "" {
+	if out != nil && (matcher.DialogNode.Title != "" || matcher.DialogNode.Name != "") {
 		for _, n := range out.VisitedNodes {
 			if matcher.DialogNode.Name != "" {
 				if matcher.DialogNode.Title != "" {
@@ -68,8 +115,17 @@ func (out *Output) Match(c *Context, matcher *Matcher) bool {
 		}
 	}
 
+	if matcher.Group != nil && matcher.Group.Matches(c, in, out) {
+		ok = true
+	}
+
+	if matcher.NOT != nil {
+		// alone the negation is the whole matcher, e.g. Not(m), with other predicates it's AND-ed to them
+		ok = (ok || !matcher.hasPredicates()) && !matcher.NOT.Matches(c, in, out)
+	}
+
 	if matcher.AND != nil {
-		if out.Match(c, matcher.AND) && ok {
+		if matcher.AND.Matches(c, in, out) && ok {
 			ok = true
 		} else {
 			ok = false
@@ -77,7 +133,7 @@ func (out *Output) Match(c *Context, matcher *Matcher) bool {
 	}
 
 	if matcher.OR != nil {
-		if out.Match(c, matcher.OR) || ok {
+		if matcher.OR.Matches(c, in, out) || ok {
 			ok = true
 		} else {
 			ok = false
@@ -87,46 +143,33 @@ func (out *Output) Match(c *Context, matcher *Matcher) bool {
 	return ok
 }
 
-func (in *Input) Match(c *Context, matcher *Matcher) bool {
-	ok := false
-	for _, i := range in.Intents {
-		if i.Intent == matcher.Intent.Is && i.Confidence > matcher.Intent.Confidence {
-			ok = true
-		}
-	}
+// hasPredicates returns true if the matcher defines something to match besides NOT, AND and OR
+func (matcher *Matcher) hasPredicates() bool {
+	return matcher.DialogNode != (DialogNodeMatch{}) ||
+		matcher.Entity.Is != "" ||
+		matcher.Intent.Is != "" ||
+		matcher.ContextVariable.Name != "" ||
+		matcher.Text.Regex != nil || matcher.Text.Contains != "" || matcher.Text.Prefix != "" ||
+		matcher.InputType != "" ||
+		matcher.Group != nil
+}
 
-	for _, e := range in.Entities {
-		if e.Entity == matcher.Entity.Is && e.Confidence > matcher.Entity.Confidence {
-			ok = true
-		}
+func matchText(value string, tm TextMatch) bool {
+	if tm.Regex == nil && tm.Contains == "" && tm.Prefix == "" {
+		return false
 	}
 
-	if c.Variables != nil {
-		for varName, varValue := range c.Variables {
-			if matcher.ContextVariable.Name == varName {
-				if matcher.ContextVariable.Value == varValue {
-					ok = true
-				}
-			}
-		}
+	if tm.Regex != nil && !tm.Regex.MatchString(value) {
+		return false
 	}
 
-	if matcher.AND != nil {
-		if in.Match(c, matcher.AND) && ok {
-			ok = true
-		} else {
-			ok = false
-		}
+	if tm.Contains != "" && !strings.Contains(value, tm.Contains) {
+		return false
 	}
 
-	if matcher.OR != nil {
-		if in.Match(c, matcher.OR) || ok {
-			ok = true
-		} else {
-			ok = false
-		}
+	if tm.Prefix != "" && !strings.HasPrefix(value, tm.Prefix) {
+		return false
 	}
 
-	return ok
-
+	return true
 }
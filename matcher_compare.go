@@ -0,0 +1,164 @@
+package neocortex
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// CompareOperator defines how a context variable is compared with a value
+type CompareOperator string
+
+// Equal is the default operator of a context variable comparison
+const Equal CompareOperator = "=="
+
+// NotEqual is true when the context variable exists and it's different of the value
+const NotEqual CompareOperator = "!="
+
+// Greater compares numbers or strings
+const Greater CompareOperator = ">"
+
+// GreaterOrEqual compares numbers or strings
+const GreaterOrEqual CompareOperator = ">="
+
+// Less compares numbers or strings
+const Less CompareOperator = "<"
+
+// LessOrEqual compares numbers or strings
+const LessOrEqual CompareOperator = "<="
+
+// Exists ignores the value and only checks that the context variable is defined
+const Exists CompareOperator = "exists"
+
+// In checks that the context variable is equal to one of the values, the value must be a slice
+const In CompareOperator = "in"
+
+func matchContextVariable(c *Context, cm CMatch) bool {
+	if c == nil || c.Variables == nil || cm.Name == "" {
+		return false
+	}
+
+	value, exists := c.Variables[cm.Name]
+	if !exists {
+		return false
+	}
+
+	switch cm.Operator {
+	case "", Equal:
+		return equalValues(value, cm.Value)
+	case NotEqual:
+		return !equalValues(value, cm.Value)
+	case Exists:
+		return true
+	case In:
+		values := reflect.ValueOf(cm.Value)
+		if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+			return false
+		}
+		for i := 0; i < values.Len(); i++ {
+			if equalValues(value, values.Index(i).Interface()) {
+				return true
+			}
+		}
+		return false
+	case Greater, GreaterOrEqual, Less, LessOrEqual:
+		cmp, comparable := compareValues(value, cm.Value)
+		if !comparable {
+			return false
+		}
+		switch cm.Operator {
+		case Greater:
+			return cmp > 0
+		case GreaterOrEqual:
+			return cmp >= 0
+		case Less:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+
+	return false
+}
+
+// toFloat64 converts any numeric value into a float64, the strings are not converted
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func equalValues(a, b interface{}) bool {
+	fa, aIsNumber := toFloat64(a)
+	fb, bIsNumber := toFloat64(b)
+	if aIsNumber && bIsNumber {
+		return fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues returns -1, 0 or 1, the second value is false if the values can't be compared
+func compareValues(a, b interface{}) (int, bool) {
+	fa, aIsNumber := toFloat64(a)
+	fb, bIsNumber := toFloat64(b)
+
+	// context variables coming from cognitive services are usually strings
+	if aIsNumber && !bIsNumber {
+		if s, ok := b.(string); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			fb, bIsNumber = f, err == nil
+		}
+	} else if !aIsNumber && bIsNumber {
+		if s, ok := a.(string); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			fa, aIsNumber = f, err == nil
+		}
+	}
+
+	if aIsNumber && bIsNumber {
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	sa, aIsString := a.(string)
+	sb, bIsString := b.(string)
+	if aIsString && bIsString {
+		switch {
+		case sa < sb:
+			return -1, true
+		case sa > sb:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,471 @@
+package neocortex
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MatcherSyntaxError describes a problem found parsing a matcher expression
+type MatcherSyntaxError struct {
+	Expression string
+	Position   int
+	Message    string
+}
+
+func (err *MatcherSyntaxError) Error() string {
+	return fmt.Sprintf("invalid matcher expression at position %d: %s\n\t%s\n\t%s^",
+		err.Position, err.Message, err.Expression, strings.Repeat(" ", err.Position))
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenVariable
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+var expressionOperators = []string{"&&", "||", "==", "!=", ">=", "<=", ">", "<", "!"}
+
+func tokenize(expr string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(expr)
+
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case r == '"':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &MatcherSyntaxError{Expression: expr, Position: start, Message: "unterminated string"}
+			}
+			i++
+			text := string(runes[start:i])
+			value, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, &MatcherSyntaxError{Expression: expr, Position: start, Message: "invalid string " + text}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, value: value, pos: start})
+		case r == '$':
+			start := i
+			i++
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			if i == start+1 {
+				return nil, &MatcherSyntaxError{Expression: expr, Position: start, Message: "expected a variable name after '$'"}
+			}
+			tokens = append(tokens, token{kind: tokenVariable, text: string(runes[start:i]), value: string(runes[start+1 : i]), pos: start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &MatcherSyntaxError{Expression: expr, Position: start, Message: "invalid number " + text}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: start})
+		case isIdentRune(r):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			found := false
+			for _, op := range expressionOperators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len([]rune(op))
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, &MatcherSyntaxError{Expression: expr, Position: i, Message: fmt.Sprintf("unexpected character '%c'", r)}
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type matcherParser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func (p *matcherParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *matcherParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *matcherParser) errorf(t token, format string, args ...interface{}) error {
+	return &MatcherSyntaxError{Expression: p.expr, Position: t.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *matcherParser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.errorf(t, "expected %s but found %s", what, t)
+	}
+	return t, nil
+}
+
+// or := and ( "||" and )*
+func (p *matcherParser) parseOr() (*Matcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOperator && p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Matcher{Group: left, OR: right}
+	}
+
+	return left, nil
+}
+
+// and := unary ( "&&" unary )*
+func (p *matcherParser) parseAnd() (*Matcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOperator && p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Matcher{Group: left, AND: right}
+	}
+
+	return left, nil
+}
+
+// unary := "!" unary | primary
+func (p *matcherParser) parseUnary() (*Matcher, error) {
+	if t := p.peek(); t.kind == tokenOperator && t.text == "!" {
+		p.next()
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Matcher{NOT: m}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// primary := "(" or ")" | call [ ">" number ] | variable [ operator literal | "in" list ]
+func (p *matcherParser) parsePrimary() (*Matcher, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLeftParen:
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, "')'"); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case tokenIdent:
+		return p.parseCall(t)
+	case tokenVariable:
+		return p.parseVariable(t)
+	}
+
+	return nil, p.errorf(t, "expected a function, a $variable or '(' but found %s", t)
+}
+
+func (p *matcherParser) parseArguments() ([]string, error) {
+	if _, err := p.expect(tokenLeftParen, "'('"); err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0)
+	for {
+		t, err := p.expect(tokenString, "a string argument")
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, t.value.(string))
+
+		t = p.next()
+		if t.kind == tokenRightParen {
+			return args, nil
+		}
+		if t.kind != tokenComma {
+			return nil, p.errorf(t, "expected ',' or ')' but found %s", t)
+		}
+	}
+}
+
+func (p *matcherParser) parseConfidence() (float64, error) {
+	t := p.peek()
+	if t.kind != tokenOperator || t.text == "&&" || t.text == "||" || t.text == "!" {
+		return 0, nil
+	}
+
+	p.next()
+	if t.text != ">" {
+		return 0, p.errorf(t, "the confidence only can be compared with '>', found %s", t)
+	}
+
+	n, err := p.expect(tokenNumber, "a confidence number")
+	if err != nil {
+		return 0, err
+	}
+
+	return n.value.(float64), nil
+}
+
+func (p *matcherParser) parseCall(name token) (*Matcher, error) {
+	args, err := p.parseArguments()
+	if err != nil {
+		return nil, err
+	}
+
+	switch name.text {
+	case "intent":
+		if len(args) != 1 {
+			return nil, p.errorf(name, "intent() takes exactly one argument, found %d", len(args))
+		}
+		confidence, err := p.parseConfidence()
+		if err != nil {
+			return nil, err
+		}
+		return IntentIs(args[0], confidence), nil
+	case "entity":
+		if len(args) != 1 && len(args) != 2 {
+			return nil, p.errorf(name, "entity() takes one or two arguments (entity, value), found %d", len(args))
+		}
+		confidence, err := p.parseConfidence()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) == 2 {
+			return IfEntityValueIs(args[0], args[1], confidence), nil
+		}
+		return IfEntityIs(args[0], confidence), nil
+	case "text_matches":
+		if len(args) != 1 {
+			return nil, p.errorf(name, "text_matches() takes exactly one argument, found %d", len(args))
+		}
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return nil, p.errorf(name, "invalid regular expression: %s", err.Error())
+		}
+		return &Matcher{Text: TextMatch{Regex: re}}, nil
+	case "text_contains":
+		if len(args) != 1 {
+			return nil, p.errorf(name, "text_contains() takes exactly one argument, found %d", len(args))
+		}
+		return IfTextContains(args[0]), nil
+	case "text_prefix":
+		if len(args) != 1 {
+			return nil, p.errorf(name, "text_prefix() takes exactly one argument, found %d", len(args))
+		}
+		return IfTextHasPrefix(args[0]), nil
+	case "input_type":
+		if len(args) != 1 {
+			return nil, p.errorf(name, "input_type() takes exactly one argument, found %d", len(args))
+		}
+		return IfInputTypeIs(InputType(args[0])), nil
+	case "node":
+		switch len(args) {
+		case 1:
+			// a node can be referenced by name or by title
+			return IfDialogNodeNameIs(args[0]).OrIfDialogNodeTitleIs(args[0]), nil
+		case 2:
+			return IfDialogNodeIs(args[0], args[1]), nil
+		}
+		return nil, p.errorf(name, "node() takes one or two arguments (title, name), found %d", len(args))
+	}
+
+	return nil, p.errorf(name, "unknown function %s()", name.text)
+}
+
+func (p *matcherParser) parseLiteral() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString, tokenNumber:
+		return t.value, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null", "nil":
+			return nil, nil
+		}
+	}
+
+	return nil, p.errorf(t, "expected a string, a number, true, false or null but found %s", t)
+}
+
+// list := "(" literal ( "," literal )* ")"
+func (p *matcherParser) parseLiteralList() ([]interface{}, error) {
+	if _, err := p.expect(tokenLeftParen, "'('"); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0)
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		if t.kind == tokenRightParen {
+			return values, nil
+		}
+		if t.kind != tokenComma {
+			return nil, p.errorf(t, "expected ',' or ')' but found %s", t)
+		}
+	}
+}
+
+func (p *matcherParser) parseVariable(variable token) (*Matcher, error) {
+	name := variable.value.(string)
+
+	t := p.peek()
+	if t.kind == tokenIdent && t.text == "in" {
+		p.next()
+		values, err := p.parseLiteralList()
+		if err != nil {
+			return nil, err
+		}
+		return IfContextVariableIn(name, values...), nil
+	}
+
+	if t.kind != tokenOperator || t.text == "&&" || t.text == "||" || t.text == "!" {
+		return IfContextVariableExists(name), nil
+	}
+
+	p.next()
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Matcher{ContextVariable: CMatch{Name: name, Value: value, Operator: CompareOperator(t.text)}}, nil
+}
+
+// ParseMatcher compiles an expression into a Matcher, e.g.
+//
+//	intent("buy") > 0.6 && (entity("product") || $cart_size > 3) && !node("farewell")
+//
+// the available functions are intent(name), entity(name) or entity(name, value), node(name) or
+// node(title, name), text_matches(regex), text_contains(text), text_prefix(text) and input_type(type).
+// The context variables are referenced with $ and can be compared with ==, !=, <, <=, > and >=,
+// checked against a list with $var in ("a", "b") or alone to check if they exist
+func ParseMatcher(expr string) (*Matcher, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &matcherParser{expr: expr, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "empty expression")
+	}
+
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %s, expected '&&', '||' or the end of expression", t)
+	}
+
+	return m, nil
+}
+
+// MustParseMatcher is like ParseMatcher but panics if the expression is invalid
+func MustParseMatcher(expr string) *Matcher {
+	m, err := ParseMatcher(expr)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// UnmarshalText allows to write matchers as expressions into config files (json, yaml, etc)
+func (m *Matcher) UnmarshalText(text []byte) error {
+	parsed, err := ParseMatcher(string(text))
+	if err != nil {
+		return err
+	}
+	*m = *parsed
+	return nil
+}
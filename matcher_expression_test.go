@@ -0,0 +1,174 @@
+package neocortex
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func expressionFixture() (*Context, *Input, *Output) {
+	c := &Context{
+		SessionID: "s1",
+		Variables: map[string]interface{}{
+			"cart_size": 4,
+			"plan":      "pro",
+			"verified":  true,
+		},
+	}
+	in := &Input{Data: InputData{Type: InputText, Value: "I want to buy a laptop"}}
+	out := &Output{
+		Intents:      []Intent{{Intent: "buy", Confidence: 0.8}},
+		Entities:     []Entity{{Entity: "product", Value: "laptop", Confidence: 0.9}},
+		VisitedNodes: []*DialogNode{{Name: "checkout", Title: "sales"}},
+	}
+	return c, in, out
+}
+
+func TestParseMatcher(t *testing.T) {
+	c, in, out := expressionFixture()
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{`intent("buy")`, true},
+		{`intent("buy") > 0.7`, true},
+		{`intent("buy") > 0.9`, false},
+		{`intent("sell")`, false},
+		{`entity("product")`, true},
+		{`entity("product", "laptop")`, true},
+		{`entity("product", "phone")`, false},
+		{`node("checkout")`, true},
+		{`node("sales")`, true},
+		{`node("sales", "checkout")`, true},
+		{`node("farewell")`, false},
+		{`text_contains("laptop")`, true},
+		{`text_prefix("I want")`, true},
+		{`text_matches("^I .* laptop$")`, true},
+		{`text_matches("phone")`, false},
+		{`input_type("text")`, true},
+		{`$plan`, true},
+		{`$missing`, false},
+		{`$plan == "pro"`, true},
+		{`$plan != "pro"`, false},
+		{`$cart_size > 3`, true},
+		{`$cart_size >= 4`, true},
+		{`$cart_size < 4`, false},
+		{`$cart_size <= 4`, true},
+		{`$verified == true`, true},
+		{`$plan in ("free", "pro")`, true},
+		{`$plan in ("free", "basic")`, false},
+
+		// negation
+		{`!intent("sell")`, true},
+		{`!intent("buy")`, false},
+		{`!!intent("buy")`, true},
+		{`!$missing`, true},
+
+		// && binds tighter than ||
+		{`intent("sell") && intent("buy") || $plan == "pro"`, true},
+		{`$plan == "pro" || intent("sell") && intent("buy")`, true},
+		{`intent("sell") && (intent("buy") || $plan == "pro")`, false},
+		{`(intent("sell") || intent("buy")) && $plan == "pro"`, true},
+		{`intent("buy") && !(node("farewell") || $cart_size > 10)`, true},
+		{`intent("buy") && !node("checkout")`, false},
+		{`!intent("buy") || !$verified`, false},
+		{`intent("buy") > 0.6 && (entity("product") || $cart_size > 3) && !node("farewell")`, true},
+		{`((intent("buy")))`, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			m, err := ParseMatcher(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Matches(c, in, out); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseMatcherSyntaxErrors(t *testing.T) {
+	cases := []struct {
+		expr     string
+		position int
+		message  string
+	}{
+		{``, 0, "empty expression"},
+		{`   `, 3, "empty expression"},
+		{`intent("buy"`, 12, "expected ',' or ')'"},
+		{`intent("buy") &&`, 16, "expected a function"},
+		{`(intent("buy")`, 14, "expected ')'"},
+		{`intent("buy"))`, 13, "unexpected ')'"},
+		{`intent("buy") entity("x")`, 14, "unexpected 'entity'"},
+		{`intent("buy) > 0.5`, 7, "unterminated string"},
+		{`intent("buy") >= 0.5`, 14, "only can be compared with '>'"},
+		{`intent("a", "b")`, 0, "intent() takes exactly one argument"},
+		{`entity()`, 7, "expected a string argument"},
+		{`smile("x")`, 0, "unknown function smile()"},
+		{`$ == 1`, 0, "expected a variable name"},
+		{`$plan == `, 9, "expected a string, a number"},
+		{`$plan in "pro"`, 9, "expected '('"},
+		{`text_matches("(")`, 0, "invalid regular expression"},
+		{`intent("buy") # 1`, 14, "unexpected character '#'"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := ParseMatcher(tc.expr)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			syntaxErr := new(MatcherSyntaxError)
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a *MatcherSyntaxError, got %T", err)
+			}
+			if syntaxErr.Position != tc.position {
+				t.Errorf("expected the position %d, got %d (%s)", tc.position, syntaxErr.Position, syntaxErr.Message)
+			}
+			if !strings.Contains(syntaxErr.Message, tc.message) {
+				t.Errorf("expected the message to contain %q, got %q", tc.message, syntaxErr.Message)
+			}
+
+			// the last line points to the position with a caret
+			lines := strings.Split(err.Error(), "\n")
+			caret := lines[len(lines)-1]
+			if caret != "\t"+strings.Repeat(" ", tc.position)+"^" {
+				t.Errorf("the caret isn't under the position %d: %q", tc.position, caret)
+			}
+		})
+	}
+}
+
+func TestMustParseMatcherPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	MustParseMatcher(`intent(`)
+}
+
+func TestMatcherUnmarshalText(t *testing.T) {
+	c, in, out := expressionFixture()
+
+	var config struct {
+		When *Matcher `json:"when"`
+	}
+	if err := json.Unmarshal([]byte(`{"when": "intent(\"buy\") && $plan == \"pro\""}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.When == nil || !config.When.Matches(c, in, out) {
+		t.Error("the unmarshalled matcher must match")
+	}
+
+	err := json.Unmarshal([]byte(`{"when": "intent(\"buy\") &&"}`), &config)
+	syntaxErr := new(MatcherSyntaxError)
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected a *MatcherSyntaxError, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package neocortex
+
+import "testing"
+
+func TestMatcherPredicates(t *testing.T) {
+	c, in, out := expressionFixture()
+	c.Variables["age"] = "42"
+	c.Variables["score"] = 7.5
+
+	cases := []struct {
+		name    string
+		matcher *Matcher
+		want    bool
+	}{
+		{"intent", IntentIs("buy"), true},
+		{"intent under the confidence", IntentIs("buy", 0.8), false},
+		{"entity", IfEntityIs("product"), true},
+		{"entity value", IfEntityValueIs("product", "laptop"), true},
+		{"other entity value", IfEntityValueIs("product", "phone"), false},
+		{"node name", IfDialogNodeNameIs("checkout"), true},
+		{"node title", IfDialogNodeTitleIs("sales"), true},
+		{"node title and name", IfDialogNodeIs("sales", "checkout"), true},
+		{"text regex", IfTextMatches(`buy\s+a`), true},
+		{"text contains", IfTextContains("laptop"), true},
+		{"text prefix", IfTextHasPrefix("want"), false},
+		{"input type", IfInputTypeIs(InputText), true},
+		{"other input type", IfInputTypeIs(InputAudio), false},
+		{"variable is", IfContextVariableIs("plan", "pro"), true},
+		{"variable int vs float", IfContextVariableIs("cart_size", 4.0), true},
+		{"variable exists", IfContextVariableExists("plan"), true},
+		{"variable doesn't exist", IfContextVariableExists("missing"), false},
+		{"variable in", IfContextVariableIn("plan", "free", "pro"), true},
+		{"variable not equal", IfContextVariable("plan", NotEqual, "free"), true},
+		{"missing variable not equal", IfContextVariable("missing", NotEqual, "free"), false},
+		{"numeric string greater", IfContextVariable("age", Greater, 40), true},
+		{"float less or equal", IfContextVariable("score", LessOrEqual, 7.5), true},
+		{"string comparison", IfContextVariable("plan", Less, "zzz"), true},
+		{"not comparable", IfContextVariable("verified", Greater, 1), false},
+		{"and", IntentIs("buy").AndEntityIs("product"), true},
+		{"and false", IntentIs("buy").AndEntityIs("price"), false},
+		{"or", IntentIs("sell").OrEntityIs("product"), true},
+		{"or false", IntentIs("sell").OrEntityIs("price"), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.matcher.Matches(c, in, out); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMatcherNot(t *testing.T) {
+	c, in, out := expressionFixture()
+
+	cases := []struct {
+		name    string
+		matcher *Matcher
+		want    bool
+	}{
+		{"alone", Not(IntentIs("sell")), true},
+		{"alone negating a match", Not(IntentIs("buy")), false},
+		{"double", Not(Not(IntentIs("buy"))), true},
+
+		// the negation is AND-ed with the other predicates of the matcher, not OR-ed
+		{"with a matching sibling", &Matcher{Intent: Match{Is: "buy"}, NOT: IntentIs("sell")}, true},
+		{"with a sibling that doesn't match", &Matcher{Intent: Match{Is: "sell"}, NOT: IntentIs("greet")}, false},
+		{"with a sibling, negating a match", &Matcher{Intent: Match{Is: "buy"}, NOT: IfEntityIs("product")}, false},
+
+		{"and not", IntentIs("buy").AndNot(IfDialogNodeNameIs("farewell")), true},
+		{"and not a match", IntentIs("buy").AndNot(IfDialogNodeNameIs("checkout")), false},
+		{"or not", IntentIs("sell").OrNot(IntentIs("greet")), true},
+		{"or not a match", IntentIs("sell").OrNot(IntentIs("buy")), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.matcher.Matches(c, in, out); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMatcherInputAndOutput(t *testing.T) {
+	c, in, out := expressionFixture()
+	in.Intents = []Intent{{Intent: "greet", Confidence: 1}}
+
+	// the intents of the output take precedence over the intents of the input
+	if IntentIs("greet").Matches(c, in, out) {
+		t.Error("the intents must be taken from the output")
+	}
+	if !in.Match(c, IntentIs("greet")) {
+		t.Error("without output the intents must be taken from the input")
+	}
+
+	// without its input the text and the type of the output are never matched
+	if out.Match(c, IfTextContains("laptop")) || out.Match(c, IfInputTypeIs(InputText)) {
+		t.Error("Output.Match can't evaluate the text or the input type without the input")
+	}
+
+	out.Input = in
+	for _, m := range []*Matcher{IfTextContains("laptop"), IfTextHasPrefix("I want"), IfTextMatches(`\blaptops?\b`), IfInputTypeIs(InputText)} {
+		if !out.Match(c, m) {
+			t.Errorf("Output.Match must evaluate the text and the type of its input, %+v", m)
+		}
+	}
+	if !out.Copy().Match(c, IfTextContains("laptop")) {
+		t.Error("the copy of the output must keep its input")
+	}
+	if !out.Match(c, IntentIs("buy").AndIfDialogNodeNameIs("checkout")) {
+		t.Error("Output.Match must evaluate the intents and the nodes")
+	}
+
+	// the nodes are only visited by the outputs
+	if in.Match(c, IfDialogNodeNameIs("checkout")) {
+		t.Error("Input.Match can't evaluate the dialog nodes")
+	}
+}
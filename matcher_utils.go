@@ -1,5 +1,7 @@
 package neocortex
 
+import "regexp"
+
 func (m *Matcher) And(and *Matcher) *Matcher {
 	m.AND = and
 	return m
@@ -117,3 +119,138 @@ func (m *Matcher) AndIfDialogNodeIs(title, name string) *Matcher {
 func (m *Matcher) OrIfDialogNodeIs(title, name string) *Matcher {
 	return m.Or(IfDialogNodeIs(title, name))
 }
+
+// Not negates the matcher
+func Not(matcher *Matcher) *Matcher {
+	return &Matcher{
+		NOT: matcher,
+	}
+}
+
+func (m *Matcher) AndNot(matcher *Matcher) *Matcher {
+	return m.And(Not(matcher))
+}
+
+func (m *Matcher) OrNot(matcher *Matcher) *Matcher {
+	return m.Or(Not(matcher))
+}
+
+func IfEntityValueIs(entity, value string, confidence ...float64) *Matcher {
+	m := IfEntityIs(entity, confidence...)
+	m.Entity.Value = value
+	return m
+}
+
+func (m *Matcher) AndEntityValueIs(entity, value string, confidence ...float64) *Matcher {
+	return m.And(IfEntityValueIs(entity, value, confidence...))
+}
+
+func (m *Matcher) OrEntityValueIs(entity, value string, confidence ...float64) *Matcher {
+	return m.Or(IfEntityValueIs(entity, value, confidence...))
+}
+
+// IfTextMatches checks the raw value of the input with a regular expression, it panics if the expression is invalid
+func IfTextMatches(expr string) *Matcher {
+	return &Matcher{
+		Text: TextMatch{
+			Regex: regexp.MustCompile(expr),
+		},
+	}
+}
+
+func (m *Matcher) AndIfTextMatches(expr string) *Matcher {
+	return m.And(IfTextMatches(expr))
+}
+
+func (m *Matcher) OrIfTextMatches(expr string) *Matcher {
+	return m.Or(IfTextMatches(expr))
+}
+
+func IfTextContains(text string) *Matcher {
+	return &Matcher{
+		Text: TextMatch{
+			Contains: text,
+		},
+	}
+}
+
+func (m *Matcher) AndIfTextContains(text string) *Matcher {
+	return m.And(IfTextContains(text))
+}
+
+func (m *Matcher) OrIfTextContains(text string) *Matcher {
+	return m.Or(IfTextContains(text))
+}
+
+func IfTextHasPrefix(prefix string) *Matcher {
+	return &Matcher{
+		Text: TextMatch{
+			Prefix: prefix,
+		},
+	}
+}
+
+func (m *Matcher) AndIfTextHasPrefix(prefix string) *Matcher {
+	return m.And(IfTextHasPrefix(prefix))
+}
+
+func (m *Matcher) OrIfTextHasPrefix(prefix string) *Matcher {
+	return m.Or(IfTextHasPrefix(prefix))
+}
+
+func IfInputTypeIs(inputType InputType) *Matcher {
+	return &Matcher{
+		InputType: inputType,
+	}
+}
+
+func (m *Matcher) AndIfInputTypeIs(inputType InputType) *Matcher {
+	return m.And(IfInputTypeIs(inputType))
+}
+
+func (m *Matcher) OrIfInputTypeIs(inputType InputType) *Matcher {
+	return m.Or(IfInputTypeIs(inputType))
+}
+
+// IfContextVariable compares the context variable using the operator (==, !=, <, <=, >, >=, in, exists)
+func IfContextVariable(name string, operator CompareOperator, value interface{}) *Matcher {
+	return &Matcher{
+		ContextVariable: CMatch{
+			Name:     name,
+			Value:    value,
+			Operator: operator,
+		},
+	}
+}
+
+func (m *Matcher) AndIfContextVariable(name string, operator CompareOperator, value interface{}) *Matcher {
+	return m.And(IfContextVariable(name, operator, value))
+}
+
+func (m *Matcher) OrIfContextVariable(name string, operator CompareOperator, value interface{}) *Matcher {
+	return m.Or(IfContextVariable(name, operator, value))
+}
+
+func IfContextVariableExists(name string) *Matcher {
+	return IfContextVariable(name, Exists, nil)
+}
+
+func (m *Matcher) AndIfContextVariableExists(name string) *Matcher {
+	return m.And(IfContextVariableExists(name))
+}
+
+func (m *Matcher) OrIfContextVariableExists(name string) *Matcher {
+	return m.Or(IfContextVariableExists(name))
+}
+
+func IfContextVariableIn(name string, values ...interface{}) *Matcher {
+	return IfContextVariable(name, In, values)
+}
+
+func (m *Matcher) AndIfContextVariableIn(name string, values ...interface{}) *Matcher {
+	return m.And(IfContextVariableIn(name, values...))
+}
+
+func (m *Matcher) OrIfContextVariableIn(name string, values ...interface{}) *Matcher {
+	return m.Or(IfContextVariableIn(name, values...))
+}
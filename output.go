@@ -35,4 +35,8 @@ type Output struct {
 	VisitedNodes []*DialogNode `json:"visited_nodes"`
 	Logs         []*LogMessage `json:"logs"`
 	Responses    []Response    `json:"responses"`
+
+	// Input is the input answered by the output, the engine sets it before the resolvers so Output.Match
+	// can evaluate the Text and InputType predicates. It isn't serialized
+	Input *Input `json:"-" bson:"-"`
 }
@@ -9,3 +9,96 @@ func (out *Output) Clean() *Output {
 	out.Responses = []Response{}
 	return out
 }
+
+// Copy returns a deep copy of the output, the values of the responses are copied if they are
+// options (the other values are strings or numbers)
+func (out *Output) Copy() *Output {
+	if out == nil {
+		return nil
+	}
+
+	copied := &Output{Input: out.Input}
+	if out.Entities != nil {
+		copied.Entities = make([]Entity, len(out.Entities))
+		for i, e := range out.Entities {
+			if e.Location != nil {
+				e.Location = append([]int64{}, e.Location...)
+			}
+			if e.Metadata != nil {
+				metadata := make(map[string]interface{}, len(e.Metadata))
+				for k, v := range e.Metadata {
+					metadata[k] = v
+				}
+				e.Metadata = metadata
+			}
+			copied.Entities[i] = e
+		}
+	}
+	if out.Intents != nil {
+		copied.Intents = append([]Intent{}, out.Intents...)
+	}
+	if out.VisitedNodes != nil {
+		copied.VisitedNodes = make([]*DialogNode, len(out.VisitedNodes))
+		for i, n := range out.VisitedNodes {
+			if n != nil {
+				node := *n
+				n = &node
+			}
+			copied.VisitedNodes[i] = n
+		}
+	}
+	if out.Logs != nil {
+		copied.Logs = make([]*LogMessage, len(out.Logs))
+		for i, l := range out.Logs {
+			if l != nil {
+				log := *l
+				l = &log
+			}
+			copied.Logs[i] = l
+		}
+	}
+	if out.Responses != nil {
+		copied.Responses = make([]Response, len(out.Responses))
+		for i, r := range out.Responses {
+			r.Value = copyResponseValue(r.Value)
+			copied.Responses[i] = r
+		}
+	}
+	return copied
+}
+
+func copyResponseValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case OptionsResponse:
+		return copyOptionsResponse(v)
+	case *OptionsResponse:
+		if v == nil {
+			return v
+		}
+		options := copyOptionsResponse(*v)
+		return &options
+	case []OptionsResponse:
+		list := make([]OptionsResponse, len(v))
+		for i, o := range v {
+			list[i] = copyOptionsResponse(o)
+		}
+		return list
+	}
+	return value
+}
+
+func copyOptionsResponse(o OptionsResponse) OptionsResponse {
+	if o.Options == nil {
+		return o
+	}
+	options := make([]*Option, len(o.Options))
+	for i, option := range o.Options {
+		if option != nil {
+			copied := *option
+			option = &copied
+		}
+		options[i] = option
+	}
+	o.Options = options
+	return o
+}
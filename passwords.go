@@ -0,0 +1,113 @@
+package neocortex
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordAlgorithm is the function used to hash the passwords of the admins
+type PasswordAlgorithm string
+
+// Bcrypt hashes with bcrypt and its default cost, it's the default algorithm
+const Bcrypt PasswordAlgorithm = "bcrypt"
+
+// Argon2id hashes with argon2id, the hash is encoded like "$argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>"
+const Argon2id PasswordAlgorithm = "argon2id"
+
+const (
+	argon2Memory  = 64 * 1024
+	argon2Time    = 1
+	argon2Threads = 4
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// HashPassword returns the hash of the password with the algorithm (bcrypt by default),
+// the hash keeps its parameters so CheckPassword can verify it with any algorithm
+func HashPassword(password string, algorithm ...PasswordAlgorithm) (string, error) {
+	alg := Bcrypt
+	if len(algorithm) > 0 {
+		alg = algorithm[0]
+	}
+
+	switch alg {
+	case Bcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	case Argon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+
+	return "", ErrUnknownPasswordAlgorithm
+}
+
+// CheckPassword returns true if the password matches the hash, the algorithm is taken from the hash
+func CheckPassword(hash, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return checkArgon2id(hash, password)
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func checkArgon2id(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+var unknownAdmin struct {
+	once sync.Once
+	hash string
+}
+
+// unknownAdminHash is a valid hash that no password matches, it's checked when the admin doesn't exist
+func unknownAdminHash() string {
+	unknownAdmin.once.Do(func() {
+		secret := make([]byte, 32)
+		_, _ = rand.Read(secret)
+		unknownAdmin.hash, _ = HashPassword(base64.RawStdEncoding.EncodeToString(secret))
+	})
+	return unknownAdmin.hash
+}
@@ -0,0 +1,172 @@
+package neocortex
+
+import (
+	"context"
+	"time"
+)
+
+// PersistenceMode defines when the active dialogs are written to the repository
+type PersistenceMode string
+
+// PersistIncremental saves the dialog after every input or output, the saves run in background (out of
+// the message in progress) and the records that arrive meanwhile are saved together by the next one
+const PersistIncremental PersistenceMode = "incremental"
+
+// PersistPeriodic saves the dialogs with new records every checkpoint interval
+const PersistPeriodic PersistenceMode = "periodic"
+
+// PersistOnClose saves the dialogs only when they are closed, the active dialogs are lost if the process dies.
+// It's the default mode, the other ones must be chosen with SetPersistenceMode
+const PersistOnClose PersistenceMode = "on_close"
+
+const defaultCheckpointInterval = 30 * time.Second
+
+// defaultRecoveryWindow is how far back the engine looks for active dialogs at startup
+const defaultRecoveryWindow = 24 * time.Hour
+
+// SetPersistenceMode changes when the active dialogs are saved, the interval is used by PersistPeriodic
+// (30 seconds by default). It must be called before Run
+func (engine *Engine) SetPersistenceMode(mode PersistenceMode, interval ...time.Duration) {
+	engine.persistenceMode = mode
+	if len(interval) > 0 && interval[0] > 0 {
+		engine.checkpointInterval = interval[0]
+	}
+}
+
+// SetRecoveryWindow changes how far back (from its start) an active dialog can be recovered at startup.
+// The recovered dialogs are matched with its person only by the key of the PersonInfo (its ID, or its name
+// without ID), the sessions of the channels are not restored: the channel creates a new context when the
+// person comes back (by any channel) and the recovered dialog and variables are moved into it
+func (engine *Engine) SetRecoveryWindow(window time.Duration) {
+	engine.recoveryWindow = window
+}
+
+func (engine *Engine) saveCheckpoint(dialog *Dialog) error {
+	return engine.Repository.SaveDialog(dialog)
+}
+
+// persist saves the active dialog of the context in background if the engine persists incrementally,
+// there is at most one save scheduled by dialog
+func (engine *Engine) persist(c *Context) {
+	if engine.Repository == nil || engine.persistenceMode != PersistIncremental {
+		return
+	}
+
+	if !engine.Sessions.schedule(c) {
+		return
+	}
+
+	go func() {
+		if err := engine.Sessions.checkpoint(c, engine.saveCheckpoint); err != nil {
+			engine.log(Error, "error saving the dialog", withError(engine.contextFields(c), err))
+		}
+	}()
+}
+
+func (engine *Engine) recordInput(c *Context, in *Input) {
+	engine.Sessions.recordInput(c, in)
+	engine.persist(c)
+}
+
+func (engine *Engine) recordOutput(c *Context, out *Output) {
+	engine.Sessions.recordOutput(c, out)
+	engine.persist(c)
+}
+
+// runCheckpoints saves the dialogs with new records periodically if the engine persists periodically
+func (engine *Engine) runCheckpoints() {
+	if engine.Repository == nil || engine.persistenceMode != PersistPeriodic {
+		return
+	}
+
+	ticker := time.NewTicker(engine.checkpointInterval)
+	go func() {
+		for range ticker.C {
+			engine.checkpoint()
+		}
+	}()
+}
+
+// checkpoint saves all dialogs with new records
+func (engine *Engine) checkpoint() {
+	for _, c := range engine.Sessions.dirty() {
+		if err := engine.Sessions.checkpoint(c, engine.saveCheckpoint); err != nil {
+			engine.log(Error, "error saving the dialog", withError(engine.contextFields(c), err))
+		}
+	}
+}
+
+// recoverDialogs looks for the dialogs that weren't closed (the process died), the dialogs idle for more than
+// maxIdle are closed and the others wait for its person to come back (see onNewContextCreated)
+func (engine *Engine) recoverDialogs(maxIdle time.Duration) error {
+	if engine.Repository == nil || engine.persistenceMode == PersistOnClose {
+		return nil
+	}
+
+	now := time.Now()
+	frame := TimeFrame{From: now.Add(-engine.recoveryWindow), PageSize: 200, PageNum: 1}
+
+	latest := map[string]*Dialog{}
+	for {
+		dialogs, err := engine.Repository.AllDialogs(frame)
+		if err != nil {
+			return err
+		}
+
+		for _, dialog := range dialogs {
+			if !dialog.EndAt.IsZero() || len(dialog.Contexts) == 0 {
+				continue
+			}
+
+			if now.Sub(dialog.LastActivity) > maxIdle {
+				engine.closeRecovered(dialog, dialog.LastActivity)
+				continue
+			}
+
+			key := dialog.Contexts[len(dialog.Contexts)-1].Context.Person.key()
+			if previous, ok := latest[key]; ok {
+				if previous.LastActivity.After(dialog.LastActivity) {
+					engine.closeRecovered(dialog, dialog.LastActivity)
+					continue
+				}
+				engine.closeRecovered(previous, previous.LastActivity)
+			}
+			latest[key] = dialog
+		}
+
+		if len(dialogs) < frame.PageSize {
+			break
+		}
+		frame.PageNum++
+	}
+
+	for _, dialog := range latest {
+		last := dialog.Contexts[len(dialog.Contexts)-1].Context
+		ctx := context.Background()
+		c := &Context{
+			Context:   &ctx,
+			SessionID: last.SessionID,
+			Person:    last.Person,
+			Variables: last.Variables,
+		}
+		if c.Variables == nil {
+			c.Variables = map[string]interface{}{}
+		}
+
+		engine.Sessions.restore(c, dialog)
+	}
+
+	if len(latest) > 0 {
+		engine.log(Info, "recovered active dialogs", Fields{"total": len(latest)})
+	}
+
+	return nil
+}
+
+func (engine *Engine) closeRecovered(dialog *Dialog, at time.Time) {
+	dialog.EndAt = at
+	dialog.Performance = engine.dialogPerformanceFunc(dialog)
+	if err := engine.Repository.SaveDialog(dialog); err != nil {
+		engine.log(Error, "error closing the recovered dialog", Fields{DialogIDField: dialog.ID, ErrorField: err})
+	}
+}
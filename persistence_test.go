@@ -0,0 +1,157 @@
+package neocortex_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	neo "github.com/minskylab/neocortex"
+	memoryrepo "github.com/minskylab/neocortex/repositories/memory"
+)
+
+// gatedRepo holds the saves of the open dialogs until its gate is opened
+type gatedRepo struct {
+	*memoryrepo.InMemoryRepo
+	gate chan struct{}
+
+	mu    sync.Mutex
+	saves int
+}
+
+func (repo *gatedRepo) SaveDialog(dialog *neo.Dialog) error {
+	if dialog.EndAt.IsZero() {
+		<-repo.gate
+	}
+
+	repo.mu.Lock()
+	repo.saves++
+	repo.mu.Unlock()
+	return repo.InMemoryRepo.SaveDialog(dialog)
+}
+
+// waitDialogs polls the repository until it has the dialogs that pass the check
+func waitDialogs(t *testing.T, repo neo.Repository, check func(dialogs []*neo.Dialog) bool) []*neo.Dialog {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dialogs, err := repo.AllDialogs(neo.TimeFrame{PageSize: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if check(dialogs) {
+			return dialogs
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the repository doesn't have the expected dialogs, got %d dialogs", len(dialogs))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestThePersistenceIsOnCloseByDefault(t *testing.T) {
+	repo := memoryrepo.New()
+	_, ch := newUselessEngine(t, 0, neo.WithRepository(repo))
+
+	person := neo.PersonInfo{ID: "42"}
+	if _, err := ch.Say(person, "hi"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if dialogs, _ := repo.AllDialogs(neo.TimeFrame{PageSize: 10}); len(dialogs) != 0 {
+		t.Fatalf("the active dialogs can't be saved by default, got %d dialogs", len(dialogs))
+	}
+
+	ch.End(person.ID)
+	dialogs, _ := repo.AllDialogs(neo.TimeFrame{PageSize: 10})
+	if len(dialogs) != 1 || dialogs[0].EndAt.IsZero() {
+		t.Fatalf("the closed dialog must be saved, got %d dialogs", len(dialogs))
+	}
+}
+
+func TestIncrementalPersistenceDoesNotBlockTheMessages(t *testing.T) {
+	const messages = 5
+
+	repo := &gatedRepo{InMemoryRepo: memoryrepo.New(), gate: make(chan struct{})}
+	_, ch := newUselessEngine(t, 0, neo.WithRepository(repo), neo.WithPersistence(neo.PersistIncremental))
+
+	person := neo.PersonInfo{ID: "42"}
+	start := time.Now()
+	for m := 0; m < messages; m++ {
+		if _, err := ch.Say(person, "hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("the messages must not wait for the saves, they took %s", elapsed)
+	}
+
+	close(repo.gate)
+	waitDialogs(t, repo, func(dialogs []*neo.Dialog) bool {
+		return len(dialogs) == 1 && len(dialogs[0].Ins) == messages && len(dialogs[0].Outs) == messages
+	})
+
+	repo.mu.Lock()
+	saves := repo.saves
+	repo.mu.Unlock()
+	if saves > 3 {
+		t.Errorf("the records that arrive during a save must be saved together, got %d saves", saves)
+	}
+
+	ch.End(person.ID)
+	dialogs := waitDialogs(t, repo, func(dialogs []*neo.Dialog) bool {
+		return len(dialogs) == 1 && !dialogs[0].EndAt.IsZero()
+	})
+	if len(dialogs[0].Ins) != messages {
+		t.Errorf("the closed dialog must keep its records, got %d ins", len(dialogs[0].Ins))
+	}
+}
+
+func TestRecoveredDialogsAreResumedByThePerson(t *testing.T) {
+	repo := memoryrepo.New()
+	now := time.Now()
+	open := &neo.Dialog{
+		ID:           "open",
+		StartAt:      now.Add(-time.Minute),
+		LastActivity: now.Add(-time.Second),
+		Ins:          []*neo.InputRecord{{At: now.Add(-time.Second)}},
+		Contexts: []*neo.ContextRecord{{
+			At:      now.Add(-time.Second),
+			Context: neo.Context{SessionID: "before-the-restart", Person: neo.PersonInfo{ID: "42"}, Variables: map[string]interface{}{"step": "payment"}},
+		}},
+	}
+	if err := repo.SaveDialog(open); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, ch := newUselessEngine(t, 0,
+		neo.WithRepository(repo),
+		neo.WithPersistence(neo.PersistIncremental),
+		neo.WithPort("127.0.0.1:0"),
+		neo.WithSessionTimeout(time.Hour),
+	)
+	go engine.Run()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for engine.Sessions.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if engine.Sessions.Len() != 1 {
+		t.Fatalf("expected the recovered dialog, got %d active sessions", engine.Sessions.Len())
+	}
+
+	// the channel doesn't know the recovered context, it creates a new one and the dialog is moved into it
+	exchange, err := ch.Say(neo.PersonInfo{ID: "42"}, "hi again")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exchange.Context.SessionID == "before-the-restart" || exchange.Context.Variables["step"] != "payment" {
+		t.Errorf("the variables must be restored into the new context, got %+v", exchange.Context)
+	}
+
+	dialog, ok := engine.Sessions.Dialog(exchange.Context)
+	if !ok || dialog.ID != "open" || len(dialog.Ins) != 2 {
+		t.Fatalf("the recovered dialog must continue, got %+v", dialog)
+	}
+}
@@ -0,0 +1,137 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: neocortex.proto
+
+package neocortexpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// NeocortexClient is the client API for Neocortex service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NeocortexClient interface {
+	Converse(ctx context.Context, opts ...grpc.CallOption) (Neocortex_ConverseClient, error)
+}
+
+type neocortexClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNeocortexClient(cc grpc.ClientConnInterface) NeocortexClient {
+	return &neocortexClient{cc}
+}
+
+func (c *neocortexClient) Converse(ctx context.Context, opts ...grpc.CallOption) (Neocortex_ConverseClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Neocortex_ServiceDesc.Streams[0], "/neocortex.Neocortex/Converse", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &neocortexConverseClient{stream}
+	return x, nil
+}
+
+type Neocortex_ConverseClient interface {
+	Send(*ConverseRequest) error
+	Recv() (*ConverseResponse, error)
+	grpc.ClientStream
+}
+
+type neocortexConverseClient struct {
+	grpc.ClientStream
+}
+
+func (x *neocortexConverseClient) Send(m *ConverseRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *neocortexConverseClient) Recv() (*ConverseResponse, error) {
+	m := new(ConverseResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NeocortexServer is the server API for Neocortex service.
+// All implementations must embed UnimplementedNeocortexServer
+// for forward compatibility
+type NeocortexServer interface {
+	Converse(Neocortex_ConverseServer) error
+	mustEmbedUnimplementedNeocortexServer()
+}
+
+// UnimplementedNeocortexServer must be embedded to have forward compatible implementations.
+type UnimplementedNeocortexServer struct {
+}
+
+func (UnimplementedNeocortexServer) Converse(Neocortex_ConverseServer) error {
+	return status.Errorf(codes.Unimplemented, "method Converse not implemented")
+}
+func (UnimplementedNeocortexServer) mustEmbedUnimplementedNeocortexServer() {}
+
+// UnsafeNeocortexServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NeocortexServer will
+// result in compilation errors.
+type UnsafeNeocortexServer interface {
+	mustEmbedUnimplementedNeocortexServer()
+}
+
+func RegisterNeocortexServer(s grpc.ServiceRegistrar, srv NeocortexServer) {
+	s.RegisterService(&Neocortex_ServiceDesc, srv)
+}
+
+func _Neocortex_Converse_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NeocortexServer).Converse(&neocortexConverseServer{stream})
+}
+
+type Neocortex_ConverseServer interface {
+	Send(*ConverseResponse) error
+	Recv() (*ConverseRequest, error)
+	grpc.ServerStream
+}
+
+type neocortexConverseServer struct {
+	grpc.ServerStream
+}
+
+func (x *neocortexConverseServer) Send(m *ConverseResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *neocortexConverseServer) Recv() (*ConverseRequest, error) {
+	m := new(ConverseRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Neocortex_ServiceDesc is the grpc.ServiceDesc for Neocortex service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Neocortex_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "neocortex.Neocortex",
+	HandlerType: (*NeocortexServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Converse",
+			Handler:       _Neocortex_Converse_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "neocortex.proto",
+}
@@ -0,0 +1,57 @@
+package boltdb
+
+import (
+	"github.com/asdine/storm"
+	"github.com/minskylab/neocortex"
+)
+
+// adminRecord is keyed by the username, so bolt keeps the admins sorted by it
+type adminRecord struct {
+	Username string           `storm:"id"`
+	Admin    *neocortex.Admin `json:"admin"`
+}
+
+// SaveAdmin inserts the admin or replaces the admin with the same username
+func (repo *Repository) SaveAdmin(admin *neocortex.Admin) error {
+	return repo.admins.Save(&adminRecord{
+		Username: admin.Username,
+		Admin:    admin,
+	})
+}
+
+func (repo *Repository) GetAdmin(username string) (*neocortex.Admin, error) {
+	record := new(adminRecord)
+	if err := repo.admins.One("Username", username, record); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, neocortex.ErrAdminNotExist
+		}
+		return nil, err
+	}
+	return record.Admin, nil
+}
+
+// AllAdmins returns the admins sorted by its username
+func (repo *Repository) AllAdmins() ([]*neocortex.Admin, error) {
+	records := make([]*adminRecord, 0)
+	if err := repo.admins.All(&records); err != nil {
+		return nil, err
+	}
+
+	admins := make([]*neocortex.Admin, 0, len(records))
+	for _, record := range records {
+		admins = append(admins, record.Admin)
+	}
+	return admins, nil
+}
+
+func (repo *Repository) DeleteAdmin(username string) (*neocortex.Admin, error) {
+	admin, err := repo.GetAdmin(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.admins.DeleteStruct(&adminRecord{Username: username}); err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
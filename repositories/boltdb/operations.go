@@ -1,57 +1,278 @@
 package boltdb
 
 import (
+	"math"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/index"
+	"github.com/asdine/storm/q"
 	"github.com/minskylab/neocortex"
 	"github.com/rs/xid"
 )
 
-func (repo *Repository) SaveNewDialog(dialog *neocortex.Dialog) (*neocortex.Dialog, error) {
+// dialogRecord wraps the dialog with an index over its start (unix nanoseconds, big endian into bolt),
+// so the time frame queries are ranges over the index
+type dialogRecord struct {
+	ID      string            `storm:"id"`
+	StartAt int64             `storm:"index"`
+	Dialog  *neocortex.Dialog `json:"dialog"`
+}
+
+type viewRecord struct {
+	ID   string `storm:"id"`
+	Name string
+	View *neocortex.View `json:"view"`
+}
+
+// stamp converts t to the index value, storm doesn't index zero values and the
+// negative numbers doesn't sort well as bytes, so the minimum stamp is 1
+func stamp(t time.Time) int64 {
+	if t.IsZero() || t.UnixNano() < 1 {
+		return 1
+	}
+	return t.UnixNano()
+}
+
+func (repo *Repository) SaveDialog(dialog *neocortex.Dialog) error {
 	if dialog.ID == "" {
 		dialog.ID = xid.New().String()
 	}
-	err := repo.db.Save(dialog)
-	if err != nil {
-		return nil, err
-	}
-	return repo.GetDialogByID(dialog.ID)
+
+	return repo.dialogs.Save(&dialogRecord{
+		ID:      dialog.ID,
+		StartAt: stamp(dialog.StartAt),
+		Dialog:  dialog,
+	})
 }
 
 func (repo *Repository) GetDialogByID(id string) (*neocortex.Dialog, error) {
-	dialog := new(neocortex.Dialog)
-	err := repo.db.One("ID", id, dialog)
-	if err != nil {
+	record := new(dialogRecord)
+	if err := repo.dialogs.One("ID", id, record); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, neocortex.ErrDialogNotExist
+		}
 		return nil, err
 	}
-	return dialog, nil
+	return record.Dialog, nil
 }
 
-func (repo *Repository) GetAllDialogs() ([]*neocortex.Dialog, error) {
-	var allDialogs []*neocortex.Dialog
-	err := repo.db.All(&allDialogs)
-	if err != nil {
+// dialogsInFrame returns the dialogs that start into the frame sorted by its start, options can page the range
+func (repo *Repository) dialogsInFrame(frame neocortex.TimeFrame, options ...func(*index.Options)) ([]*neocortex.Dialog, error) {
+	from, to := frame.Bounds()
+	max := int64(math.MaxInt64)
+	if !to.IsZero() {
+		max = stamp(to)
+	}
+
+	records := make([]*dialogRecord, 0)
+	if err := repo.dialogs.Range("StartAt", stamp(from), max, &records, options...); err != nil && err != storm.ErrNotFound {
 		return nil, err
 	}
-	return allDialogs, nil
+
+	dialogs := make([]*neocortex.Dialog, 0, len(records))
+	for _, record := range records {
+		dialogs = append(dialogs, record.Dialog)
+	}
+	return dialogs, nil
+}
+
+func (repo *Repository) AllDialogs(frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
+	offset, limit := frame.Page()
+	return repo.dialogsInFrame(frame, storm.Skip(offset), storm.Limit(limit))
 }
 
 func (repo *Repository) DeleteDialog(id string) (*neocortex.Dialog, error) {
-	d, err := repo.GetDialogByID(id)
+	record := new(dialogRecord)
+	if err := repo.dialogs.One("ID", id, record); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, neocortex.ErrDialogNotExist
+		}
+		return nil, err
+	}
+
+	if err := repo.dialogs.DeleteStruct(record); err != nil {
+		return nil, err
+	}
+	return record.Dialog, nil
+}
+
+func (repo *Repository) DialogsByView(viewID string, frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
+	view, err := repo.GetViewByID(viewID)
 	if err != nil {
 		return nil, err
 	}
-	err = repo.db.DeleteStruct(d)
+
+	dialogs, err := repo.dialogsInFrame(frame)
 	if err != nil {
 		return nil, err
 	}
 
-	return d, err
+	matched := make([]*neocortex.Dialog, 0)
+	for _, dialog := range dialogs {
+		if view.Matches(dialog) {
+			matched = append(matched, dialog)
+		}
+	}
+	return frame.Paginate(matched), nil
 }
 
-func (repo *Repository) UpdateDialog(dialog *neocortex.Dialog) (*neocortex.Dialog, error) {
-	err := repo.db.Update(dialog)
+func (repo *Repository) Summary(frame neocortex.TimeFrame) (*neocortex.Summary, error) {
+	dialogs, err := repo.dialogsInFrame(frame)
 	if err != nil {
 		return nil, err
 	}
+	return neocortex.NewSummary(dialogs), nil
+}
+
+// register appends the value to the collection (without duplicates) into a single transaction
+func (repo *Repository) register(key, value string) error {
+	tx, err := repo.collections.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	values := make([]string, 0)
+	if err := tx.Get(valuesBucket, key, &values); err != nil && err != storm.ErrNotFound {
+		return err
+	}
 
-	return repo.GetDialogByID(dialog.ID)
+	for _, v := range values {
+		if v == value {
+			return nil
+		}
+	}
+
+	if err := tx.Set(valuesBucket, key, append(values, value)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (repo *Repository) values(key string) []string {
+	values := make([]string, 0)
+	if err := repo.collections.Get(valuesBucket, key, &values); err != nil {
+		return []string{}
+	}
+	return values
+}
+
+func (repo *Repository) RegisterIntent(intent string) error {
+	return repo.register(intentsKey, intent)
+}
+
+func (repo *Repository) RegisterEntity(entity string) error {
+	return repo.register(entitiesKey, entity)
+}
+
+func (repo *Repository) RegisterDialogNode(name string) error {
+	return repo.register(nodesKey, name)
+}
+
+func (repo *Repository) RegisterContextVar(value string) error {
+	return repo.register(contextVarsKey, value)
+}
+
+func (repo *Repository) Intents() []string {
+	return repo.values(intentsKey)
+}
+
+func (repo *Repository) Entities() []string {
+	return repo.values(entitiesKey)
+}
+
+func (repo *Repository) DialogNodes() []string {
+	return repo.values(nodesKey)
+}
+
+func (repo *Repository) ContextVars() []string {
+	return repo.values(contextVarsKey)
+}
+
+func (repo *Repository) SaveView(view *neocortex.View) error {
+	if view.ID == "" {
+		view.ID = xid.New().String()
+	}
+
+	return repo.views.Save(&viewRecord{
+		ID:   view.ID,
+		Name: view.Name,
+		View: view,
+	})
+}
+
+func (repo *Repository) GetViewByID(id string) (*neocortex.View, error) {
+	record := new(viewRecord)
+	if err := repo.views.One("ID", id, record); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, neocortex.ErrViewNotExist
+		}
+		return nil, err
+	}
+	return record.View, nil
+}
+
+func recordsToViews(records []*viewRecord) []*neocortex.View {
+	views := make([]*neocortex.View, 0, len(records))
+	for _, record := range records {
+		views = append(views, record.View)
+	}
+	return views
+}
+
+// FindViewByName returns the views with exactly that name, it's a query (not an index) because
+// storm doesn't index the empty names
+func (repo *Repository) FindViewByName(name string) ([]*neocortex.View, error) {
+	records := make([]*viewRecord, 0)
+	if err := repo.views.Select(q.Eq("Name", name)).Find(&records); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return recordsToViews(records), nil
+}
+
+func (repo *Repository) AllViews() ([]*neocortex.View, error) {
+	records := make([]*viewRecord, 0)
+	if err := repo.views.All(&records); err != nil {
+		return nil, err
+	}
+	return recordsToViews(records), nil
+}
+
+func (repo *Repository) UpdateView(view *neocortex.View) error {
+	if _, err := repo.GetViewByID(view.ID); err != nil {
+		return err
+	}
+	return repo.SaveView(view)
+}
+
+func (repo *Repository) DeleteView(id string) (*neocortex.View, error) {
+	record := new(viewRecord)
+	if err := repo.views.One("ID", id, record); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, neocortex.ErrViewNotExist
+		}
+		return nil, err
+	}
+
+	if err := repo.views.DeleteStruct(record); err != nil {
+		return nil, err
+	}
+	return record.View, nil
+}
+
+func (repo *Repository) SetActionVar(name string, value string) error {
+	return repo.db.Set(actionsBucket, name, value)
+}
+
+// GetActionVar returns an empty value if the var doesn't exist
+func (repo *Repository) GetActionVar(name string) (string, error) {
+	var value string
+	if err := repo.db.Get(actionsBucket, name, &value); err != nil {
+		if err == storm.ErrNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	return value, nil
 }
@@ -1,10 +1,39 @@
+// Package boltdb is a repository persisted into a single bolt file (via storm), it implements
+// neocortex.Repository and it's useful for single binary deployments without a database server
 package boltdb
 
-import "github.com/asdine/storm"
+import (
+	"github.com/asdine/storm"
+)
+
+const (
+	dialogsNode     = "dialogs"
+	viewsNode       = "views"
+	collectionsNode = "collections"
+	adminsNode      = "admins"
+	apiKeysNode     = "api_keys"
+	revokedNode     = "revoked_tokens"
+	actionsBucket   = "actions"
+	valuesBucket    = "values"
+)
+
+const (
+	intentsKey     = "intents"
+	entitiesKey    = "entities"
+	nodesKey       = "nodes"
+	contextVarsKey = "context_vars"
+)
 
 type Repository struct {
 	filename string
 	db       *storm.DB
+
+	dialogs     storm.Node
+	views       storm.Node
+	collections storm.Node
+	admins      storm.Node
+	apiKeys     storm.Node
+	revoked     storm.Node
 }
 
 func New(path string) (*Repository, error) {
@@ -14,7 +43,18 @@ func New(path string) (*Repository, error) {
 	}
 
 	return &Repository{
-		db:       db,
-		filename: path,
+		db:          db,
+		filename:    path,
+		dialogs:     db.From(dialogsNode),
+		views:       db.From(viewsNode),
+		collections: db.From(collectionsNode),
+		admins:      db.From(adminsNode),
+		apiKeys:     db.From(apiKeysNode),
+		revoked:     db.From(revokedNode),
 	}, nil
 }
+
+// Close closes the bolt file, the repository can't be used after that
+func (repo *Repository) Close() error {
+	return repo.db.Close()
+}
@@ -0,0 +1,20 @@
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/repositories/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) neocortex.Repository {
+		repo, err := New(filepath.Join(t.TempDir(), "neocortex.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
@@ -0,0 +1,75 @@
+package boltdb
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/minskylab/neocortex"
+)
+
+// apiKeyRecord wraps the key with an index over its creation, so the keys are listed in that order
+type apiKeyRecord struct {
+	ID        string            `storm:"id"`
+	CreatedAt int64             `storm:"index"`
+	Key       *neocortex.APIKey `json:"key"`
+}
+
+type revokedToken struct {
+	ID        string `storm:"id"`
+	ExpiresAt int64
+}
+
+// SaveAPIKey inserts the key or replaces the key with the same id
+func (repo *Repository) SaveAPIKey(key *neocortex.APIKey) error {
+	return repo.apiKeys.Save(&apiKeyRecord{
+		ID:        key.ID,
+		CreatedAt: stamp(key.CreatedAt),
+		Key:       key,
+	})
+}
+
+func (repo *Repository) GetAPIKey(id string) (*neocortex.APIKey, error) {
+	record := new(apiKeyRecord)
+	if err := repo.apiKeys.One("ID", id, record); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, neocortex.ErrAPIKeyNotExist
+		}
+		return nil, err
+	}
+	return record.Key, nil
+}
+
+// AllAPIKeys returns the keys in the order they were created
+func (repo *Repository) AllAPIKeys() ([]*neocortex.APIKey, error) {
+	records := make([]*apiKeyRecord, 0)
+	if err := repo.apiKeys.AllByIndex("CreatedAt", &records); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	keys := make([]*neocortex.APIKey, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, record.Key)
+	}
+	return keys, nil
+}
+
+// RevokeToken drops the revocations that already expired
+func (repo *Repository) RevokeToken(id string, expiresAt time.Time) error {
+	err := repo.revoked.Select(q.Lt("ExpiresAt", time.Now().UnixNano())).Delete(new(revokedToken))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	return repo.revoked.Save(&revokedToken{ID: id, ExpiresAt: expiresAt.UnixNano()})
+}
+
+func (repo *Repository) IsTokenRevoked(id string) (bool, error) {
+	if err := repo.revoked.One("ID", id, new(revokedToken)); err != nil {
+		if err == storm.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/minskylab/neocortex"
+)
+
+// SaveAdmin inserts the admin or replaces the admin with the same username
+func (m *InMemoryRepo) SaveAdmin(admin *neocortex.Admin) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+
+	m.admins[admin.Username] = *admin
+	return nil
+}
+
+func (m *InMemoryRepo) GetAdmin(username string) (*neocortex.Admin, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	admin, exist := m.admins[username]
+	if !exist {
+		return nil, neocortex.ErrAdminNotExist
+	}
+	return &admin, nil
+}
+
+// AllAdmins returns the admins sorted by its username
+func (m *InMemoryRepo) AllAdmins() ([]*neocortex.Admin, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	admins := make([]*neocortex.Admin, 0, len(m.admins))
+	for _, admin := range m.admins {
+		admin := admin
+		admins = append(admins, &admin)
+	}
+	sort.Slice(admins, func(i, j int) bool {
+		return admins[i].Username < admins[j].Username
+	})
+	return admins, nil
+}
+
+func (m *InMemoryRepo) DeleteAdmin(username string) (*neocortex.Admin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	admin, exist := m.admins[username]
+	if !exist {
+		return nil, neocortex.ErrAdminNotExist
+	}
+
+	delete(m.admins, username)
+	return &admin, nil
+}
@@ -1,14 +1,315 @@
+// Package memory is a repository that lives into the memory of the process, it is safe for concurrent
+// use and it is the reference implementation of neocortex.Repository (useful for tests and small bots)
 package memory
 
 import (
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+const (
+	intentsBox     = "intents"
+	entitiesBox    = "entities"
+	nodesBox       = "nodes"
+	contextVarsBox = "context_vars"
 )
 
 type InMemoryRepo struct {
-	dialogs []*neocortex.Dialog
+	mu          sync.RWMutex
+	dialogs     map[string]*neocortex.Dialog
+	views       map[string]*neocortex.View
+	viewsOrder  []string
+	collections map[string][]string
+	actionVars  map[string]string
+	admins      map[string]neocortex.Admin
+	apiKeys     map[string]neocortex.APIKey
+	revoked     map[string]time.Time
+}
+
+func New() *InMemoryRepo {
+	repo := &InMemoryRepo{}
+	repo.init()
+	return repo
+}
+
+// init lets to use the zero value of the repo, it must be called with the lock
+func (m *InMemoryRepo) init() {
+	if m.dialogs != nil {
+		return
+	}
+	m.dialogs = map[string]*neocortex.Dialog{}
+	m.views = map[string]*neocortex.View{}
+	m.viewsOrder = []string{}
+	m.collections = map[string][]string{}
+	m.actionVars = map[string]string{}
+	m.admins = map[string]neocortex.Admin{}
+	m.apiKeys = map[string]neocortex.APIKey{}
+	m.revoked = map[string]time.Time{}
+}
+
+func copyDialog(dialog *neocortex.Dialog) *neocortex.Dialog {
+	copied := *dialog
+	copied.Ins = append([]*neocortex.InputRecord{}, dialog.Ins...)
+	copied.Outs = append([]*neocortex.OutputRecord{}, dialog.Outs...)
+	copied.Contexts = append([]*neocortex.ContextRecord{}, dialog.Contexts...)
+	return &copied
+}
+
+func copyView(view *neocortex.View) *neocortex.View {
+	copied := *view
+	copied.Styles = append([]neocortex.ViewStyle{}, view.Styles...)
+	copied.Classes = append([]neocortex.ViewClass{}, view.Classes...)
+	copied.Children = append([]*neocortex.View{}, view.Children...)
+	return &copied
 }
 
+// SaveDialog inserts the dialog or replaces the dialog with the same id
 func (m *InMemoryRepo) SaveDialog(dialog *neocortex.Dialog) error {
-	m.dialogs = append(m.dialogs, dialog)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+
+	if dialog.ID == "" {
+		dialog.ID = xid.New().String()
+	}
+
+	m.dialogs[dialog.ID] = copyDialog(dialog)
+	return nil
+}
+
+func (m *InMemoryRepo) GetDialogByID(id string) (*neocortex.Dialog, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dialog, exist := m.dialogs[id]
+	if !exist {
+		return nil, neocortex.ErrDialogNotExist
+	}
+	return copyDialog(dialog), nil
+}
+
+// dialogsInFrame returns the dialogs that start into the frame, sorted by its start, it must be called with the lock
+func (m *InMemoryRepo) dialogsInFrame(frame neocortex.TimeFrame, view *neocortex.View) []*neocortex.Dialog {
+	dialogs := make([]*neocortex.Dialog, 0)
+	for _, dialog := range m.dialogs {
+		if !frame.Contains(dialog.StartAt) {
+			continue
+		}
+		if view != nil && !view.Matches(dialog) {
+			continue
+		}
+		dialogs = append(dialogs, dialog)
+	}
+
+	sort.Slice(dialogs, func(i, j int) bool {
+		if dialogs[i].StartAt.Equal(dialogs[j].StartAt) {
+			return dialogs[i].ID < dialogs[j].ID
+		}
+		return dialogs[i].StartAt.Before(dialogs[j].StartAt)
+	})
+
+	return dialogs
+}
+
+func (m *InMemoryRepo) copyPage(frame neocortex.TimeFrame, dialogs []*neocortex.Dialog) []*neocortex.Dialog {
+	page := frame.Paginate(dialogs)
+	copied := make([]*neocortex.Dialog, 0, len(page))
+	for _, dialog := range page {
+		copied = append(copied, copyDialog(dialog))
+	}
+	return copied
+}
+
+func (m *InMemoryRepo) AllDialogs(frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.copyPage(frame, m.dialogsInFrame(frame, nil)), nil
+}
+
+func (m *InMemoryRepo) DeleteDialog(id string) (*neocortex.Dialog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dialog, exist := m.dialogs[id]
+	if !exist {
+		return nil, neocortex.ErrDialogNotExist
+	}
+	delete(m.dialogs, id)
+	return copyDialog(dialog), nil
+}
+
+func (m *InMemoryRepo) DialogsByView(viewID string, frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	view, exist := m.views[viewID]
+	if !exist {
+		return nil, neocortex.ErrViewNotExist
+	}
+
+	return m.copyPage(frame, m.dialogsInFrame(frame, view)), nil
+}
+
+func (m *InMemoryRepo) Summary(frame neocortex.TimeFrame) (*neocortex.Summary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return neocortex.NewSummary(m.dialogsInFrame(frame, nil)), nil
+}
+
+func (m *InMemoryRepo) register(box, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+
+	for _, v := range m.collections[box] {
+		if v == value {
+			return nil
+		}
+	}
+	m.collections[box] = append(m.collections[box], value)
 	return nil
 }
+
+func (m *InMemoryRepo) values(box string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string{}, m.collections[box]...)
+}
+
+func (m *InMemoryRepo) RegisterIntent(intent string) error {
+	return m.register(intentsBox, intent)
+}
+
+func (m *InMemoryRepo) RegisterEntity(entity string) error {
+	return m.register(entitiesBox, entity)
+}
+
+func (m *InMemoryRepo) RegisterDialogNode(name string) error {
+	return m.register(nodesBox, name)
+}
+
+func (m *InMemoryRepo) RegisterContextVar(value string) error {
+	return m.register(contextVarsBox, value)
+}
+
+func (m *InMemoryRepo) Intents() []string {
+	return m.values(intentsBox)
+}
+
+func (m *InMemoryRepo) Entities() []string {
+	return m.values(entitiesBox)
+}
+
+func (m *InMemoryRepo) DialogNodes() []string {
+	return m.values(nodesBox)
+}
+
+func (m *InMemoryRepo) ContextVars() []string {
+	return m.values(contextVarsBox)
+}
+
+func (m *InMemoryRepo) SaveView(view *neocortex.View) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+
+	if view.ID == "" {
+		view.ID = xid.New().String()
+	}
+
+	if _, exist := m.views[view.ID]; !exist {
+		m.viewsOrder = append(m.viewsOrder, view.ID)
+	}
+	m.views[view.ID] = copyView(view)
+	return nil
+}
+
+func (m *InMemoryRepo) GetViewByID(id string) (*neocortex.View, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	view, exist := m.views[id]
+	if !exist {
+		return nil, neocortex.ErrViewNotExist
+	}
+	return copyView(view), nil
+}
+
+// FindViewByName returns the views with exactly that name
+func (m *InMemoryRepo) FindViewByName(name string) ([]*neocortex.View, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	views := make([]*neocortex.View, 0)
+	for _, id := range m.viewsOrder {
+		if view := m.views[id]; view.Name == name {
+			views = append(views, copyView(view))
+		}
+	}
+	return views, nil
+}
+
+func (m *InMemoryRepo) AllViews() ([]*neocortex.View, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	views := make([]*neocortex.View, 0, len(m.viewsOrder))
+	for _, id := range m.viewsOrder {
+		views = append(views, copyView(m.views[id]))
+	}
+	return views, nil
+}
+
+func (m *InMemoryRepo) UpdateView(view *neocortex.View) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exist := m.views[view.ID]; !exist {
+		return neocortex.ErrViewNotExist
+	}
+	m.views[view.ID] = copyView(view)
+	return nil
+}
+
+func (m *InMemoryRepo) DeleteView(id string) (*neocortex.View, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	view, exist := m.views[id]
+	if !exist {
+		return nil, neocortex.ErrViewNotExist
+	}
+
+	delete(m.views, id)
+	for i, v := range m.viewsOrder {
+		if v == id {
+			m.viewsOrder = append(m.viewsOrder[:i], m.viewsOrder[i+1:]...)
+			break
+		}
+	}
+	return view, nil
+}
+
+func (m *InMemoryRepo) SetActionVar(name string, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+
+	m.actionVars[name] = value
+	return nil
+}
+
+// GetActionVar returns an empty value if the var doesn't exist
+func (m *InMemoryRepo) GetActionVar(name string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.actionVars[name], nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/repositories/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) neocortex.Repository {
+		return New()
+	})
+}
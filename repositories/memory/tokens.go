@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+// SaveAPIKey inserts the key or replaces the key with the same id
+func (m *InMemoryRepo) SaveAPIKey(key *neocortex.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+
+	copied := *key
+	copied.Scopes = append([]neocortex.Scope{}, key.Scopes...)
+	m.apiKeys[key.ID] = copied
+	return nil
+}
+
+func (m *InMemoryRepo) GetAPIKey(id string) (*neocortex.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, exist := m.apiKeys[id]
+	if !exist {
+		return nil, neocortex.ErrAPIKeyNotExist
+	}
+	return &key, nil
+}
+
+// AllAPIKeys returns the keys in the order they were created
+func (m *InMemoryRepo) AllAPIKeys() ([]*neocortex.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]*neocortex.APIKey, 0, len(m.apiKeys))
+	for _, key := range m.apiKeys {
+		key := key
+		keys = append(keys, &key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// RevokeToken drops the revocations that already expired
+func (m *InMemoryRepo) RevokeToken(id string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+
+	now := time.Now()
+	for other, at := range m.revoked {
+		if at.Before(now) {
+			delete(m.revoked, other)
+		}
+	}
+
+	m.revoked[id] = expiresAt
+	return nil
+}
+
+func (m *InMemoryRepo) IsTokenRevoked(id string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, revoked := m.revoked[id]
+	return revoked, nil
+}
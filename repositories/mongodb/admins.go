@@ -0,0 +1,60 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/minskylab/neocortex"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveAdmin inserts the admin or replaces the admin with the same username
+func (repo *Repository) SaveAdmin(admin *neocortex.Admin) error {
+	_, err := repo.admins.ReplaceOne(context.Background(), bson.M{"username": admin.Username}, admin, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (repo *Repository) GetAdmin(username string) (*neocortex.Admin, error) {
+	admin := new(neocortex.Admin)
+	if err := repo.admins.FindOne(context.Background(), bson.M{"username": username}).Decode(admin); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, neocortex.ErrAdminNotExist
+		}
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// AllAdmins returns the admins sorted by its username
+func (repo *Repository) AllAdmins() ([]*neocortex.Admin, error) {
+	c, err := repo.admins.Find(context.Background(), bson.M{}, options.Find().SetSort(bson.M{"username": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close(context.Background())
+
+	admins := make([]*neocortex.Admin, 0)
+	for c.Next(context.Background()) {
+		admin := new(neocortex.Admin)
+		if err := c.Decode(admin); err != nil {
+			return nil, err
+		}
+		admins = append(admins, admin)
+	}
+
+	return admins, c.Err()
+}
+
+func (repo *Repository) DeleteAdmin(username string) (*neocortex.Admin, error) {
+	admin := new(neocortex.Admin)
+	if err := repo.admins.FindOneAndDelete(context.Background(), bson.M{"username": username}).Decode(admin); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, neocortex.ErrAdminNotExist
+		}
+		return nil, err
+	}
+
+	return admin, nil
+}
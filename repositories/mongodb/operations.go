@@ -2,12 +2,8 @@ package mongodb
 
 import (
 	"context"
-	"time"
-
-	"fmt"
-
-	"github.com/jinzhu/now"
 
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,14 +13,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// SaveDialog inserts the dialog or replaces the dialog with the same id
 func (repo *Repository) SaveDialog(dialog *neocortex.Dialog) error {
 	if dialog.ID == "" {
 		dialog.ID = xid.New().String()
 	}
 
-	dialog.LastActivity = time.Now()
-
-	_, err := repo.dialogs.InsertOne(context.Background(), dialog)
+	_, err := repo.dialogs.ReplaceOne(context.Background(), bson.M{"id": dialog.ID}, dialog, options.Replace().SetUpsert(true))
 	if err != nil {
 		return err
 	}
@@ -35,65 +30,46 @@ func (repo *Repository) SaveDialog(dialog *neocortex.Dialog) error {
 func (repo *Repository) GetDialogByID(id string) (*neocortex.Dialog, error) {
 	dialog := new(neocortex.Dialog)
 	if err := repo.dialogs.FindOne(context.Background(), bson.M{"id": id}).Decode(dialog); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, neocortex.ErrDialogNotExist
+		}
 		return nil, err
 	}
 
 	return dialog, nil
 }
 
-func (repo *Repository) AllDialogs(frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
-	from := frame.From
-	to := frame.To
-
-	switch frame.Preset {
-	case neocortex.DayPreset:
-		from = now.BeginningOfDay()
-		to = now.EndOfDay()
-	case neocortex.WeekPreset:
-		from = now.BeginningOfWeek()
-		to = now.EndOfWeek()
-	case neocortex.MonthPreset:
-		from = now.BeginningOfMonth()
-		to = now.EndOfMonth()
-	case neocortex.YearPreset:
-		from = now.BeginningOfYear()
-		to = now.EndOfYear()
-		// default:
-		// 	return nil, errors.New("invalid preset, please choose between: day, week, month or year")
-	}
-
-	// * Mongo pleaseeee
-	from = from.Truncate(time.Second)
-	to = to.Truncate(time.Second)
+// frameFilter returns the filter of the dialogs that start into the frame, a zero bound means no limit
+func frameFilter(frame neocortex.TimeFrame) bson.M {
+	from, to := frame.Bounds()
 
-	filter := bson.M{
-		"start_at": bson.M{
-			"$gte": primitive.DateTime(from.UnixNano() / 1000000),
-			"$lte": primitive.DateTime(to.UnixNano() / 1000000),
-		},
+	startAt := bson.M{}
+	if !from.IsZero() {
+		startAt["$gte"] = primitive.DateTime(from.UnixNano() / 1000000)
 	}
-
-	size := int64(frame.PageSize)
-	if size <= 0 {
-		size = 20
+	if !to.IsZero() {
+		startAt["$lte"] = primitive.DateTime(to.UnixNano() / 1000000)
 	}
 
-	skips := int64(size * int64(frame.PageNum-1))
-	if skips <= 0 {
-		skips = 0
+	if len(startAt) == 0 {
+		return bson.M{}
 	}
 
-	opts := options.Find().SetLimit(size).SetSkip(skips)
+	return bson.M{"start_at": startAt}
+}
 
-	cursor, err := repo.dialogs.Find(
-		context.Background(),
-		filter,
-		opts,
-	)
+// findDialogs returns the dialogs of the frame sorted by its start, opts can page the query
+func (repo *Repository) findDialogs(frame neocortex.TimeFrame, opts *options.FindOptions) ([]*neocortex.Dialog, error) {
+	opts.SetSort(bson.D{
+		{Key: "start_at", Value: 1},
+		{Key: "id", Value: 1},
+	})
 
+	cursor, err := repo.dialogs.Find(context.Background(), frameFilter(frame), opts)
 	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(context.Background())
 
 	dialogs := make([]*neocortex.Dialog, 0)
 	for cursor.Next(context.Background()) {
@@ -103,33 +79,36 @@ func (repo *Repository) AllDialogs(frame neocortex.TimeFrame) ([]*neocortex.Dial
 			if err := cursor.Decode(&m); err != nil {
 				continue
 			}
+
+			// * the dialogs of old versions can't be decoded, only its metadata is recovered
+			dialog = new(neocortex.Dialog)
 			dialog.ID, _ = m["id"].(string)
 
 			start, _ := m["start_at"].(primitive.DateTime)
 			end, _ := m["end_at"].(primitive.DateTime)
 
-			// * Mongo pleaseeee
-			s := time.Unix(int64(start)/1000, int64(start)%1000*1000000)
-			e := time.Unix(int64(end)/1000, int64(end)%1000*1000000)
-
-			dialog.StartAt = s
-			dialog.EndAt = e
+			dialog.StartAt = start.Time()
+			dialog.EndAt = end.Time()
 			dialog.Ins = []*neocortex.InputRecord{}
 			dialog.Outs = []*neocortex.OutputRecord{}
 			dialog.Contexts = []*neocortex.ContextRecord{}
 		}
 
-		if dialog.Ins != nil && dialog.Outs != nil {
-			if len(dialog.Ins) != 0 && len(dialog.Outs) != 0 {
-				dialogs = append(dialogs, dialog)
-			}
-		}
+		dialogs = append(dialogs, dialog)
+	}
 
+	if err := cursor.Err(); err != nil {
+		return nil, err
 	}
 
 	return dialogs, nil
 }
 
+func (repo *Repository) AllDialogs(frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
+	offset, limit := frame.Page()
+	return repo.findDialogs(frame, options.Find().SetSkip(int64(offset)).SetLimit(int64(limit)))
+}
+
 func (repo *Repository) DeleteDialog(id string) (*neocortex.Dialog, error) {
 	dialog, err := repo.GetDialogByID(id)
 	if err != nil {
@@ -143,36 +122,14 @@ func (repo *Repository) DeleteDialog(id string) (*neocortex.Dialog, error) {
 	return dialog, nil
 }
 
-func checkDialogInView(dialog *neocortex.Dialog, view *neocortex.View) bool {
-	for _, c := range view.Classes {
-		switch c.Type {
-		case neocortex.EntityClass:
-			if dialog.HasEntity(c.Value) {
-				return true
-			}
-		case neocortex.IntentClass:
-			if dialog.HasIntent(c.Value) {
-				return true
-			}
-		case neocortex.DialogNodeClass:
-			if dialog.HasDialogNode(c.Value) {
-				return true
-			}
-		default:
-			return false
-		}
-	}
-
-	return false
-}
-
+// DialogsByView filters the dialogs of the frame before paging them
 func (repo *Repository) DialogsByView(viewID string, frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
 	view, err := repo.GetViewByID(viewID)
 	if err != nil {
 		return nil, err
 	}
 
-	dialogs, err := repo.AllDialogs(frame)
+	dialogs, err := repo.findDialogs(frame, options.Find())
 	if err != nil {
 		return nil, err
 	}
@@ -180,152 +137,74 @@ func (repo *Repository) DialogsByView(viewID string, frame neocortex.TimeFrame)
 	filteredDialogs := make([]*neocortex.Dialog, 0)
 
 	for _, dialog := range dialogs {
-		if checkDialogInView(dialog, view) {
+		if view.Matches(dialog) {
 			filteredDialogs = append(filteredDialogs, dialog)
 		}
 	}
 
-	return filteredDialogs, nil
+	return frame.Paginate(filteredDialogs), nil
 }
 
-func (repo *Repository) RegisterIntent(intent string) error {
-	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "intents"}).Decode(coll)
-	if err != nil {
-		return err
-	}
-
-	for _, v := range coll.Values {
-		if v == intent {
-			return nil
-		}
-	}
-
-	coll.Values = append(coll.Values, intent)
-
-	_, err = repo.collections.UpdateOne(context.Background(), bson.M{"box": "intents"}, bson.M{"$set": bson.M{"values": coll.Values}})
-	if err != nil {
-		return err
-	}
-
-	return nil
+// register adds the value to the box, $addToSet keeps the values without duplicates (and it's atomic)
+func (repo *Repository) register(box, value string) error {
+	_, err := repo.collections.UpdateOne(
+		context.Background(),
+		bson.M{"box": box},
+		bson.M{"$addToSet": bson.M{"values": value}},
+		options.Update().SetUpsert(true),
+	)
+	return err
 }
 
-func (repo *Repository) RegisterEntity(entity string) error {
+func (repo *Repository) values(box string) []string {
 	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "entities"}).Decode(coll)
-	if err != nil {
-		return err
-	}
-
-	for _, v := range coll.Values {
-		if v == entity {
-			return nil
-		}
+	err := repo.collections.FindOne(context.Background(), bson.M{"box": box}).Decode(coll)
+	if err != nil || coll.Values == nil {
+		return []string{}
 	}
 
-	coll.Values = append(coll.Values, entity)
+	return coll.Values
+}
 
-	_, err = repo.collections.UpdateOne(context.Background(), bson.M{"box": "entities"}, bson.M{"$set": bson.M{"values": coll.Values}})
-	if err != nil {
-		return err
-	}
+func (repo *Repository) RegisterIntent(intent string) error {
+	return repo.register("intents", intent)
+}
 
-	return nil
+func (repo *Repository) RegisterEntity(entity string) error {
+	return repo.register("entities", entity)
 }
 
 func (repo *Repository) RegisterDialogNode(name string) error {
-	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "nodes"}).Decode(coll)
-	if err != nil {
-		return err
-	}
-
-	for _, v := range coll.Values {
-		if v == name {
-			return nil
-		}
-	}
-
-	coll.Values = append(coll.Values, name)
-
-	_, err = repo.collections.UpdateOne(context.Background(), bson.M{"box": "nodes"}, bson.M{"$set": bson.M{"values": coll.Values}})
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return repo.register("nodes", name)
 }
 
 func (repo *Repository) RegisterContextVar(value string) error {
-	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "context_vars"}).Decode(coll)
-	if err != nil {
-		return err
-	}
-
-	for _, v := range coll.Values {
-		if v == value {
-			return nil
-		}
-	}
-
-	coll.Values = append(coll.Values, value)
-
-	_, err = repo.collections.UpdateOne(context.Background(), bson.M{"box": "context_vars"}, bson.M{"$set": bson.M{"values": coll.Values}})
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return repo.register("context_vars", value)
 }
 
 func (repo *Repository) Intents() []string {
-	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "intents"}).Decode(coll)
-	if err != nil {
-		return nil
-	}
-
-	return coll.Values
+	return repo.values("intents")
 }
 
 func (repo *Repository) Entities() []string {
-	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "entities"}).Decode(coll)
-	if err != nil {
-		return nil
-	}
-
-	return coll.Values
+	return repo.values("entities")
 }
 
 func (repo *Repository) DialogNodes() []string {
-	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "nodes"}).Decode(coll)
-	if err != nil {
-		return nil
-	}
-
-	return coll.Values
+	return repo.values("nodes")
 }
 
 func (repo *Repository) ContextVars() []string {
-	coll := new(collection)
-	err := repo.collections.FindOne(context.Background(), bson.M{"box": "context_vars"}).Decode(coll)
-	if err != nil {
-		return nil
-	}
-
-	return coll.Values
+	return repo.values("context_vars")
 }
 
+// SaveView inserts the view or replaces the view with the same id
 func (repo *Repository) SaveView(view *neocortex.View) error {
 	if view.ID == "" {
 		view.ID = xid.New().String()
 	}
 
-	_, err := repo.views.InsertOne(context.Background(), view)
+	_, err := repo.views.ReplaceOne(context.Background(), bson.M{"id": view.ID}, view, options.Replace().SetUpsert(true))
 	if err != nil {
 		return err
 	}
@@ -336,24 +215,24 @@ func (repo *Repository) SaveView(view *neocortex.View) error {
 func (repo *Repository) GetViewByID(id string) (*neocortex.View, error) {
 	view := new(neocortex.View)
 	if err := repo.views.FindOne(context.Background(), bson.M{"id": id}).Decode(view); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, neocortex.ErrViewNotExist
+		}
 		return nil, err
 	}
 
 	return view, nil
 }
 
-func (repo *Repository) FindViewByName(name string) ([]*neocortex.View, error) {
-
-	c, err := repo.views.Find(context.Background(), bson.M{"name": name})
+// findViews returns the views of the filter in the order they were created
+func (repo *Repository) findViews(filter bson.M) ([]*neocortex.View, error) {
+	c, err := repo.views.Find(context.Background(), filter, options.Find().SetSort(bson.M{"_id": 1}))
 	if err != nil {
 		return nil, err
 	}
+	defer c.Close(context.Background())
 
 	views := make([]*neocortex.View, 0)
-	if err != nil {
-		return nil, err
-	}
-
 	for c.Next(context.Background()) {
 		view := new(neocortex.View)
 		if err := c.Decode(view); err != nil {
@@ -362,189 +241,73 @@ func (repo *Repository) FindViewByName(name string) ([]*neocortex.View, error) {
 		views = append(views, view)
 	}
 
-	return views, nil
+	return views, c.Err()
+}
+
+// FindViewByName returns the views with exactly that name
+func (repo *Repository) FindViewByName(name string) ([]*neocortex.View, error) {
+	return repo.findViews(bson.M{"name": name})
 }
 
 func (repo *Repository) AllViews() ([]*neocortex.View, error) {
-	c, err := repo.views.Find(context.Background(), bson.M{})
-	if err != nil {
-		return nil, err
-	}
+	return repo.findViews(bson.M{})
+}
 
-	views := make([]*neocortex.View, 0)
+func (repo *Repository) UpdateView(view *neocortex.View) error {
+	res, err := repo.views.ReplaceOne(context.Background(), bson.M{"id": view.ID}, view)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	for c.Next(context.Background()) {
-		view := new(neocortex.View)
-		if err := c.Decode(view); err != nil {
-			return nil, err
-		}
-		views = append(views, view)
+	if res.MatchedCount == 0 {
+		return neocortex.ErrViewNotExist
 	}
 
-	return views, nil
-}
-
-//! MAKE GOOD UPDATE IN UPDATE VIEW
-func (repo *Repository) UpdateView(view *neocortex.View) error {
 	return nil
-	// _, err := repo.views.UpdateOne(context.Background(), bson.M{"id": view.ID}, bson.M{
-	// 	"name":     view.Name,
-	// 	"styles":   view.Styles,
-	// 	"classes":  view.Classes,
-	// 	"children": view.Children,
-	// })
-
-	// return err
 }
 
 func (repo *Repository) DeleteView(id string) (*neocortex.View, error) {
-	view, err := repo.GetViewByID(id)
-	if err != nil {
-		return nil, err
-	}
-
-	del, err := repo.views.DeleteOne(context.Background(), bson.M{"id": view.ID})
-	if err != nil {
+	view := new(neocortex.View)
+	if err := repo.views.FindOneAndDelete(context.Background(), bson.M{"id": id}).Decode(view); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, neocortex.ErrViewNotExist
+		}
 		return nil, err
 	}
-	fmt.Println("del.DeletedCount =", del.DeletedCount)
 
 	return view, nil
-
 }
 
 func (repo *Repository) SetActionVar(name string, value string) error {
-	act := new(action)
-	err := repo.actions.FindOne(context.Background(), bson.M{"name": "envs"}).Decode(act)
-	if err != nil {
-		return err
-	}
-
-	act.Vars[name] = value
-
-	_, err = repo.actions.UpdateOne(context.Background(), bson.M{"name": "envs"}, bson.M{"$set": bson.M{"vars": act.Vars}})
+	_, err := repo.actions.UpdateOne(
+		context.Background(),
+		bson.M{"name": "envs"},
+		bson.M{"$set": bson.M{"vars." + name: value}},
+		options.Update().SetUpsert(true),
+	)
 	return err
 }
 
+// GetActionVar returns an empty value if the var doesn't exist
 func (repo *Repository) GetActionVar(name string) (string, error) {
 	act := new(action)
 	err := repo.actions.FindOne(context.Background(), bson.M{"name": "envs"}).Decode(act)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
 		return "", err
 	}
 
 	return act.Vars[name], nil
 }
 
+// Summary uses the same math of every repository (see neocortex.NewSummary) over all dialogs of the frame
 func (repo *Repository) Summary(frame neocortex.TimeFrame) (*neocortex.Summary, error) {
-
-	summary := neocortex.Summary{}
-	summary.UsersByTimezone = map[string]neocortex.UsersSummary{}
-
-	from := frame.From
-	to := frame.To
-
-	switch frame.Preset {
-	case neocortex.DayPreset:
-		from = now.BeginningOfDay()
-		to = now.EndOfDay()
-	case neocortex.WeekPreset:
-		from = now.BeginningOfWeek()
-		to = now.EndOfWeek()
-	case neocortex.MonthPreset:
-		from = now.BeginningOfMonth()
-		to = now.EndOfMonth()
-	case neocortex.YearPreset:
-		from = now.BeginningOfYear()
-		to = now.EndOfYear()
-		// default:
-		// 	return nil, errors.New("invalid preset, please choose between: day, week, month or year")
-	}
-
-	// * Mongo pleaseeee
-
-	filter := bson.M{
-		"start_at": bson.M{
-			"$gte": primitive.DateTime(from.UnixNano() / 1000000),
-			"$lte": primitive.DateTime(to.UnixNano() / 1000000),
-		},
-	}
-
-	fmt.Println(" ======== SUMMARY ======== ")
-	fmt.Printf("From: %s\tTo: %s\n", from, to)
-
-	opts := options.Find().SetLimit(1e8) // 100000000
-
-	fmt.Printf("opts: %v\n", opts.Limit)
-
-	cursor, err := repo.dialogs.Find(context.Background(), filter, opts)
+	dialogs, err := repo.findDialogs(frame, options.Find())
 	if err != nil {
 		return nil, err
 	}
 
-	performanceAccum := 0.0
-	totalCorrectDialogs := int64(0)
-
-	usersByTimezone := map[string]map[string]int{}
-
-	for cursor.Next(context.Background()) {
-		dialog := new(neocortex.Dialog)
-		err = cursor.Decode(dialog)
-		if err != nil {
-			continue
-		}
-
-		if len(dialog.Contexts) > 0 {
-			c := dialog.Contexts[0]
-			if usersByTimezone[c.Context.Person.Timezone] == nil {
-				usersByTimezone[c.Context.Person.Timezone] = map[string]int{}
-			}
-			usersByTimezone[c.Context.Person.Timezone][c.Context.Person.Name]++
-		}
-
-		if len(dialog.Ins) > 0 {
-			performanceAccum += dialog.Performance
-			totalCorrectDialogs++
-		}
-	}
-
-	if totalCorrectDialogs == 0 {
-		return &neocortex.Summary{
-			TotalDialogs:    0,
-			TotalUsers:      0,
-			RecurrentUsers:  0,
-			PerformanceMean: 0.0,
-			// UsersByTimezone: map[string,
-		}, nil
-	}
-
-	totalUsers := int64(0)
-	totalRecurrent := int64(0)
-
-	for timezone, totalUsersByTimezone := range usersByTimezone {
-		recurrent := 0
-		for _, r := range totalUsersByTimezone {
-			if r > 1 {
-				recurrent++
-			}
-		}
-
-		summary.UsersByTimezone[timezone] = neocortex.UsersSummary{
-			Recurrents: int64(recurrent),
-			News:       int64(len(totalUsersByTimezone) - recurrent),
-		}
-
-		totalUsers += int64(len(totalUsersByTimezone))
-		totalRecurrent += int64(recurrent)
-	}
-
-	summary.TotalUsers = totalUsers
-	summary.RecurrentUsers = totalRecurrent
-	summary.TotalDialogs = totalCorrectDialogs
-	summary.PerformanceMean = performanceAccum / float64(totalCorrectDialogs)
-
-	return &summary, nil
+	return neocortex.NewSummary(dialogs), nil
 }
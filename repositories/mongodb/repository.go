@@ -14,6 +14,9 @@ type Repository struct {
 	views       *mongo.Collection
 	actions     *mongo.Collection
 	collections *mongo.Collection
+	admins      *mongo.Collection
+	apiKeys     *mongo.Collection
+	revoked     *mongo.Collection
 }
 
 type collection struct {
@@ -38,10 +41,18 @@ func New(uri string) (*Repository, error) {
 		return nil, err
 	}
 
-	dialogs := client.Database("neocortexdev").Collection("dialogs")
-	views := client.Database("neocortexdev").Collection("views")
-	actions := client.Database("neocortexdev").Collection("actions")
-	collections := client.Database("neocortexdev").Collection("collections")
+	return newRepository(client, client.Database("neocortexdev"))
+}
+
+// newRepository uses the collections of the database, the tests use their own databases
+func newRepository(client *mongo.Client, db *mongo.Database) (*Repository, error) {
+	dialogs := db.Collection("dialogs")
+	views := db.Collection("views")
+	actions := db.Collection("actions")
+	collections := db.Collection("collections")
+	admins := db.Collection("admins")
+	apiKeys := db.Collection("api_keys")
+	revoked := db.Collection("revoked_tokens")
 
 	// * Creating different 'boxes' for intents, entities, dialog nodes and context variables
 
@@ -88,5 +99,8 @@ func New(uri string) (*Repository, error) {
 		views:       views,
 		actions:     actions,
 		collections: collections,
+		admins:      admins,
+		apiKeys:     apiKeys,
+		revoked:     revoked,
 	}, nil
 }
@@ -0,0 +1,58 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/repositories/repositorytest"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// uriEnv is the server of the suite, every test uses its own database and drops it at the end, e.g.
+//
+//	NEOCORTEX_MONGODB_URI=mongodb://localhost:27017 go test ./repositories/mongodb
+const uriEnv = "NEOCORTEX_MONGODB_URI"
+
+func connect(t *testing.T, uri string) *mongo.Client {
+	t.Helper()
+
+	client, err := mongo.NewClient(options.Client().ApplyURI(uri).SetServerSelectionTimeout(3 * time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+
+	if err = client.Ping(context.Background(), nil); err != nil {
+		t.Skipf("mongodb is unavailable at %s: %v", uri, err)
+	}
+	return client
+}
+
+func TestRepository(t *testing.T) {
+	uri := os.Getenv(uriEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", uriEnv)
+	}
+
+	client := connect(t, uri)
+
+	repositorytest.Run(t, func(t *testing.T) neocortex.Repository {
+		db := client.Database(fmt.Sprintf("neocortex_test_%s", xid.New()))
+		t.Cleanup(func() { _ = db.Drop(context.Background()) })
+
+		repo, err := newRepository(client, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
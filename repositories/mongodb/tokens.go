@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/minskylab/neocortex"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type revokedToken struct {
+	ID        string    `bson:"id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// SaveAPIKey inserts the key or replaces the key with the same id
+func (repo *Repository) SaveAPIKey(key *neocortex.APIKey) error {
+	_, err := repo.apiKeys.ReplaceOne(context.Background(), bson.M{"id": key.ID}, key, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (repo *Repository) GetAPIKey(id string) (*neocortex.APIKey, error) {
+	key := new(neocortex.APIKey)
+	if err := repo.apiKeys.FindOne(context.Background(), bson.M{"id": id}).Decode(key); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, neocortex.ErrAPIKeyNotExist
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// AllAPIKeys returns the keys in the order they were created
+func (repo *Repository) AllAPIKeys() ([]*neocortex.APIKey, error) {
+	sort := bson.D{
+		{Key: "created_at", Value: 1},
+		{Key: "id", Value: 1},
+	}
+
+	c, err := repo.apiKeys.Find(context.Background(), bson.M{}, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close(context.Background())
+
+	keys := make([]*neocortex.APIKey, 0)
+	for c.Next(context.Background()) {
+		key := new(neocortex.APIKey)
+		if err := c.Decode(key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, c.Err()
+}
+
+// RevokeToken drops the revocations that already expired
+func (repo *Repository) RevokeToken(id string, expiresAt time.Time) error {
+	now := primitive.DateTime(time.Now().UnixNano() / 1000000)
+	if _, err := repo.revoked.DeleteMany(context.Background(), bson.M{"expires_at": bson.M{"$lt": now}}); err != nil {
+		return err
+	}
+
+	_, err := repo.revoked.ReplaceOne(
+		context.Background(),
+		bson.M{"id": id},
+		revokedToken{ID: id, ExpiresAt: expiresAt},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+func (repo *Repository) IsTokenRevoked(id string) (bool, error) {
+	n, err := repo.revoked.CountDocuments(context.Background(), bson.M{"id": id})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
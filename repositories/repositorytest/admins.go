@@ -0,0 +1,100 @@
+package repositorytest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+func newAdmin(username string, role neocortex.AdminRole) *neocortex.Admin {
+	return &neocortex.Admin{
+		Username:          username,
+		PasswordHash:      "$2a$10$hash-of-" + username,
+		Role:              role,
+		CreatedAt:         base,
+		UpdatedAt:         base,
+		PasswordChangedAt: base,
+	}
+}
+
+func sameAdmin(got, want *neocortex.Admin) bool {
+	return got.Username == want.Username && got.PasswordHash == want.PasswordHash && got.Role == want.Role &&
+		got.Disabled == want.Disabled && got.CreatedAt.Equal(want.CreatedAt) && got.UpdatedAt.Equal(want.UpdatedAt) &&
+		got.PasswordChangedAt.Equal(want.PasswordChangedAt)
+}
+
+// testAdmins runs only for the repositories that implement neocortex.AdminRepository
+func testAdmins(t *testing.T, factory Factory) {
+	repo, ok := factory(t).(neocortex.AdminRepository)
+	if !ok {
+		t.Skip("the repository doesn't implement neocortex.AdminRepository")
+	}
+
+	if _, err := repo.GetAdmin("missing"); !errors.Is(err, neocortex.ErrAdminNotExist) {
+		t.Errorf("GetAdmin of a missing admin: got %v, want ErrAdminNotExist", err)
+	}
+	if _, err := repo.DeleteAdmin("missing"); !errors.Is(err, neocortex.ErrAdminNotExist) {
+		t.Errorf("DeleteAdmin of a missing admin: got %v, want ErrAdminNotExist", err)
+	}
+
+	admins, err := repo.AllAdmins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(admins) != 0 {
+		t.Errorf("AllAdmins must start empty, got %d admins", len(admins))
+	}
+
+	for _, admin := range []*neocortex.Admin{
+		newAdmin("mara", neocortex.AnalystRole),
+		newAdmin("ana", neocortex.OwnerRole),
+		newAdmin("zoe", neocortex.ViewerRole),
+	} {
+		if err := repo.SaveAdmin(admin); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated := newAdmin("mara", neocortex.EditorRole)
+	updated.Disabled = true
+	updated.PasswordHash = "$2a$10$rotated"
+	updated.UpdatedAt = base.Add(time.Hour)
+	updated.PasswordChangedAt = base.Add(time.Hour)
+	if err := repo.SaveAdmin(updated); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetAdmin("mara")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameAdmin(got, updated) {
+		t.Errorf("SaveAdmin must replace the admin with the same username: got %+v, want %+v", got, updated)
+	}
+
+	admins, err = repo.AllAdmins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(admins))
+	for _, admin := range admins {
+		names = append(names, admin.Username)
+	}
+	if want := []string{"ana", "mara", "zoe"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("AllAdmins must be sorted by username: got %v, want %v", names, want)
+	}
+
+	deleted, err := repo.DeleteAdmin("ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.Username != "ana" || deleted.Role != neocortex.OwnerRole {
+		t.Errorf("DeleteAdmin must return the deleted admin, got %+v", deleted)
+	}
+	if _, err := repo.GetAdmin("ana"); !errors.Is(err, neocortex.ErrAdminNotExist) {
+		t.Errorf("GetAdmin after DeleteAdmin: got %v, want ErrAdminNotExist", err)
+	}
+}
@@ -0,0 +1,74 @@
+package repositorytest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testCollections(t *testing.T, factory Factory) {
+	repo := factory(t)
+
+	boxes := []struct {
+		name     string
+		register func(string) error
+		values   func() []string
+	}{
+		{"intents", repo.RegisterIntent, repo.Intents},
+		{"entities", repo.RegisterEntity, repo.Entities},
+		{"nodes", repo.RegisterDialogNode, repo.DialogNodes},
+		{"context vars", repo.RegisterContextVar, repo.ContextVars},
+	}
+
+	for _, box := range boxes {
+		if values := box.values(); len(values) != 0 {
+			t.Errorf("%s must start empty, got %v", box.name, values)
+		}
+	}
+
+	for _, box := range boxes {
+		for _, value := range []string{box.name + "-b", box.name + "-a", box.name + "-b", box.name + "-c"} {
+			if err := box.register(value); err != nil {
+				t.Fatalf("registering %q into %s: %v", value, box.name, err)
+			}
+		}
+	}
+
+	for _, box := range boxes {
+		want := []string{box.name + "-b", box.name + "-a", box.name + "-c"}
+		if got := box.values(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s must keep the order of registration without duplicates: got %v, want %v", box.name, got, want)
+		}
+	}
+}
+
+func testActionVars(t *testing.T, factory Factory) {
+	repo := factory(t)
+
+	value, err := repo.GetActionVar("missing")
+	if err != nil || value != "" {
+		t.Errorf("GetActionVar of a missing var: got %q and %v, want an empty value without error", value, err)
+	}
+
+	steps := []struct{ name, value string }{
+		{"greeting", "hello"},
+		{"farewell", "bye"},
+		{"greeting", "hi"},
+		{"empty", ""},
+	}
+	for _, step := range steps {
+		if err := repo.SetActionVar(step.name, step.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{"greeting": "hi", "farewell": "bye", "empty": ""}
+	for name, expected := range want {
+		value, err := repo.GetActionVar(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Errorf("GetActionVar(%q): got %q, want %q", name, value, expected)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package repositorytest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+// writers is the number of goroutines that write at the same time
+const writers = 16
+
+func testConcurrency(t *testing.T, factory Factory) {
+	repo := factory(t)
+	perWriter := 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter*4)
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				n := w*perWriter + i
+				f := fixture{ID: fmt.Sprintf("d-%03d", n), Start: base.Add(time.Duration(n) * time.Minute), Intent: "buy"}
+
+				if err := repo.SaveDialog(f.dialog()); err != nil {
+					errs <- fmt.Errorf("SaveDialog: %w", err)
+				}
+				// the same dialog saved again by another writer must not be duplicated
+				if err := repo.SaveDialog(f.dialog()); err != nil {
+					errs <- fmt.Errorf("SaveDialog again: %w", err)
+				}
+				if err := repo.RegisterIntent(fmt.Sprintf("intent-%d", n%3)); err != nil {
+					errs <- fmt.Errorf("RegisterIntent: %w", err)
+				}
+				if err := repo.SetActionVar(fmt.Sprintf("var-%d", w), fmt.Sprint(i)); err != nil {
+					errs <- fmt.Errorf("SetActionVar: %w", err)
+				}
+				if _, err := repo.AllDialogs(neocortex.TimeFrame{PageSize: 5}); err != nil {
+					errs <- fmt.Errorf("AllDialogs: %w", err)
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	total := writers * perWriter
+	all, err := repo.AllDialogs(neocortex.TimeFrame{PageSize: total + 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != total {
+		t.Errorf("AllDialogs after concurrent writes: got %d dialogs, want %d", len(all), total)
+	}
+	for i, dialog := range all {
+		if want := fmt.Sprintf("d-%03d", i); dialog.ID != want {
+			t.Errorf("dialog %d: got %s, want %s", i, dialog.ID, want)
+			break
+		}
+	}
+
+	if intents := repo.Intents(); len(intents) != 3 {
+		t.Errorf("Intents after concurrent registers: got %v, want 3 values", intents)
+	}
+
+	for w := 0; w < writers; w++ {
+		value, err := repo.GetActionVar(fmt.Sprintf("var-%d", w))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprint(perWriter - 1); value != want {
+			t.Errorf("action var of writer %d: got %q, want %q", w, value, want)
+		}
+	}
+
+	summary, err := repo.Summary(neocortex.TimeFrame{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.TotalDialogs != int64(total) {
+		t.Errorf("summary after concurrent writes: got %d dialogs, want %d", summary.TotalDialogs, total)
+	}
+}
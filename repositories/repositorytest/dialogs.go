@@ -0,0 +1,209 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+func testDialogs(t *testing.T, factory Factory) {
+	t.Run("SaveAndGet", func(t *testing.T) {
+		repo := factory(t)
+		f := fixture{ID: "dialog", Start: base, Intent: "buy", Entity: "pizza", Node: "order", Variable: "cart", Performance: 0.5}
+		want := f.dialog()
+
+		if err := repo.SaveDialog(f.dialog()); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetDialogByID("dialog")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectDialog(t, got, want)
+	})
+
+	t.Run("GeneratesID", func(t *testing.T) {
+		repo := factory(t)
+		dialog := fixture{Start: base}.dialog()
+		if err := repo.SaveDialog(dialog); err != nil {
+			t.Fatal(err)
+		}
+		if dialog.ID == "" {
+			t.Fatal("SaveDialog must set the id of a dialog without id")
+		}
+		if _, err := repo.GetDialogByID(dialog.ID); err != nil {
+			t.Fatalf("the dialog with generated id can't be found: %v", err)
+		}
+	})
+
+	t.Run("SaveReplaces", func(t *testing.T) {
+		repo := factory(t)
+		save(t, repo, fixture{ID: "dialog", Start: base, Intent: "buy"})
+
+		replaced := fixture{ID: "dialog", Start: base, Intent: "sell", Performance: 1}.dialog()
+		replaced.EndAt = base.Add(time.Hour)
+		if err := repo.SaveDialog(replaced); err != nil {
+			t.Fatal(err)
+		}
+
+		all, err := repo.AllDialogs(neocortex.TimeFrame{})
+		expectIDs(t, "AllDialogs after saving twice", all, err, "dialog")
+
+		got, err := repo.GetDialogByID("dialog")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectDialog(t, got, replaced)
+	})
+
+	t.Run("NotExist", func(t *testing.T) {
+		repo := factory(t)
+		if _, err := repo.GetDialogByID("missing"); !errors.Is(err, neocortex.ErrDialogNotExist) {
+			t.Errorf("GetDialogByID of a missing dialog: got %v, want %v", err, neocortex.ErrDialogNotExist)
+		}
+		if _, err := repo.DeleteDialog("missing"); !errors.Is(err, neocortex.ErrDialogNotExist) {
+			t.Errorf("DeleteDialog of a missing dialog: got %v, want %v", err, neocortex.ErrDialogNotExist)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := factory(t)
+		save(t, repo, fixture{ID: "a", Start: base}, fixture{ID: "b", Start: base.Add(time.Minute)})
+
+		deleted, err := repo.DeleteDialog("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted == nil || deleted.ID != "a" {
+			t.Fatalf("DeleteDialog must return the deleted dialog, got %v", deleted)
+		}
+
+		if _, err := repo.GetDialogByID("a"); !errors.Is(err, neocortex.ErrDialogNotExist) {
+			t.Errorf("GetDialogByID of a deleted dialog: got %v, want %v", err, neocortex.ErrDialogNotExist)
+		}
+
+		all, err := repo.AllDialogs(neocortex.TimeFrame{})
+		expectIDs(t, "AllDialogs after delete", all, err, "b")
+	})
+}
+
+// expectDialog compares the persisted data of the dialogs, the times with Equal (the location may change)
+func expectDialog(t *testing.T, got, want *neocortex.Dialog) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("ID: got %q, want %q", got.ID, want.ID)
+	}
+	expectTime(t, "StartAt", got.StartAt, want.StartAt)
+	expectTime(t, "LastActivity", got.LastActivity, want.LastActivity)
+	expectTime(t, "EndAt", got.EndAt, want.EndAt)
+	if got.Performance != want.Performance {
+		t.Errorf("Performance: got %v, want %v", got.Performance, want.Performance)
+	}
+
+	if len(got.Ins) != len(want.Ins) || len(got.Outs) != len(want.Outs) || len(got.Contexts) != len(want.Contexts) {
+		t.Fatalf("records: got %d ins, %d outs and %d contexts, want %d, %d and %d",
+			len(got.Ins), len(got.Outs), len(got.Contexts), len(want.Ins), len(want.Outs), len(want.Contexts))
+	}
+
+	for i := range want.Ins {
+		g, w := got.Ins[i], want.Ins[i]
+		expectTime(t, "input At", g.At, w.At)
+		if g.Input.Data.Type != w.Input.Data.Type || g.Input.Data.Value != w.Input.Data.Value {
+			t.Errorf("input %d data: got %v, want %v", i, g.Input.Data, w.Input.Data)
+		}
+		expectIntents(t, "input", g.Input.Intents, w.Input.Intents)
+		expectEntities(t, "input", g.Input.Entities, w.Input.Entities)
+	}
+
+	for i := range want.Outs {
+		g, w := got.Outs[i], want.Outs[i]
+		expectTime(t, "output At", g.At, w.At)
+		expectIntents(t, "output", g.Output.Intents, w.Output.Intents)
+		expectEntities(t, "output", g.Output.Entities, w.Output.Entities)
+
+		if len(g.Output.VisitedNodes) != len(w.Output.VisitedNodes) {
+			t.Errorf("output %d nodes: got %d, want %d", i, len(g.Output.VisitedNodes), len(w.Output.VisitedNodes))
+		} else {
+			for j := range w.Output.VisitedNodes {
+				if *g.Output.VisitedNodes[j] != *w.Output.VisitedNodes[j] {
+					t.Errorf("output %d node %d: got %v, want %v", i, j, g.Output.VisitedNodes[j], w.Output.VisitedNodes[j])
+				}
+			}
+		}
+
+		if len(g.Output.Responses) != len(w.Output.Responses) {
+			t.Errorf("output %d responses: got %d, want %d", i, len(g.Output.Responses), len(w.Output.Responses))
+		} else {
+			for j := range w.Output.Responses {
+				gr, wr := g.Output.Responses[j], w.Output.Responses[j]
+				if gr.Type != wr.Type || gr.Value != wr.Value {
+					t.Errorf("output %d response %d: got %v, want %v", i, j, gr, wr)
+				}
+			}
+		}
+	}
+
+	for i := range want.Contexts {
+		g, w := got.Contexts[i], want.Contexts[i]
+		expectTime(t, "context At", g.At, w.At)
+		if g.Context.SessionID != w.Context.SessionID || g.Context.Person != w.Context.Person {
+			t.Errorf("context %d: got %v %v, want %v %v", i, g.Context.SessionID, g.Context.Person, w.Context.SessionID, w.Context.Person)
+		}
+		if len(g.Context.Variables) != len(w.Context.Variables) {
+			t.Errorf("context %d variables: got %v, want %v", i, g.Context.Variables, w.Context.Variables)
+		}
+		for name, value := range w.Context.Variables {
+			if g.Context.Variables[name] != value {
+				t.Errorf("context %d variable %q: got %v, want %v", i, name, g.Context.Variables[name], value)
+			}
+		}
+	}
+}
+
+func expectTime(t *testing.T, what string, got, want time.Time) {
+	t.Helper()
+	if got.IsZero() && want.IsZero() {
+		return
+	}
+	if !got.Equal(want) {
+		t.Errorf("%s: got %v, want %v", what, got, want)
+	}
+}
+
+func expectIntents(t *testing.T, what string, got, want []neocortex.Intent) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s intents: got %v, want %v", what, got, want)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s intent %d: got %v, want %v", what, i, got[i], want[i])
+		}
+	}
+}
+
+func expectEntities(t *testing.T, what string, got, want []neocortex.Entity) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s entities: got %v, want %v", what, got, want)
+		return
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Entity != w.Entity || g.Value != w.Value || g.Confidence != w.Confidence || len(g.Location) != len(w.Location) {
+			t.Errorf("%s entity %d: got %v, want %v", what, i, g, w)
+			continue
+		}
+		for j := range w.Location {
+			if g.Location[j] != w.Location[j] {
+				t.Errorf("%s entity %d location: got %v, want %v", what, i, g.Location, w.Location)
+				break
+			}
+		}
+	}
+}
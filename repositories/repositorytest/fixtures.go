@@ -0,0 +1,146 @@
+package repositorytest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+// base is the start of the fixtures with explicit frames, it's far from now so the presets don't include it
+var base = time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+// fixture describes a dialog with one input, one output and one context
+type fixture struct {
+	ID          string
+	Start       time.Time
+	Person      neocortex.PersonInfo
+	Intent      string
+	Entity      string
+	Node        string
+	Variable    string
+	Performance float64
+	// WithoutInputs saves the dialog without inputs (it isn't counted by the summary)
+	WithoutInputs bool
+}
+
+func (f fixture) dialog() *neocortex.Dialog {
+	start := f.Start.Truncate(time.Millisecond)
+	at := start.Add(time.Second)
+
+	person := f.Person
+	if person.ID == "" && person.Name == "" {
+		person = neocortex.PersonInfo{ID: "person-" + f.ID, Name: "Person " + f.ID, Timezone: "America/Lima", Locale: "es_PE"}
+	}
+
+	variables := map[string]interface{}{"channel": "test"}
+	if f.Variable != "" {
+		variables[f.Variable] = "yes"
+	}
+
+	in := &neocortex.InputRecord{
+		At: at,
+		Input: neocortex.Input{
+			Data:     neocortex.InputData{Type: neocortex.InputText, Value: "hello " + f.ID},
+			Intents:  []neocortex.Intent{},
+			Entities: []neocortex.Entity{},
+		},
+	}
+	if f.Intent != "" {
+		in.Input.Intents = append(in.Input.Intents, neocortex.Intent{Intent: f.Intent, Confidence: 0.9})
+	}
+	if f.Entity != "" {
+		in.Input.Entities = append(in.Input.Entities, neocortex.Entity{
+			Entity:     "thing",
+			Value:      f.Entity,
+			Location:   []int64{6, 6 + int64(len(f.Entity))},
+			Confidence: 1,
+		})
+	}
+
+	out := &neocortex.OutputRecord{
+		At: at,
+		Output: neocortex.Output{
+			Intents:      in.Input.Intents,
+			Entities:     []neocortex.Entity{},
+			VisitedNodes: []*neocortex.DialogNode{},
+			Logs:         []*neocortex.LogMessage{},
+			Responses:    []neocortex.Response{{Type: neocortex.Text, Value: "hi " + f.ID}},
+		},
+	}
+	if f.Node != "" {
+		out.Output.VisitedNodes = append(out.Output.VisitedNodes, &neocortex.DialogNode{Name: f.Node, Title: "Title of " + f.Node})
+	}
+
+	dialog := &neocortex.Dialog{
+		ID:           f.ID,
+		StartAt:      start,
+		LastActivity: at,
+		Ins:          []*neocortex.InputRecord{in},
+		Outs:         []*neocortex.OutputRecord{out},
+		Contexts: []*neocortex.ContextRecord{{
+			At:      at,
+			Context: neocortex.Context{SessionID: "session-" + f.ID, Person: person, Variables: variables},
+		}},
+		Performance: f.Performance,
+	}
+
+	if f.WithoutInputs {
+		dialog.Ins = []*neocortex.InputRecord{}
+	}
+
+	return dialog
+}
+
+// sequence returns n fixtures that start every minute from start, with ids prefix-00, prefix-01, ...
+func sequence(prefix string, start time.Time, n int) []fixture {
+	fixtures := make([]fixture, 0, n)
+	for i := 0; i < n; i++ {
+		fixtures = append(fixtures, fixture{
+			ID:    fmt.Sprintf("%s-%02d", prefix, i),
+			Start: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	return fixtures
+}
+
+func save(t *testing.T, repo neocortex.Repository, fixtures ...fixture) {
+	t.Helper()
+	for _, f := range fixtures {
+		if err := repo.SaveDialog(f.dialog()); err != nil {
+			t.Fatalf("saving dialog %q: %v", f.ID, err)
+		}
+	}
+}
+
+func ids(dialogs []*neocortex.Dialog) []string {
+	result := make([]string, 0, len(dialogs))
+	for _, dialog := range dialogs {
+		result = append(result, dialog.ID)
+	}
+	return result
+}
+
+func expectIDs(t *testing.T, what string, dialogs []*neocortex.Dialog, err error, want ...string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", what, err)
+	}
+	if dialogs == nil {
+		t.Errorf("%s: got a nil slice, want an empty slice", what)
+	}
+	if got := ids(dialogs); !reflect.DeepEqual(got, want) && !(len(got) == 0 && len(want) == 0) {
+		t.Errorf("%s: got dialogs %v, want %v", what, got, want)
+	}
+}
+
+// idsOf returns the ids of the fixtures from i to j (j not included)
+func idsOf(fixtures []fixture, i, j int) []string {
+	result := make([]string, 0, j-i)
+	for _, f := range fixtures[i:j] {
+		result = append(result, f.ID)
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+func testTimeFrames(t *testing.T, factory Factory) {
+	t.Run("Bounds", func(t *testing.T) {
+		repo := factory(t)
+		fixtures := sequence("d", base, 5)
+		save(t, repo, fixtures...)
+
+		all, err := repo.AllDialogs(neocortex.TimeFrame{From: base, To: base.Add(4 * time.Minute)})
+		expectIDs(t, "both bounds are inclusive", all, err, idsOf(fixtures, 0, 5)...)
+
+		all, err = repo.AllDialogs(neocortex.TimeFrame{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)})
+		expectIDs(t, "inner range", all, err, idsOf(fixtures, 1, 4)...)
+
+		all, err = repo.AllDialogs(neocortex.TimeFrame{From: base.Add(90 * time.Second)})
+		expectIDs(t, "without To the range is open", all, err, idsOf(fixtures, 2, 5)...)
+
+		all, err = repo.AllDialogs(neocortex.TimeFrame{})
+		expectIDs(t, "zero frame returns everything", all, err, idsOf(fixtures, 0, 5)...)
+
+		all, err = repo.AllDialogs(neocortex.TimeFrame{From: base.Add(time.Hour), To: base.Add(2 * time.Hour)})
+		expectIDs(t, "empty range", all, err)
+	})
+
+	t.Run("Presets", func(t *testing.T) {
+		repo := factory(t)
+		now := time.Now()
+		save(t, repo,
+			fixture{ID: "now", Start: now},
+			fixture{ID: "past", Start: now.AddDate(-2, 0, 0)},
+			fixture{ID: "future", Start: now.AddDate(2, 0, 0)},
+		)
+
+		presets := []neocortex.TimeFramePreset{
+			neocortex.DayPreset,
+			neocortex.WeekPreset,
+			neocortex.MonthPreset,
+			neocortex.YearPreset,
+		}
+		for _, preset := range presets {
+			// the preset wins over the explicit bounds
+			frame := neocortex.TimeFrame{Preset: preset, From: now.AddDate(-3, 0, 0), To: now.AddDate(3, 0, 0)}
+
+			all, err := repo.AllDialogs(frame)
+			expectIDs(t, "preset "+string(preset), all, err, "now")
+
+			summary, err := repo.Summary(frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary.TotalDialogs != 1 {
+				t.Errorf("summary of preset %s: got %d dialogs, want 1", preset, summary.TotalDialogs)
+			}
+		}
+	})
+}
+
+func testPagination(t *testing.T, factory Factory) {
+	repo := factory(t)
+	fixtures := sequence("d", base, 45)
+
+	// saved in reverse, the dialogs are sorted by its start
+	for i := len(fixtures) - 1; i >= 0; i-- {
+		save(t, repo, fixtures[i])
+	}
+
+	cases := []struct {
+		name  string
+		frame neocortex.TimeFrame
+		want  []string
+	}{
+		{"default size is 20", neocortex.TimeFrame{}, idsOf(fixtures, 0, 20)},
+		{"page 0 is the first page", neocortex.TimeFrame{PageNum: 0, PageSize: 10}, idsOf(fixtures, 0, 10)},
+		{"page 1 is the first page", neocortex.TimeFrame{PageNum: 1, PageSize: 10}, idsOf(fixtures, 0, 10)},
+		{"negative page is the first page", neocortex.TimeFrame{PageNum: -3, PageSize: 10}, idsOf(fixtures, 0, 10)},
+		{"second page", neocortex.TimeFrame{PageNum: 2, PageSize: 10}, idsOf(fixtures, 10, 20)},
+		{"last partial page", neocortex.TimeFrame{PageNum: 5, PageSize: 10}, idsOf(fixtures, 40, 45)},
+		{"after the last page", neocortex.TimeFrame{PageNum: 6, PageSize: 10}, []string{}},
+		{"negative size is the default size", neocortex.TimeFrame{PageNum: 3, PageSize: -1}, idsOf(fixtures, 40, 45)},
+		{"size bigger than the total", neocortex.TimeFrame{PageSize: 100}, idsOf(fixtures, 0, 45)},
+		{"paging into a range", neocortex.TimeFrame{From: base.Add(5 * time.Minute), To: base.Add(14 * time.Minute), PageNum: 2, PageSize: 4}, idsOf(fixtures, 9, 13)},
+	}
+
+	for _, c := range cases {
+		all, err := repo.AllDialogs(c.frame)
+		expectIDs(t, c.name, all, err, c.want...)
+	}
+}
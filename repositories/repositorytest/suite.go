@@ -0,0 +1,41 @@
+// Package repositorytest is a conformance suite for the implementations of neocortex.Repository,
+// every backend must pass it so the dialogs, views and summaries behave the same with any of them, e.g.
+//
+//	func TestRepository(t *testing.T) {
+//		repositorytest.Run(t, func(t *testing.T) neocortex.Repository {
+//			repo, err := boltdb.New(filepath.Join(t.TempDir(), "neocortex.db"))
+//			if err != nil {
+//				t.Fatal(err)
+//			}
+//			t.Cleanup(func() { repo.Close() })
+//			return repo
+//		})
+//	}
+//
+// The times of the fixtures are truncated to milliseconds, the backends may not keep more precision.
+package repositorytest
+
+import (
+	"testing"
+
+	"github.com/minskylab/neocortex"
+)
+
+// Factory returns a new and empty repository for every test, use t.Cleanup to release it
+type Factory func(t *testing.T) neocortex.Repository
+
+// Run runs the whole suite against the repositories created by the factory, the admins and
+// the tokens are tested only if the repository implements neocortex.AdminRepository and neocortex.TokenRepository
+func Run(t *testing.T, factory Factory) {
+	t.Run("Dialogs", func(t *testing.T) { testDialogs(t, factory) })
+	t.Run("TimeFrames", func(t *testing.T) { testTimeFrames(t, factory) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, factory) })
+	t.Run("Views", func(t *testing.T) { testViews(t, factory) })
+	t.Run("DialogsByView", func(t *testing.T) { testDialogsByView(t, factory) })
+	t.Run("Summary", func(t *testing.T) { testSummary(t, factory) })
+	t.Run("Collections", func(t *testing.T) { testCollections(t, factory) })
+	t.Run("ActionVars", func(t *testing.T) { testActionVars(t, factory) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, factory) })
+	t.Run("Admins", func(t *testing.T) { testAdmins(t, factory) })
+	t.Run("Tokens", func(t *testing.T) { testTokens(t, factory) })
+}
@@ -0,0 +1,86 @@
+package repositorytest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+func testSummary(t *testing.T, factory Factory) {
+	t.Run("Empty", func(t *testing.T) {
+		repo := factory(t)
+		summary, err := repo.Summary(neocortex.TimeFrame{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.TotalDialogs != 0 || summary.TotalUsers != 0 || summary.RecurrentUsers != 0 || summary.PerformanceMean != 0 {
+			t.Errorf("summary of an empty repository: got %+v", summary)
+		}
+		if summary.UsersByTimezone == nil || len(summary.UsersByTimezone) != 0 {
+			t.Errorf("UsersByTimezone must be an empty map, got %v", summary.UsersByTimezone)
+		}
+	})
+
+	t.Run("Math", func(t *testing.T) {
+		repo := factory(t)
+
+		ana := neocortex.PersonInfo{ID: "ana", Name: "Ana", Timezone: "America/Lima"}
+		bob := neocortex.PersonInfo{Name: "Bob", Timezone: "America/Lima"}
+		otherBob := neocortex.PersonInfo{ID: "bob-2", Name: "Bob", Timezone: "America/Lima"}
+		eva := neocortex.PersonInfo{ID: "eva", Name: "Eva", Timezone: "Europe/Madrid"}
+
+		save(t, repo,
+			fixture{ID: "1", Start: base, Person: ana, Performance: 0.5},
+			fixture{ID: "2", Start: base.Add(1 * time.Minute), Person: bob, Performance: 1},
+			fixture{ID: "3", Start: base.Add(2 * time.Minute), Person: bob, Performance: 0},
+			// the person is identified by its id, the name is used without id
+			fixture{ID: "4", Start: base.Add(3 * time.Minute), Person: otherBob, Performance: 0.25},
+			// without inputs the dialog isn't counted, but its user is
+			fixture{ID: "5", Start: base.Add(4 * time.Minute), Person: eva, Performance: 100, WithoutInputs: true},
+			// out of the frame
+			fixture{ID: "6", Start: base.Add(time.Hour), Person: ana, Performance: 1},
+		)
+
+		summary, err := repo.Summary(neocortex.TimeFrame{From: base, To: base.Add(10 * time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if summary.TotalDialogs != 4 {
+			t.Errorf("TotalDialogs: got %d, want 4", summary.TotalDialogs)
+		}
+		if summary.TotalUsers != 4 {
+			t.Errorf("TotalUsers: got %d, want 4", summary.TotalUsers)
+		}
+		if summary.RecurrentUsers != 1 {
+			t.Errorf("RecurrentUsers: got %d, want 1", summary.RecurrentUsers)
+		}
+		if math.Abs(summary.PerformanceMean-0.4375) > 1e-9 {
+			t.Errorf("PerformanceMean: got %v, want 0.4375", summary.PerformanceMean)
+		}
+
+		want := map[string]neocortex.UsersSummary{
+			"America/Lima":  {News: 2, Recurrents: 1},
+			"Europe/Madrid": {News: 1, Recurrents: 0},
+		}
+		if len(summary.UsersByTimezone) != len(want) {
+			t.Errorf("UsersByTimezone: got %v, want %v", summary.UsersByTimezone, want)
+		}
+		for timezone, users := range want {
+			if summary.UsersByTimezone[timezone] != users {
+				t.Errorf("UsersByTimezone[%s]: got %+v, want %+v", timezone, summary.UsersByTimezone[timezone], users)
+			}
+		}
+
+		// the whole history makes ana recurrent
+		summary, err = repo.Summary(neocortex.TimeFrame{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.TotalDialogs != 5 || summary.RecurrentUsers != 2 {
+			t.Errorf("summary without frame: got %d dialogs and %d recurrent users, want 5 and 2", summary.TotalDialogs, summary.RecurrentUsers)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package repositorytest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+func newAPIKey(id string, createdAt time.Time, scopes ...neocortex.Scope) *neocortex.APIKey {
+	return &neocortex.APIKey{
+		ID:        id,
+		Name:      "key " + id,
+		Hash:      "hash-of-" + id,
+		Scopes:    scopes,
+		CreatedBy: "ana",
+		CreatedAt: createdAt,
+	}
+}
+
+// testTokens runs only for the repositories that implement neocortex.TokenRepository
+func testTokens(t *testing.T, factory Factory) {
+	repo, ok := factory(t).(neocortex.TokenRepository)
+	if !ok {
+		t.Skip("the repository doesn't implement neocortex.TokenRepository")
+	}
+
+	t.Run("APIKeys", func(t *testing.T) {
+		if _, err := repo.GetAPIKey("missing"); !errors.Is(err, neocortex.ErrAPIKeyNotExist) {
+			t.Errorf("GetAPIKey of a missing key: got %v, want ErrAPIKeyNotExist", err)
+		}
+
+		keys := []*neocortex.APIKey{
+			newAPIKey("k-2", base.Add(2*time.Minute), "dialogs:read"),
+			newAPIKey("k-1", base.Add(time.Minute), "summary:read", "views:write"),
+			newAPIKey("k-3", base.Add(3*time.Minute), "chats:read"),
+		}
+		keys[2].ExpiresAt = base.Add(24 * time.Hour)
+		for _, key := range keys {
+			if err := repo.SaveAPIKey(key); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		revoked := newAPIKey("k-1", base.Add(time.Minute), "summary:read", "views:write")
+		revoked.RevokedAt = base.Add(time.Hour)
+		if err := repo.SaveAPIKey(revoked); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetAPIKey("k-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != revoked.Name || got.Hash != revoked.Hash || got.CreatedBy != revoked.CreatedBy ||
+			!reflect.DeepEqual(got.Scopes, revoked.Scopes) || !got.CreatedAt.Equal(revoked.CreatedAt) ||
+			!got.ExpiresAt.IsZero() || !got.RevokedAt.Equal(revoked.RevokedAt) {
+			t.Errorf("SaveAPIKey must replace the key with the same id: got %+v, want %+v", got, revoked)
+		}
+
+		got, err = repo.GetAPIKey("k-3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.ExpiresAt.Equal(keys[2].ExpiresAt) || !got.RevokedAt.IsZero() {
+			t.Errorf("GetAPIKey must keep the expiration: got %+v, want %+v", got, keys[2])
+		}
+
+		all, err := repo.AllAPIKeys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, 0, len(all))
+		for _, key := range all {
+			ids = append(ids, key.ID)
+		}
+		if want := []string{"k-1", "k-2", "k-3"}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("AllAPIKeys must keep the order of creation: got %v, want %v", ids, want)
+		}
+	})
+
+	t.Run("RevokedTokens", func(t *testing.T) {
+		revoked, err := repo.IsTokenRevoked("token-1")
+		if err != nil || revoked {
+			t.Errorf("IsTokenRevoked of an unknown token: got %v and %v, want false without error", revoked, err)
+		}
+
+		if err := repo.RevokeToken("token-1", time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		// the expired revocations can be dropped, revoking other token must not drop the active ones
+		if err := repo.RevokeToken("token-2", time.Now().Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RevokeToken("token-3", time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range []string{"token-1", "token-3"} {
+			revoked, err := repo.IsTokenRevoked(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !revoked {
+				t.Errorf("IsTokenRevoked(%q): got false, want true", id)
+			}
+		}
+	})
+}
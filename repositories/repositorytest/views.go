@@ -0,0 +1,176 @@
+package repositorytest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+func newView(name string, classes ...neocortex.ViewClass) *neocortex.View {
+	return &neocortex.View{
+		Name:     name,
+		Styles:   []neocortex.ViewStyle{},
+		Classes:  classes,
+		Children: []*neocortex.View{},
+	}
+}
+
+func viewNames(views []*neocortex.View) []string {
+	names := make([]string, 0, len(views))
+	for _, view := range views {
+		names = append(names, view.Name)
+	}
+	return names
+}
+
+func testViews(t *testing.T, factory Factory) {
+	t.Run("CRUD", func(t *testing.T) {
+		repo := factory(t)
+
+		first := newView("buyers", neocortex.ViewClass{Type: neocortex.IntentClass, Value: "buy"})
+		if err := repo.SaveView(first); err != nil {
+			t.Fatal(err)
+		}
+		if first.ID == "" {
+			t.Fatal("SaveView must set the id of a view without id")
+		}
+
+		got, err := repo.GetViewByID(first.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "buyers" || len(got.Classes) != 1 || got.Classes[0] != first.Classes[0] {
+			t.Errorf("GetViewByID: got %+v, want %+v", got, first)
+		}
+
+		first.Name = "customers"
+		first.Classes = append(first.Classes, neocortex.ViewClass{Type: neocortex.EntityClass, Value: "pizza"})
+		if err := repo.UpdateView(first); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = repo.GetViewByID(first.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "customers" || len(got.Classes) != 2 || got.Classes[1] != first.Classes[1] {
+			t.Errorf("GetViewByID after update: got %+v, want %+v", got, first)
+		}
+
+		deleted, err := repo.DeleteView(first.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted == nil || deleted.ID != first.ID {
+			t.Errorf("DeleteView must return the deleted view, got %v", deleted)
+		}
+
+		if _, err := repo.GetViewByID(first.ID); !errors.Is(err, neocortex.ErrViewNotExist) {
+			t.Errorf("GetViewByID of a deleted view: got %v, want %v", err, neocortex.ErrViewNotExist)
+		}
+	})
+
+	t.Run("NotExist", func(t *testing.T) {
+		repo := factory(t)
+		if _, err := repo.GetViewByID("missing"); !errors.Is(err, neocortex.ErrViewNotExist) {
+			t.Errorf("GetViewByID: got %v, want %v", err, neocortex.ErrViewNotExist)
+		}
+		if err := repo.UpdateView(&neocortex.View{ID: "missing", Name: "x"}); !errors.Is(err, neocortex.ErrViewNotExist) {
+			t.Errorf("UpdateView: got %v, want %v", err, neocortex.ErrViewNotExist)
+		}
+		if _, err := repo.DeleteView("missing"); !errors.Is(err, neocortex.ErrViewNotExist) {
+			t.Errorf("DeleteView: got %v, want %v", err, neocortex.ErrViewNotExist)
+		}
+		if _, err := repo.DialogsByView("missing", neocortex.TimeFrame{}); !errors.Is(err, neocortex.ErrViewNotExist) {
+			t.Errorf("DialogsByView: got %v, want %v", err, neocortex.ErrViewNotExist)
+		}
+	})
+
+	t.Run("FindAndOrder", func(t *testing.T) {
+		repo := factory(t)
+		for _, name := range []string{"c", "a", "b", "a"} {
+			if err := repo.SaveView(newView(name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		all, err := repo.AllViews()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := viewNames(all); len(got) != 4 || got[0] != "c" || got[1] != "a" || got[2] != "b" || got[3] != "a" {
+			t.Errorf("AllViews must keep the order of creation: got %v, want [c a b a]", got)
+		}
+
+		found, err := repo.FindViewByName("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 2 || found[0].ID != all[1].ID || found[1].ID != all[3].ID {
+			t.Errorf("FindViewByName: got %v, want the views 1 and 3", viewNames(found))
+		}
+
+		for _, name := range []string{"missing", "A", "aa", ""} {
+			found, err := repo.FindViewByName(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found == nil || len(found) != 0 {
+				t.Errorf("FindViewByName(%q) must be exact and return an empty slice, got %v", name, found)
+			}
+		}
+	})
+}
+
+func testDialogsByView(t *testing.T, factory Factory) {
+	repo := factory(t)
+
+	save(t, repo,
+		fixture{ID: "intent", Start: base, Intent: "buy"},
+		fixture{ID: "entity", Start: base.Add(1 * time.Minute), Entity: "pizza"},
+		fixture{ID: "node", Start: base.Add(2 * time.Minute), Node: "checkout"},
+		fixture{ID: "variable", Start: base.Add(3 * time.Minute), Variable: "cart"},
+		fixture{ID: "nothing", Start: base.Add(4 * time.Minute), Intent: "greet", Entity: "hello", Node: "welcome", Variable: "name"},
+		fixture{ID: "late", Start: base.Add(time.Hour), Intent: "buy"},
+	)
+
+	cases := []struct {
+		name    string
+		classes []neocortex.ViewClass
+		frame   neocortex.TimeFrame
+		want    []string
+	}{
+		{"intent", []neocortex.ViewClass{{Type: neocortex.IntentClass, Value: "buy"}}, neocortex.TimeFrame{}, []string{"intent", "late"}},
+		{"entity value", []neocortex.ViewClass{{Type: neocortex.EntityClass, Value: "pizza"}}, neocortex.TimeFrame{}, []string{"entity"}},
+		{"node name", []neocortex.ViewClass{{Type: neocortex.DialogNodeClass, Value: "checkout"}}, neocortex.TimeFrame{}, []string{"node"}},
+		{"node title", []neocortex.ViewClass{{Type: neocortex.DialogNodeClass, Value: "Title of checkout"}}, neocortex.TimeFrame{}, []string{"node"}},
+		{"context var", []neocortex.ViewClass{{Type: neocortex.ContextVarClass, Value: "cart"}}, neocortex.TimeFrame{}, []string{"variable"}},
+		{"any class", []neocortex.ViewClass{
+			{Type: neocortex.IntentClass, Value: "buy"},
+			{Type: neocortex.EntityClass, Value: "pizza"},
+			{Type: neocortex.DialogNodeClass, Value: "checkout"},
+			{Type: neocortex.ContextVarClass, Value: "cart"},
+		}, neocortex.TimeFrame{}, []string{"intent", "entity", "node", "variable", "late"}},
+		{"no classes", []neocortex.ViewClass{}, neocortex.TimeFrame{}, []string{}},
+		{"no matches", []neocortex.ViewClass{{Type: neocortex.IntentClass, Value: "sell"}}, neocortex.TimeFrame{}, []string{}},
+		{"frame", []neocortex.ViewClass{{Type: neocortex.IntentClass, Value: "buy"}}, neocortex.TimeFrame{From: base, To: base.Add(time.Minute)}, []string{"intent"}},
+		// the dialogs are filtered before paging
+		{"paging after filter", []neocortex.ViewClass{
+			{Type: neocortex.IntentClass, Value: "buy"},
+			{Type: neocortex.ContextVarClass, Value: "cart"},
+			{Type: neocortex.DialogNodeClass, Value: "checkout"},
+		}, neocortex.TimeFrame{PageSize: 2, PageNum: 2}, []string{"variable", "late"}},
+	}
+
+	for _, c := range cases {
+		view := newView(c.name, c.classes...)
+		if err := repo.SaveView(view); err != nil {
+			t.Fatal(err)
+		}
+
+		dialogs, err := repo.DialogsByView(view.ID, c.frame)
+		expectIDs(t, "view "+c.name, dialogs, err, c.want...)
+	}
+}
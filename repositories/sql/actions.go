@@ -0,0 +1,23 @@
+package sql
+
+import (
+	"database/sql"
+)
+
+func (repo *Repository) SetActionVar(name string, value string) error {
+	_, err := repo.db.Exec(repo.dialect.rebind(`INSERT INTO action_vars (name, value) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET value = excluded.value`),
+		name, value,
+	)
+	return err
+}
+
+// GetActionVar returns an empty value if the var doesn't exist
+func (repo *Repository) GetActionVar(name string) (string, error) {
+	var value string
+	err := repo.db.QueryRow(repo.dialect.rebind(`SELECT value FROM action_vars WHERE name = ?`), name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/minskylab/neocortex"
+)
+
+const adminColumns = `username, password_hash, role, disabled, created_at, updated_at, password_changed_at`
+
+// SaveAdmin inserts the admin or replaces the admin with the same username
+func (repo *Repository) SaveAdmin(admin *neocortex.Admin) error {
+	_, err := repo.db.Exec(repo.dialect.rebind(`INSERT INTO admins (`+adminColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (username) DO UPDATE SET password_hash = excluded.password_hash, role = excluded.role,
+		disabled = excluded.disabled, updated_at = excluded.updated_at, password_changed_at = excluded.password_changed_at`),
+		admin.Username, admin.PasswordHash, string(admin.Role), admin.Disabled,
+		stamp(admin.CreatedAt), stamp(admin.UpdatedAt), stamp(admin.PasswordChangedAt),
+	)
+	return err
+}
+
+func (repo *Repository) GetAdmin(username string) (*neocortex.Admin, error) {
+	admins, err := repo.queryAdmins(`SELECT `+adminColumns+` FROM admins WHERE username = ?`, username)
+	if err != nil {
+		return nil, err
+	}
+	if len(admins) == 0 {
+		return nil, neocortex.ErrAdminNotExist
+	}
+	return admins[0], nil
+}
+
+// AllAdmins returns the admins sorted by its username
+func (repo *Repository) AllAdmins() ([]*neocortex.Admin, error) {
+	return repo.queryAdmins(`SELECT ` + adminColumns + ` FROM admins ORDER BY username`)
+}
+
+func (repo *Repository) DeleteAdmin(username string) (*neocortex.Admin, error) {
+	admin, err := repo.GetAdmin(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := repo.db.Exec(repo.dialect.rebind(`DELETE FROM admins WHERE username = ?`), username); err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+func (repo *Repository) queryAdmins(query string, args ...interface{}) ([]*neocortex.Admin, error) {
+	admins := make([]*neocortex.Admin, 0)
+
+	err := repo.each(query, args, func(rows *sql.Rows) error {
+		var role string
+		var createdAt, updatedAt, passwordChangedAt int64
+		admin := new(neocortex.Admin)
+		if err := rows.Scan(&admin.Username, &admin.PasswordHash, &role, &admin.Disabled,
+			&createdAt, &updatedAt, &passwordChangedAt); err != nil {
+			return err
+		}
+
+		admin.Role = neocortex.AdminRole(role)
+		admin.CreatedAt = fromStamp(createdAt)
+		admin.UpdatedAt = fromStamp(updatedAt)
+		admin.PasswordChangedAt = fromStamp(passwordChangedAt)
+
+		admins = append(admins, admin)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return admins, nil
+}
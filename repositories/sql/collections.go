@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"time"
+)
+
+const (
+	intentsBox     = "intents"
+	entitiesBox    = "entities"
+	nodesBox       = "nodes"
+	contextVarsBox = "context_vars"
+)
+
+func (repo *Repository) register(box, value string) error {
+	_, err := repo.db.Exec(repo.dialect.rebind(`INSERT INTO collections (box, value, registered_at) VALUES (?, ?, ?)
+		ON CONFLICT (box, value) DO NOTHING`),
+		box, value, time.Now().UnixNano(),
+	)
+	return err
+}
+
+// values returns the values of the box in the order they were registered
+func (repo *Repository) values(box string) []string {
+	values := make([]string, 0)
+	rows, err := repo.db.Query(repo.dialect.rebind(`SELECT value FROM collections WHERE box = ? ORDER BY registered_at, value`), box)
+	if err != nil {
+		return values
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return values
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func (repo *Repository) RegisterIntent(intent string) error {
+	return repo.register(intentsBox, intent)
+}
+
+func (repo *Repository) RegisterEntity(entity string) error {
+	return repo.register(entitiesBox, entity)
+}
+
+func (repo *Repository) RegisterDialogNode(name string) error {
+	return repo.register(nodesBox, name)
+}
+
+func (repo *Repository) RegisterContextVar(value string) error {
+	return repo.register(contextVarsBox, value)
+}
+
+func (repo *Repository) Intents() []string {
+	return repo.values(intentsBox)
+}
+
+func (repo *Repository) Entities() []string {
+	return repo.values(entitiesBox)
+}
+
+func (repo *Repository) DialogNodes() []string {
+	return repo.values(nodesBox)
+}
+
+func (repo *Repository) ContextVars() []string {
+	return repo.values(contextVarsBox)
+}
@@ -0,0 +1,73 @@
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the differences between the supported databases, the queries of the
+// repository are written with ? as placeholder and {type} tokens into the schema
+type Dialect struct {
+	Name   string
+	Driver string
+
+	numberedParams   bool
+	singleConnection bool
+	types            *strings.Replacer
+}
+
+// SQLite uses the github.com/mattn/go-sqlite3 driver (import it with _)
+var SQLite = &Dialect{
+	Name:             "sqlite",
+	Driver:           "sqlite3",
+	singleConnection: true,
+	types: strings.NewReplacer(
+		"{bytes}", "BLOB",
+		"{float}", "REAL",
+		"{bool}", "INTEGER",
+	),
+}
+
+// PostgreSQL uses the github.com/lib/pq driver (import it with _)
+var PostgreSQL = &Dialect{
+	Name:           "postgres",
+	Driver:         "postgres",
+	numberedParams: true,
+	types: strings.NewReplacer(
+		"{bytes}", "BYTEA",
+		"{float}", "DOUBLE PRECISION",
+		"{bool}", "BOOLEAN",
+	),
+}
+
+// Dialects are the dialects by name, useful to choose one from a configuration
+var Dialects = map[string]*Dialect{
+	SQLite.Name:     SQLite,
+	"sqlite3":       SQLite,
+	PostgreSQL.Name: PostgreSQL,
+	"postgresql":    PostgreSQL,
+}
+
+// rebind replaces the ? placeholders of the query by the placeholders of the dialect
+func (d *Dialect) rebind(query string) string {
+	if !d.numberedParams {
+		return query
+	}
+
+	b := strings.Builder{}
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// schema replaces the {type} tokens of the statement by the types of the dialect
+func (d *Dialect) schema(statement string) string {
+	return d.types.Replace(statement)
+}
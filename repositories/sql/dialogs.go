@@ -0,0 +1,536 @@
+package sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+const (
+	inputSource  = "input"
+	outputSource = "output"
+)
+
+// dialogChildren are the tables of the records of a dialog, deleted before the dialog
+var dialogChildren = []string{
+	"dialog_inputs",
+	"dialog_outputs",
+	"dialog_contexts",
+	"dialog_variables",
+	"dialog_intents",
+	"dialog_entities",
+	"dialog_nodes",
+}
+
+const dialogColumns = `d.id, d.start_at, d.last_activity, d.end_at, d.performance`
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func fromJSON(data string, v interface{}) error {
+	if data == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+// SaveDialog inserts the dialog or replaces the dialog (and all its records) with the same id
+func (repo *Repository) SaveDialog(dialog *neocortex.Dialog) error {
+	if dialog.ID == "" {
+		dialog.ID = xid.New().String()
+	}
+
+	return repo.transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO dialogs (id, start_at, last_activity, end_at, performance)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET start_at = excluded.start_at, last_activity = excluded.last_activity,
+			end_at = excluded.end_at, performance = excluded.performance`),
+			dialog.ID, stamp(dialog.StartAt), stamp(dialog.LastActivity), stamp(dialog.EndAt), dialog.Performance,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.deleteRecords(tx, dialog.ID); err != nil {
+			return err
+		}
+
+		return repo.insertRecords(tx, dialog)
+	})
+}
+
+func (repo *Repository) deleteRecords(tx *sql.Tx, dialogID string) error {
+	for _, table := range dialogChildren {
+		if _, err := tx.Exec(repo.dialect.rebind(`DELETE FROM `+table+` WHERE dialog_id = ?`), dialogID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *Repository) insertRecords(tx *sql.Tx, dialog *neocortex.Dialog) error {
+	for i, in := range dialog.Ins {
+		_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO dialog_inputs (dialog_id, position, at, type, value, data)
+			VALUES (?, ?, ?, ?, ?, ?)`),
+			dialog.ID, i, stamp(in.At), string(in.Input.Data.Type), in.Input.Data.Value, in.Input.Data.Data,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.insertIntents(tx, dialog.ID, inputSource, i, in.Input.Intents); err != nil {
+			return err
+		}
+
+		if err := repo.insertEntities(tx, dialog.ID, inputSource, i, in.Input.Entities); err != nil {
+			return err
+		}
+	}
+
+	for i, out := range dialog.Outs {
+		responses, err := toJSON(out.Output.Responses)
+		if err != nil {
+			return err
+		}
+
+		logs, err := toJSON(out.Output.Logs)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(repo.dialect.rebind(`INSERT INTO dialog_outputs (dialog_id, position, at, responses, logs)
+			VALUES (?, ?, ?, ?, ?)`),
+			dialog.ID, i, stamp(out.At), responses, logs,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.insertIntents(tx, dialog.ID, outputSource, i, out.Output.Intents); err != nil {
+			return err
+		}
+
+		if err := repo.insertEntities(tx, dialog.ID, outputSource, i, out.Output.Entities); err != nil {
+			return err
+		}
+
+		for j, node := range out.Output.VisitedNodes {
+			if node == nil {
+				continue
+			}
+			_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO dialog_nodes (dialog_id, position, seq, name, title, conditions)
+				VALUES (?, ?, ?, ?, ?, ?)`),
+				dialog.ID, i, j, node.Name, node.Title, node.Conditions,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, record := range dialog.Contexts {
+		c := record.Context
+		_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO dialog_contexts
+			(dialog_id, position, at, session_id, person_id, person_name, timezone, locale, picture)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+			dialog.ID, i, stamp(record.At), c.SessionID, c.Person.ID, c.Person.Name, c.Person.Timezone, c.Person.Locale, c.Person.Picture,
+		)
+		if err != nil {
+			return err
+		}
+
+		for name, value := range c.Variables {
+			encoded, err := toJSON(value)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(repo.dialect.rebind(`INSERT INTO dialog_variables (dialog_id, position, name, value)
+				VALUES (?, ?, ?, ?)`),
+				dialog.ID, i, name, encoded,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (repo *Repository) insertIntents(tx *sql.Tx, dialogID, source string, position int, intents []neocortex.Intent) error {
+	for seq, intent := range intents {
+		_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO dialog_intents (dialog_id, source, position, seq, intent, confidence)
+			VALUES (?, ?, ?, ?, ?, ?)`),
+			dialogID, source, position, seq, intent.Intent, intent.Confidence,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *Repository) insertEntities(tx *sql.Tx, dialogID, source string, position int, entities []neocortex.Entity) error {
+	for seq, entity := range entities {
+		location, err := toJSON(entity.Location)
+		if err != nil {
+			return err
+		}
+
+		metadata, err := toJSON(entity.Metadata)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(repo.dialect.rebind(`INSERT INTO dialog_entities
+			(dialog_id, source, position, seq, entity, value, confidence, location, metadata)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+			dialogID, source, position, seq, entity.Entity, entity.Value, entity.Confidence, location, metadata,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *Repository) GetDialogByID(id string) (*neocortex.Dialog, error) {
+	dialogs, err := repo.queryDialogs(`SELECT `+dialogColumns+` FROM dialogs d WHERE d.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dialogs) == 0 {
+		return nil, neocortex.ErrDialogNotExist
+	}
+	return dialogs[0], nil
+}
+
+func (repo *Repository) AllDialogs(frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
+	min, max := bounds(frame)
+	offset, limit := frame.Page()
+
+	return repo.queryDialogs(`SELECT `+dialogColumns+` FROM dialogs d
+		WHERE d.start_at BETWEEN ? AND ?
+		ORDER BY d.start_at, d.id
+		LIMIT ? OFFSET ?`,
+		min, max, limit, offset,
+	)
+}
+
+func (repo *Repository) DeleteDialog(id string) (*neocortex.Dialog, error) {
+	dialog, err := repo.GetDialogByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.transaction(func(tx *sql.Tx) error {
+		if err := repo.deleteRecords(tx, id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(repo.dialect.rebind(`DELETE FROM dialogs WHERE id = ?`), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dialog, nil
+}
+
+// DialogsByView filters the dialogs into the database, a dialog is into the view if it has
+// at least one of the classes of the view (the same rule of neocortex.View.Matches)
+func (repo *Repository) DialogsByView(viewID string, frame neocortex.TimeFrame) ([]*neocortex.Dialog, error) {
+	if _, err := repo.GetViewByID(viewID); err != nil {
+		return nil, err
+	}
+
+	min, max := bounds(frame)
+	offset, limit := frame.Page()
+
+	return repo.queryDialogs(`SELECT `+dialogColumns+` FROM dialogs d
+		WHERE d.start_at BETWEEN ? AND ?
+		AND EXISTS (
+			SELECT 1 FROM view_classes vc WHERE vc.view_id = ? AND (
+				(vc.type = 'entity' AND EXISTS (
+					SELECT 1 FROM dialog_entities e WHERE e.dialog_id = d.id AND e.value = vc.value
+				))
+				OR (vc.type = 'intent' AND EXISTS (
+					SELECT 1 FROM dialog_intents i WHERE i.dialog_id = d.id AND i.intent = vc.value
+				))
+				OR (vc.type = 'node' AND EXISTS (
+					SELECT 1 FROM dialog_nodes n WHERE n.dialog_id = d.id AND (n.name = vc.value OR n.title = vc.value)
+				))
+				OR (vc.type = 'context_var' AND EXISTS (
+					SELECT 1 FROM dialog_variables v WHERE v.dialog_id = d.id AND v.name = vc.value
+				))
+			)
+		)
+		ORDER BY d.start_at, d.id
+		LIMIT ? OFFSET ?`,
+		min, max, viewID, limit, offset,
+	)
+}
+
+// queryDialogs runs the query (it must select the dialogColumns) and loads the records of the dialogs
+func (repo *Repository) queryDialogs(query string, args ...interface{}) ([]*neocortex.Dialog, error) {
+	rows, err := repo.db.Query(repo.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	dialogs := make([]*neocortex.Dialog, 0)
+	byID := map[string]*neocortex.Dialog{}
+
+	for rows.Next() {
+		var startAt, lastActivity, endAt int64
+		dialog := &neocortex.Dialog{
+			Ins:      []*neocortex.InputRecord{},
+			Outs:     []*neocortex.OutputRecord{},
+			Contexts: []*neocortex.ContextRecord{},
+		}
+
+		if err := rows.Scan(&dialog.ID, &startAt, &lastActivity, &endAt, &dialog.Performance); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+
+		dialog.StartAt = fromStamp(startAt)
+		dialog.LastActivity = fromStamp(lastActivity)
+		dialog.EndAt = fromStamp(endAt)
+
+		dialogs = append(dialogs, dialog)
+		byID[dialog.ID] = dialog
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(dialogs) == 0 {
+		return dialogs, nil
+	}
+
+	if err := repo.loadRecords(byID); err != nil {
+		return nil, err
+	}
+
+	return dialogs, nil
+}
+
+// in returns the placeholders and the args of a IN (...) clause with the ids of the dialogs
+func in(byID map[string]*neocortex.Dialog) (string, []interface{}) {
+	placeholders := make([]string, 0, len(byID))
+	args := make([]interface{}, 0, len(byID))
+	for id := range byID {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")", args
+}
+
+// each runs the query and calls scan for every row
+func (repo *Repository) each(query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := repo.db.Query(repo.dialect.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// loadRecords loads the inputs, outputs and contexts of the dialogs, the records are loaded in order
+// so the position of each record is its index into the dialog
+func (repo *Repository) loadRecords(byID map[string]*neocortex.Dialog) error {
+	ids, args := in(byID)
+
+	err := repo.each(`SELECT dialog_id, at, type, value, data FROM dialog_inputs
+		WHERE dialog_id IN `+ids+` ORDER BY dialog_id, position`, args,
+		func(rows *sql.Rows) error {
+			var dialogID, inputType string
+			var at int64
+			record := &neocortex.InputRecord{}
+			if err := rows.Scan(&dialogID, &at, &inputType, &record.Input.Data.Value, &record.Input.Data.Data); err != nil {
+				return err
+			}
+			record.At = fromStamp(at)
+			record.Input.Data.Type = neocortex.InputType(inputType)
+			record.Input.Entities = []neocortex.Entity{}
+			record.Input.Intents = []neocortex.Intent{}
+
+			dialog := byID[dialogID]
+			dialog.Ins = append(dialog.Ins, record)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = repo.each(`SELECT dialog_id, at, responses, logs FROM dialog_outputs
+		WHERE dialog_id IN `+ids+` ORDER BY dialog_id, position`, args,
+		func(rows *sql.Rows) error {
+			var dialogID, responses, logs string
+			var at int64
+			record := &neocortex.OutputRecord{}
+			if err := rows.Scan(&dialogID, &at, &responses, &logs); err != nil {
+				return err
+			}
+			record.At = fromStamp(at)
+			record.Output.Entities = []neocortex.Entity{}
+			record.Output.Intents = []neocortex.Intent{}
+			record.Output.VisitedNodes = []*neocortex.DialogNode{}
+
+			if err := fromJSON(responses, &record.Output.Responses); err != nil {
+				return err
+			}
+			if err := fromJSON(logs, &record.Output.Logs); err != nil {
+				return err
+			}
+
+			dialog := byID[dialogID]
+			dialog.Outs = append(dialog.Outs, record)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = repo.each(`SELECT dialog_id, source, position, intent, confidence FROM dialog_intents
+		WHERE dialog_id IN `+ids+` ORDER BY dialog_id, source, position, seq`, args,
+		func(rows *sql.Rows) error {
+			var dialogID, source string
+			var position int
+			intent := neocortex.Intent{}
+			if err := rows.Scan(&dialogID, &source, &position, &intent.Intent, &intent.Confidence); err != nil {
+				return err
+			}
+
+			dialog := byID[dialogID]
+			if source == inputSource && position < len(dialog.Ins) {
+				dialog.Ins[position].Input.Intents = append(dialog.Ins[position].Input.Intents, intent)
+			} else if source == outputSource && position < len(dialog.Outs) {
+				dialog.Outs[position].Output.Intents = append(dialog.Outs[position].Output.Intents, intent)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = repo.each(`SELECT dialog_id, source, position, entity, value, confidence, location, metadata FROM dialog_entities
+		WHERE dialog_id IN `+ids+` ORDER BY dialog_id, source, position, seq`, args,
+		func(rows *sql.Rows) error {
+			var dialogID, source, location, metadata string
+			var position int
+			entity := neocortex.Entity{}
+			if err := rows.Scan(&dialogID, &source, &position, &entity.Entity, &entity.Value, &entity.Confidence, &location, &metadata); err != nil {
+				return err
+			}
+			if err := fromJSON(location, &entity.Location); err != nil {
+				return err
+			}
+			if err := fromJSON(metadata, &entity.Metadata); err != nil {
+				return err
+			}
+
+			dialog := byID[dialogID]
+			if source == inputSource && position < len(dialog.Ins) {
+				dialog.Ins[position].Input.Entities = append(dialog.Ins[position].Input.Entities, entity)
+			} else if source == outputSource && position < len(dialog.Outs) {
+				dialog.Outs[position].Output.Entities = append(dialog.Outs[position].Output.Entities, entity)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = repo.each(`SELECT dialog_id, position, name, title, conditions FROM dialog_nodes
+		WHERE dialog_id IN `+ids+` ORDER BY dialog_id, position, seq`, args,
+		func(rows *sql.Rows) error {
+			var dialogID string
+			var position int
+			node := &neocortex.DialogNode{}
+			if err := rows.Scan(&dialogID, &position, &node.Name, &node.Title, &node.Conditions); err != nil {
+				return err
+			}
+
+			dialog := byID[dialogID]
+			if position < len(dialog.Outs) {
+				dialog.Outs[position].Output.VisitedNodes = append(dialog.Outs[position].Output.VisitedNodes, node)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = repo.each(`SELECT dialog_id, at, session_id, person_id, person_name, timezone, locale, picture FROM dialog_contexts
+		WHERE dialog_id IN `+ids+` ORDER BY dialog_id, position`, args,
+		func(rows *sql.Rows) error {
+			var dialogID string
+			var at int64
+			record := &neocortex.ContextRecord{}
+			person := &record.Context.Person
+			if err := rows.Scan(&dialogID, &at, &record.Context.SessionID, &person.ID, &person.Name, &person.Timezone, &person.Locale, &person.Picture); err != nil {
+				return err
+			}
+			record.At = fromStamp(at)
+			record.Context.Variables = map[string]interface{}{}
+
+			dialog := byID[dialogID]
+			dialog.Contexts = append(dialog.Contexts, record)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return repo.each(`SELECT dialog_id, position, name, value FROM dialog_variables
+		WHERE dialog_id IN `+ids, args,
+		func(rows *sql.Rows) error {
+			var dialogID, name, encoded string
+			var position int
+			if err := rows.Scan(&dialogID, &position, &name, &encoded); err != nil {
+				return err
+			}
+
+			var value interface{}
+			if err := fromJSON(encoded, &value); err != nil {
+				return err
+			}
+
+			dialog := byID[dialogID]
+			if position < len(dialog.Contexts) {
+				dialog.Contexts[position].Context.Variables[name] = value
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,5 @@
+package sql
+
+import "errors"
+
+var ErrUnknownDialect = errors.New("unknown sql dialect")
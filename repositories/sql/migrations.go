@@ -0,0 +1,216 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+// migrations are embedded and applied in order, never change an applied migration, append a new one
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "dialogs",
+		Statements: []string{
+			`CREATE TABLE dialogs (
+				id VARCHAR(64) PRIMARY KEY,
+				start_at BIGINT NOT NULL,
+				last_activity BIGINT NOT NULL,
+				end_at BIGINT NOT NULL,
+				performance {float} NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX dialogs_start_at ON dialogs (start_at)`,
+			`CREATE TABLE dialog_inputs (
+				dialog_id VARCHAR(64) NOT NULL REFERENCES dialogs (id),
+				position INTEGER NOT NULL,
+				at BIGINT NOT NULL,
+				type VARCHAR(32) NOT NULL,
+				value TEXT NOT NULL,
+				data {bytes},
+				PRIMARY KEY (dialog_id, position)
+			)`,
+			`CREATE TABLE dialog_outputs (
+				dialog_id VARCHAR(64) NOT NULL REFERENCES dialogs (id),
+				position INTEGER NOT NULL,
+				at BIGINT NOT NULL,
+				responses TEXT NOT NULL,
+				logs TEXT NOT NULL,
+				PRIMARY KEY (dialog_id, position)
+			)`,
+			`CREATE TABLE dialog_contexts (
+				dialog_id VARCHAR(64) NOT NULL REFERENCES dialogs (id),
+				position INTEGER NOT NULL,
+				at BIGINT NOT NULL,
+				session_id VARCHAR(255) NOT NULL,
+				person_id VARCHAR(255) NOT NULL,
+				person_name VARCHAR(255) NOT NULL,
+				timezone VARCHAR(255) NOT NULL,
+				locale VARCHAR(64) NOT NULL,
+				picture TEXT NOT NULL,
+				PRIMARY KEY (dialog_id, position)
+			)`,
+			`CREATE TABLE dialog_variables (
+				dialog_id VARCHAR(64) NOT NULL REFERENCES dialogs (id),
+				position INTEGER NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				value TEXT NOT NULL,
+				PRIMARY KEY (dialog_id, position, name)
+			)`,
+			`CREATE INDEX dialog_variables_name ON dialog_variables (name)`,
+			`CREATE TABLE dialog_intents (
+				dialog_id VARCHAR(64) NOT NULL REFERENCES dialogs (id),
+				source VARCHAR(8) NOT NULL,
+				position INTEGER NOT NULL,
+				seq INTEGER NOT NULL,
+				intent VARCHAR(255) NOT NULL,
+				confidence {float} NOT NULL,
+				PRIMARY KEY (dialog_id, source, position, seq)
+			)`,
+			`CREATE INDEX dialog_intents_intent ON dialog_intents (intent)`,
+			`CREATE TABLE dialog_entities (
+				dialog_id VARCHAR(64) NOT NULL REFERENCES dialogs (id),
+				source VARCHAR(8) NOT NULL,
+				position INTEGER NOT NULL,
+				seq INTEGER NOT NULL,
+				entity VARCHAR(255) NOT NULL,
+				value TEXT NOT NULL,
+				confidence {float} NOT NULL,
+				location TEXT NOT NULL,
+				metadata TEXT NOT NULL,
+				PRIMARY KEY (dialog_id, source, position, seq)
+			)`,
+			`CREATE INDEX dialog_entities_value ON dialog_entities (value)`,
+			`CREATE TABLE dialog_nodes (
+				dialog_id VARCHAR(64) NOT NULL REFERENCES dialogs (id),
+				position INTEGER NOT NULL,
+				seq INTEGER NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				title VARCHAR(255) NOT NULL,
+				conditions TEXT NOT NULL,
+				PRIMARY KEY (dialog_id, position, seq)
+			)`,
+			`CREATE INDEX dialog_nodes_name ON dialog_nodes (name)`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "views, collections and action vars",
+		Statements: []string{
+			`CREATE TABLE views (
+				id VARCHAR(64) PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				frequency_mode {bool} NOT NULL,
+				styles TEXT NOT NULL,
+				children TEXT NOT NULL,
+				created_at BIGINT NOT NULL
+			)`,
+			`CREATE INDEX views_name ON views (name)`,
+			`CREATE TABLE view_classes (
+				view_id VARCHAR(64) NOT NULL REFERENCES views (id),
+				seq INTEGER NOT NULL,
+				type VARCHAR(32) NOT NULL,
+				value VARCHAR(255) NOT NULL,
+				PRIMARY KEY (view_id, seq)
+			)`,
+			`CREATE TABLE collections (
+				box VARCHAR(32) NOT NULL,
+				value VARCHAR(255) NOT NULL,
+				registered_at BIGINT NOT NULL,
+				PRIMARY KEY (box, value)
+			)`,
+			`CREATE TABLE action_vars (
+				name VARCHAR(255) PRIMARY KEY,
+				value TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "admins",
+		Statements: []string{
+			`CREATE TABLE admins (
+				username VARCHAR(255) PRIMARY KEY,
+				password_hash VARCHAR(255) NOT NULL,
+				role VARCHAR(32) NOT NULL,
+				disabled {bool} NOT NULL,
+				created_at BIGINT NOT NULL,
+				updated_at BIGINT NOT NULL,
+				password_changed_at BIGINT NOT NULL
+			)`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "api keys and revoked tokens",
+		Statements: []string{
+			`CREATE TABLE api_keys (
+				id VARCHAR(64) PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				hash VARCHAR(128) NOT NULL,
+				scopes TEXT NOT NULL,
+				created_by VARCHAR(255) NOT NULL,
+				created_at BIGINT NOT NULL,
+				expires_at BIGINT NOT NULL,
+				revoked_at BIGINT NOT NULL
+			)`,
+			`CREATE INDEX api_keys_created_at ON api_keys (created_at)`,
+			`CREATE TABLE revoked_tokens (
+				id VARCHAR(64) PRIMARY KEY,
+				expires_at BIGINT NOT NULL
+			)`,
+			`CREATE INDEX revoked_tokens_expires_at ON revoked_tokens (expires_at)`,
+		},
+	},
+}
+
+// Migrate applies the pending migrations, each one into its own transaction
+func (repo *Repository) Migrate() error {
+	_, err := repo.db.Exec(`CREATE TABLE IF NOT EXISTS neocortex_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	current := 0
+	row := repo.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM neocortex_migrations`)
+	if err := row.Scan(&current); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		if err := repo.apply(m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (repo *Repository) apply(m migration) error {
+	return repo.transaction(func(tx *sql.Tx) error {
+		for _, statement := range m.Statements {
+			if _, err := tx.Exec(repo.dialect.schema(statement)); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec(
+			repo.dialect.rebind(`INSERT INTO neocortex_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+			m.Version, m.Name, time.Now().UnixNano(),
+		)
+		return err
+	})
+}
@@ -0,0 +1,108 @@
+// Package sql is a repository over database/sql with a normalized schema, it supports SQLite and PostgreSQL.
+// The package doesn't import any driver, import the driver of the dialect (e.g. _ "github.com/lib/pq")
+package sql
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+type Repository struct {
+	db      *sql.DB
+	dialect *Dialect
+}
+
+// New creates the repository over an opened database and applies the pending migrations
+func New(db *sql.DB, dialect *Dialect) (*Repository, error) {
+	if dialect == nil {
+		return nil, ErrUnknownDialect
+	}
+
+	repo := &Repository{db: db, dialect: dialect}
+	if err := repo.Migrate(); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// Open opens the database with the driver of the dialect, SQLite uses only one connection
+// because it doesn't support concurrent writers (and every connection to :memory: is a new database)
+func Open(dialect *Dialect, dsn string) (*Repository, error) {
+	if dialect == nil {
+		return nil, ErrUnknownDialect
+	}
+
+	db, err := sql.Open(dialect.Driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect.singleConnection {
+		db.SetMaxOpenConns(1)
+	}
+
+	repo, err := New(db, dialect)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// DB returns the underlying database
+func (repo *Repository) DB() *sql.DB {
+	return repo.db
+}
+
+func (repo *Repository) Close() error {
+	return repo.db.Close()
+}
+
+// transaction runs fn into a transaction, it's committed only if fn doesn't fail
+func (repo *Repository) transaction(fn func(tx *sql.Tx) error) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// stamp converts t to unix nanoseconds, the zero time is stored as 0
+func stamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromStamp(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// bounds returns the range of the frame as stamps, without limits the range is open
+func bounds(frame neocortex.TimeFrame) (int64, int64) {
+	from, to := frame.Bounds()
+
+	min, max := int64(math.MinInt64), int64(math.MaxInt64)
+	if !from.IsZero() {
+		min = from.UnixNano()
+	}
+	if !to.IsZero() {
+		max = to.UnixNano()
+	}
+	return min, max
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/repositories/repositorytest"
+)
+
+// the suite runs against an in-memory sqlite database, or against the database of the environment
+// if its driver is linked into the test binary (e.g. with a file of the package that imports it with _)
+//
+//	NEOCORTEX_SQL_DIALECT=postgres NEOCORTEX_SQL_DSN=postgres://localhost/neocortex_test go test ./repositories/sql
+//
+// the tables are emptied before every test, don't use a database with data
+const (
+	dialectEnv = "NEOCORTEX_SQL_DIALECT"
+	dsnEnv     = "NEOCORTEX_SQL_DSN"
+)
+
+// tables are emptied in order, the tables with references to others go first
+var tables = []string{
+	"dialog_inputs", "dialog_outputs", "dialog_contexts", "dialog_variables", "dialog_intents",
+	"dialog_entities", "dialog_nodes", "dialogs", "view_classes", "views", "collections",
+	"action_vars", "admins", "api_keys", "revoked_tokens",
+}
+
+func hasDriver(name string) bool {
+	for _, driver := range sql.Drivers() {
+		if driver == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRepository(t *testing.T) {
+	name, dsn := os.Getenv(dialectEnv), os.Getenv(dsnEnv)
+	if name == "" || dsn == "" {
+		name, dsn = "sqlite", ":memory:"
+	}
+
+	dialect, exist := Dialects[name]
+	if !exist {
+		t.Fatalf("unknown dialect %q", name)
+	}
+	if !hasDriver(dialect.Driver) {
+		t.Skipf("the driver %q is not linked into the test", dialect.Driver)
+	}
+
+	repositorytest.Run(t, func(t *testing.T) neocortex.Repository {
+		repo, err := Open(dialect, dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		for _, table := range tables {
+			if _, err = repo.DB().Exec("DELETE FROM " + table); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return repo
+	})
+}
@@ -0,0 +1,66 @@
+package sql
+
+import (
+	"github.com/minskylab/neocortex"
+)
+
+// Summary is computed into the database with the same rules of neocortex.NewSummary: only the dialogs
+// with inputs are counted, the users are grouped by the timezone of the first context of each dialog
+// (identified by its id or by its name) and they are recurrent if they have more than one dialog
+func (repo *Repository) Summary(frame neocortex.TimeFrame) (*neocortex.Summary, error) {
+	min, max := bounds(frame)
+	summary := &neocortex.Summary{UsersByTimezone: map[string]neocortex.UsersSummary{}}
+
+	var performance float64
+	row := repo.db.QueryRow(repo.dialect.rebind(`SELECT COUNT(*), COALESCE(SUM(d.performance), 0) FROM dialogs d
+		WHERE d.start_at BETWEEN ? AND ?
+		AND EXISTS (SELECT 1 FROM dialog_inputs i WHERE i.dialog_id = d.id)`),
+		min, max,
+	)
+	if err := row.Scan(&summary.TotalDialogs, &performance); err != nil {
+		return nil, err
+	}
+
+	if summary.TotalDialogs == 0 {
+		return summary, nil
+	}
+
+	summary.PerformanceMean = performance / float64(summary.TotalDialogs)
+
+	rows, err := repo.db.Query(repo.dialect.rebind(`SELECT u.timezone, COUNT(*), SUM(CASE WHEN u.total > 1 THEN 1 ELSE 0 END)
+		FROM (
+			SELECT c.timezone AS timezone, CASE WHEN c.person_id <> '' THEN c.person_id ELSE c.person_name END AS person, COUNT(*) AS total
+			FROM dialogs d JOIN dialog_contexts c ON c.dialog_id = d.id AND c.position = 0
+			WHERE d.start_at BETWEEN ? AND ?
+			GROUP BY c.timezone, CASE WHEN c.person_id <> '' THEN c.person_id ELSE c.person_name END
+		) u
+		GROUP BY u.timezone`),
+		min, max,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var timezone string
+		var users, recurrents int64
+		if err := rows.Scan(&timezone, &users, &recurrents); err != nil {
+			return nil, err
+		}
+
+		summary.UsersByTimezone[timezone] = neocortex.UsersSummary{
+			Recurrents: recurrents,
+			News:       users - recurrents,
+		}
+
+		summary.TotalUsers += users
+		summary.RecurrentUsers += recurrents
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
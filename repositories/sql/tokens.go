@@ -0,0 +1,92 @@
+package sql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/minskylab/neocortex"
+)
+
+const apiKeyColumns = `id, name, hash, scopes, created_by, created_at, expires_at, revoked_at`
+
+// SaveAPIKey inserts the key or replaces the key with the same id
+func (repo *Repository) SaveAPIKey(key *neocortex.APIKey) error {
+	scopes, err := toJSON(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.db.Exec(repo.dialect.rebind(`INSERT INTO api_keys (`+apiKeyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, hash = excluded.hash, scopes = excluded.scopes,
+		expires_at = excluded.expires_at, revoked_at = excluded.revoked_at`),
+		key.ID, key.Name, key.Hash, scopes, key.CreatedBy,
+		stamp(key.CreatedAt), stamp(key.ExpiresAt), stamp(key.RevokedAt),
+	)
+	return err
+}
+
+func (repo *Repository) GetAPIKey(id string) (*neocortex.APIKey, error) {
+	keys, err := repo.queryAPIKeys(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, neocortex.ErrAPIKeyNotExist
+	}
+	return keys[0], nil
+}
+
+// AllAPIKeys returns the keys in the order they were created
+func (repo *Repository) AllAPIKeys() ([]*neocortex.APIKey, error) {
+	return repo.queryAPIKeys(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at, id`)
+}
+
+func (repo *Repository) queryAPIKeys(query string, args ...interface{}) ([]*neocortex.APIKey, error) {
+	keys := make([]*neocortex.APIKey, 0)
+
+	err := repo.each(query, args, func(rows *sql.Rows) error {
+		var scopes string
+		var createdAt, expiresAt, revokedAt int64
+		key := new(neocortex.APIKey)
+		if err := rows.Scan(&key.ID, &key.Name, &key.Hash, &scopes, &key.CreatedBy,
+			&createdAt, &expiresAt, &revokedAt); err != nil {
+			return err
+		}
+		if err := fromJSON(scopes, &key.Scopes); err != nil {
+			return err
+		}
+
+		key.CreatedAt = fromStamp(createdAt)
+		key.ExpiresAt = fromStamp(expiresAt)
+		key.RevokedAt = fromStamp(revokedAt)
+
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeToken drops the revocations that already expired
+func (repo *Repository) RevokeToken(id string, expiresAt time.Time) error {
+	return repo.transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(repo.dialect.rebind(`DELETE FROM revoked_tokens WHERE expires_at < ?`), time.Now().UnixNano()); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO revoked_tokens (id, expires_at) VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET expires_at = excluded.expires_at`),
+			id, expiresAt.UnixNano(),
+		)
+		return err
+	})
+}
+
+func (repo *Repository) IsTokenRevoked(id string) (bool, error) {
+	var n int
+	err := repo.db.QueryRow(repo.dialect.rebind(`SELECT COUNT(*) FROM revoked_tokens WHERE id = ?`), id).Scan(&n)
+	return n > 0, err
+}
@@ -0,0 +1,149 @@
+package sql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/minskylab/neocortex"
+	"github.com/rs/xid"
+)
+
+const viewColumns = `v.id, v.name, v.frequency_mode, v.styles, v.children`
+
+// SaveView inserts the view or replaces the view with the same id (keeping its original order)
+func (repo *Repository) SaveView(view *neocortex.View) error {
+	if view.ID == "" {
+		view.ID = xid.New().String()
+	}
+
+	styles, err := toJSON(view.Styles)
+	if err != nil {
+		return err
+	}
+
+	children, err := toJSON(view.Children)
+	if err != nil {
+		return err
+	}
+
+	return repo.transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO views (id, name, frequency_mode, styles, children, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET name = excluded.name, frequency_mode = excluded.frequency_mode,
+			styles = excluded.styles, children = excluded.children`),
+			view.ID, view.Name, view.FrequencyMode, styles, children, time.Now().UnixNano(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(repo.dialect.rebind(`DELETE FROM view_classes WHERE view_id = ?`), view.ID); err != nil {
+			return err
+		}
+
+		for seq, class := range view.Classes {
+			_, err := tx.Exec(repo.dialect.rebind(`INSERT INTO view_classes (view_id, seq, type, value) VALUES (?, ?, ?, ?)`),
+				view.ID, seq, string(class.Type), class.Value,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (repo *Repository) GetViewByID(id string) (*neocortex.View, error) {
+	views, err := repo.queryViews(`SELECT `+viewColumns+` FROM views v WHERE v.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(views) == 0 {
+		return nil, neocortex.ErrViewNotExist
+	}
+	return views[0], nil
+}
+
+// FindViewByName returns the views with exactly that name
+func (repo *Repository) FindViewByName(name string) ([]*neocortex.View, error) {
+	return repo.queryViews(`SELECT `+viewColumns+` FROM views v WHERE v.name = ? ORDER BY v.created_at, v.id`, name)
+}
+
+func (repo *Repository) AllViews() ([]*neocortex.View, error) {
+	return repo.queryViews(`SELECT ` + viewColumns + ` FROM views v ORDER BY v.created_at, v.id`)
+}
+
+func (repo *Repository) UpdateView(view *neocortex.View) error {
+	if _, err := repo.GetViewByID(view.ID); err != nil {
+		return err
+	}
+	return repo.SaveView(view)
+}
+
+func (repo *Repository) DeleteView(id string) (*neocortex.View, error) {
+	view, err := repo.GetViewByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(repo.dialect.rebind(`DELETE FROM view_classes WHERE view_id = ?`), id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(repo.dialect.rebind(`DELETE FROM views WHERE id = ?`), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// queryViews runs the query (it must select the viewColumns) and loads the classes of the views
+func (repo *Repository) queryViews(query string, args ...interface{}) ([]*neocortex.View, error) {
+	views := make([]*neocortex.View, 0)
+
+	err := repo.each(query, args, func(rows *sql.Rows) error {
+		var styles, children string
+		view := &neocortex.View{Classes: []neocortex.ViewClass{}}
+		if err := rows.Scan(&view.ID, &view.Name, &view.FrequencyMode, &styles, &children); err != nil {
+			return err
+		}
+		if err := fromJSON(styles, &view.Styles); err != nil {
+			return err
+		}
+		if err := fromJSON(children, &view.Children); err != nil {
+			return err
+		}
+
+		views = append(views, view)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, view := range views {
+		err := repo.each(`SELECT type, value FROM view_classes WHERE view_id = ? ORDER BY seq`, []interface{}{view.ID},
+			func(rows *sql.Rows) error {
+				var classType string
+				class := neocortex.ViewClass{}
+				if err := rows.Scan(&classType, &class.Value); err != nil {
+					return err
+				}
+				class.Type = neocortex.ViewClassType(classType)
+				view.Classes = append(view.Classes, class)
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return views, nil
+}
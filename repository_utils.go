@@ -0,0 +1,146 @@
+package neocortex
+
+import (
+	"time"
+
+	"github.com/jinzhu/now"
+)
+
+// DefaultPageSize is used when the frame doesn't have a page size
+const DefaultPageSize = 20
+
+// Bounds returns the range of the frame, the presets are relative to the current time.
+// A zero To means no upper limit
+func (frame TimeFrame) Bounds() (time.Time, time.Time) {
+	switch frame.Preset {
+	case DayPreset:
+		return now.BeginningOfDay(), now.EndOfDay()
+	case WeekPreset:
+		return now.BeginningOfWeek(), now.EndOfWeek()
+	case MonthPreset:
+		return now.BeginningOfMonth(), now.EndOfMonth()
+	case YearPreset:
+		return now.BeginningOfYear(), now.EndOfYear()
+	}
+	return frame.From, frame.To
+}
+
+// Contains returns true if t is into the bounds of the frame (both inclusive)
+func (frame TimeFrame) Contains(t time.Time) bool {
+	from, to := frame.Bounds()
+	if t.Before(from) {
+		return false
+	}
+	return to.IsZero() || !t.After(to)
+}
+
+// Page returns the offset and the limit of the frame, the pages start at 1 (0 is the first page too)
+func (frame TimeFrame) Page() (int, int) {
+	size := frame.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	page := frame.PageNum
+	if page < 1 {
+		page = 1
+	}
+
+	return (page - 1) * size, size
+}
+
+// Paginate returns the page of the dialogs
+func (frame TimeFrame) Paginate(dialogs []*Dialog) []*Dialog {
+	offset, limit := frame.Page()
+	if offset >= len(dialogs) {
+		return []*Dialog{}
+	}
+
+	end := offset + limit
+	if end > len(dialogs) {
+		end = len(dialogs)
+	}
+	return dialogs[offset:end]
+}
+
+// Matches returns true if the dialog has at least one of the classes of the view
+func (view *View) Matches(dialog *Dialog) bool {
+	for _, c := range view.Classes {
+		switch c.Type {
+		case EntityClass:
+			if dialog.HasEntity(c.Value) {
+				return true
+			}
+		case IntentClass:
+			if dialog.HasIntent(c.Value) {
+				return true
+			}
+		case DialogNodeClass:
+			if dialog.HasDialogNode(c.Value) {
+				return true
+			}
+		case ContextVarClass:
+			if dialog.HasContextVar(c.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewSummary computes the summary of the dialogs. Only the dialogs with inputs are counted, the
+// users are grouped by the timezone of the first context and they are recurrent if they have more than one dialog
+func NewSummary(dialogs []*Dialog) *Summary {
+	summary := &Summary{UsersByTimezone: map[string]UsersSummary{}}
+
+	performanceAccum := 0.0
+	usersByTimezone := map[string]map[string]int{}
+
+	for _, dialog := range dialogs {
+		if len(dialog.Contexts) > 0 {
+			person := dialog.Contexts[0].Context.Person
+			if usersByTimezone[person.Timezone] == nil {
+				usersByTimezone[person.Timezone] = map[string]int{}
+			}
+			usersByTimezone[person.Timezone][person.key()]++
+		}
+
+		if len(dialog.Ins) > 0 {
+			performanceAccum += dialog.Performance
+			summary.TotalDialogs++
+		}
+	}
+
+	if summary.TotalDialogs == 0 {
+		return summary
+	}
+
+	for timezone, users := range usersByTimezone {
+		recurrent := int64(0)
+		for _, total := range users {
+			if total > 1 {
+				recurrent++
+			}
+		}
+
+		summary.UsersByTimezone[timezone] = UsersSummary{
+			Recurrents: recurrent,
+			News:       int64(len(users)) - recurrent,
+		}
+
+		summary.TotalUsers += int64(len(users))
+		summary.RecurrentUsers += recurrent
+	}
+
+	summary.PerformanceMean = performanceAccum / float64(summary.TotalDialogs)
+
+	return summary
+}
+
+// key identifies the person into the summaries, the name is used when the channel doesn't give an id
+func (info PersonInfo) key() string {
+	if info.ID != "" {
+		return info.ID
+	}
+	return info.Name
+}
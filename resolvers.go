@@ -1,5 +1,65 @@
 package neocortex
 
+import "sort"
+
+// ResolutionMode defines how many resolvers are executed when an output matches with many of them
+type ResolutionMode string
+
+// AllMatches executes every resolver that matches with the output (in order of priority)
+const AllMatches ResolutionMode = "all"
+
+// FirstMatch only executes the resolver with the highest priority that matches with the output
+const FirstMatch ResolutionMode = "first"
+
+// registration is the matcher and the priority of a resolver or an injection
+type registration struct {
+	matcher  *Matcher
+	priority int
+}
+
+// insertionPoint keeps the registrations sorted by priority (highest first), the registrations with the same
+// priority are kept in order of registration. Registering the same matcher again replaces its registration,
+// except the nil matchers (the middlewares for all the outputs) that are always added. It returns the index of
+// the replaced registration (-1 if there isn't) and the index of the new one once the replaced is removed
+func insertionPoint(n int, at func(i int) registration, r registration) (int, int) {
+	replaced := -1
+	if r.matcher != nil {
+		for i := 0; i < n; i++ {
+			if at(i).matcher == r.matcher {
+				replaced = i
+				break
+			}
+		}
+	}
+
+	i := sort.Search(n, func(i int) bool {
+		return at(i).priority < r.priority
+	})
+	if replaced >= 0 && replaced < i {
+		i--
+	}
+	return replaced, i
+}
+
+type resolver struct {
+	registration
+	handler HandleResolver
+}
+
+func insertResolver(resolvers []*resolver, r *resolver) []*resolver {
+	at := func(i int) registration { return resolvers[i].registration }
+	replaced, i := insertionPoint(len(resolvers), at, r.registration)
+	if replaced >= 0 {
+		resolvers = append(resolvers[:replaced], resolvers[replaced+1:]...)
+	}
+	return append(resolvers[:i], append([]*resolver{r}, resolvers[i:]...)...)
+}
+
+// SetResolutionMode selects between run all the matched resolvers or only the first of them
+func (engine *Engine) SetResolutionMode(mode ResolutionMode) {
+	engine.resolutionMode = mode
+}
+
 func (engine *Engine) ResolveAny(channel CommunicationChannel, handler HandleResolver) {
 	if engine.generalResolver == nil {
 		engine.generalResolver = map[CommunicationChannel]*HandleResolver{}
@@ -7,19 +67,28 @@ func (engine *Engine) ResolveAny(channel CommunicationChannel, handler HandleRes
 	engine.generalResolver[channel] = &handler
 }
 
-func (engine *Engine) Resolve(channel CommunicationChannel, matcher *Matcher, handler HandleResolver) {
-	if engine.registeredResolvers == nil {
-		engine.registeredResolvers = map[CommunicationChannel]map[*Matcher]*HandleResolver{}
+// Resolve registers a handler for the outputs that match with the matcher, optionally you can pass
+// a priority (by default 0), resolvers with higher priority are executed first. Resolving the same matcher
+// again replaces its resolver
+func (engine *Engine) Resolve(channel CommunicationChannel, matcher *Matcher, handler HandleResolver, priority ...int) {
+	p := 0
+	if len(priority) > 0 {
+		p = priority[0]
 	}
-	if engine.registeredResolvers[channel] == nil {
-		engine.registeredResolvers[channel] = map[*Matcher]*HandleResolver{}
+
+	if engine.registeredResolvers == nil {
+		engine.registeredResolvers = map[CommunicationChannel][]*resolver{}
 	}
-	engine.registeredResolvers[channel][matcher] = &handler
+
+	engine.registeredResolvers[channel] = insertResolver(engine.registeredResolvers[channel], &resolver{
+		registration: registration{matcher: matcher, priority: p},
+		handler:      handler,
+	})
 }
 
-func (engine *Engine) ResolveMany(channels []CommunicationChannel, matcher *Matcher, handler HandleResolver) {
+func (engine *Engine) ResolveMany(channels []CommunicationChannel, matcher *Matcher, handler HandleResolver, priority ...int) {
 	for _, ch := range channels {
-		engine.Resolve(ch, matcher, handler)
+		engine.Resolve(ch, matcher, handler, priority...)
 	}
 }
 
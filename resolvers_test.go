@@ -0,0 +1,171 @@
+package neocortex_test
+
+import (
+	"strings"
+	"testing"
+
+	neo "github.com/minskylab/neocortex"
+)
+
+// registered is a resolver or an injection of the tests, it records its name when it runs
+type registered struct {
+	name     string
+	matcher  string
+	priority int
+	stop     bool
+}
+
+// matchers are shared by name, registering the same name again registers the same matcher
+func testMatchers() map[string]*neo.Matcher {
+	return map[string]*neo.Matcher{
+		"hello":   neo.IfTextContains("hello"),
+		"prefix":  neo.IfTextHasPrefix("hel"),
+		"regex":   neo.IfTextMatches(`^h`),
+		"goodbye": neo.IfTextContains("goodbye"),
+	}
+}
+
+func TestResolversOrder(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     neo.ResolutionMode
+		resolved []registered
+		want     string
+	}{
+		{"by priority", neo.AllMatches, []registered{{"a", "hello", 0, false}, {"b", "prefix", 10, false}, {"c", "regex", 5, false}}, "b c a"},
+		{"same priority in order of registration", neo.AllMatches, []registered{{"a", "hello", 1, false}, {"b", "prefix", 1, false}, {"c", "regex", 1, false}}, "a b c"},
+		{"negative priorities go last", neo.AllMatches, []registered{{"a", "hello", -1, false}, {"b", "prefix", 0, false}}, "b a"},
+		{"first match", neo.FirstMatch, []registered{{"a", "hello", 0, false}, {"b", "prefix", 10, false}}, "b"},
+		{"first match skips the resolvers that don't match", neo.FirstMatch, []registered{{"a", "hello", 0, false}, {"b", "goodbye", 10, false}}, "a"},
+		{"stop propagation", neo.AllMatches, []registered{{"a", "hello", 0, false}, {"b", "prefix", 10, true}}, "b"},
+		{"the same matcher replaces its resolver", neo.AllMatches, []registered{{"a", "hello", 0, false}, {"b", "prefix", 5, false}, {"c", "hello", 10, false}}, "c b"},
+		{"the replaced resolver goes after its priority", neo.AllMatches, []registered{{"a", "hello", 1, false}, {"b", "prefix", 1, false}, {"c", "hello", 1, false}}, "b c"},
+		{"without matches", neo.AllMatches, []registered{{"a", "goodbye", 0, false}}, "any"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			engine, ch := newUselessEngine(t, 0)
+			engine.SetResolutionMode(tc.mode)
+			matchers := testMatchers()
+
+			ran := []string{}
+			engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+				ran = append(ran, "any")
+				return response(c, out)
+			})
+			for _, r := range tc.resolved {
+				r := r
+				engine.Resolve(ch, matchers[r.matcher], func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+					ran = append(ran, r.name)
+					if r.stop {
+						return neo.ErrStopPropagation
+					}
+					return response(c, out)
+				}, r.priority)
+			}
+
+			if _, err := ch.Say(neo.PersonInfo{ID: "42"}, "hello"); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(ran, " "); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestInjectionsOrder(t *testing.T) {
+	cases := []struct {
+		name     string
+		injected []registered
+		want     string
+	}{
+		{"by priority", []registered{{"a", "hello", 0, false}, {"b", "prefix", 10, false}, {"c", "regex", 5, false}}, "b c a"},
+		{"same priority in order of registration", []registered{{"a", "hello", 0, false}, {"b", "prefix", 0, false}}, "a b"},
+		{"the same matcher replaces its injection", []registered{{"a", "hello", 0, false}, {"b", "prefix", 5, false}, {"c", "hello", 10, false}}, "c b"},
+		{"the injections that don't match are skipped", []registered{{"a", "goodbye", 10, false}, {"b", "hello", 0, false}}, "b"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			engine, ch := newUselessEngine(t, 0)
+			matchers := testMatchers()
+
+			ran := []string{}
+			for _, inj := range tc.injected {
+				inj := inj
+				engine.Inject(ch, matchers[inj.matcher], func(c *neo.Context, in *neo.Input) *neo.Input {
+					ran = append(ran, inj.name)
+					return in
+				}, inj.priority)
+			}
+
+			if _, err := ch.Say(neo.PersonInfo{ID: "42"}, "hello"); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(ran, " "); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestOutInjectionsOrder(t *testing.T) {
+	cases := []struct {
+		name     string
+		injected []registered
+		want     string
+	}{
+		{"by priority", []registered{{"a", "hello", 0, false}, {"b", "prefix", 10, false}, {"c", "", 5, false}}, "b c a"},
+		{"the same matcher replaces its middleware", []registered{{"a", "hello", 0, false}, {"b", "hello", 0, false}}, "b"},
+		{"the middlewares for all the outputs are never replaced", []registered{{"a", "", 0, false}, {"b", "", 0, false}}, "a b"},
+		{"a discarded output stops the chain", []registered{{"a", "", 10, true}, {"b", "", 0, false}}, "a"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			engine, ch := newUselessEngine(t, 0)
+			matchers := testMatchers()
+
+			ran := []string{}
+			for _, inj := range tc.injected {
+				inj := inj
+				engine.InjectOut(ch, matchers[inj.matcher], func(c *neo.Context, in *neo.Input, out *neo.Output) *neo.Output {
+					ran = append(ran, inj.name)
+					if inj.stop {
+						return nil
+					}
+					return out
+				}, inj.priority)
+			}
+
+			if _, err := ch.Say(neo.PersonInfo{ID: "42"}, "hello"); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(ran, " "); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTheOutputsOfTheResolversHaveTheirInput(t *testing.T) {
+	engine, ch := newUselessEngine(t, 0)
+
+	matched := false
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		matched = out.Match(c, neo.IfTextContains("hello"))
+		return response(c, out)
+	})
+
+	if _, err := ch.Say(neo.PersonInfo{ID: "42"}, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if !matched {
+		t.Error("Output.Match must evaluate the text of the input into the resolvers")
+	}
+}
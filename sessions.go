@@ -0,0 +1,385 @@
+package neocortex
+
+import (
+	"sync"
+	"time"
+)
+
+type sessionLock struct {
+	mu      sync.Mutex
+	refs    int
+	pending []func()
+}
+
+// session is the active dialog of a context, the saves of the dialog are serialized by saving
+// and nothing is saved after the session is closed
+type session struct {
+	dialog    *Dialog
+	channel   string
+	dirty     bool
+	scheduled bool
+	recovered bool
+
+	saving sync.Mutex
+	closed bool
+}
+
+// SessionManager keeps the active dialogs of the engine, it is safe for concurrent use
+// and serializes the messages of each session (one message at time per context)
+type SessionManager struct {
+	mu        sync.Mutex
+	sessions  map[*Context]*session
+	locks     map[*Context]*sessionLock
+	recovered map[string]*Context
+}
+
+func newSessionManager() *SessionManager {
+	return &SessionManager{
+		sessions:  map[*Context]*session{},
+		locks:     map[*Context]*sessionLock{},
+		recovered: map[string]*Context{},
+	}
+}
+
+// Open starts a new dialog for the context, if the context already had one it is replaced
+func (sm *SessionManager) Open(c *Context) *Dialog {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	dialog := newDialog()
+	sm.sessions[c] = &session{dialog: dialog}
+	return dialog
+}
+
+// Close removes the dialog of the context and returns it, after that the dialog
+// is not touched anymore by the engine
+func (sm *SessionManager) Close(c *Context) (*Dialog, bool) {
+	s, ok := sm.remove(c)
+	if !ok {
+		return nil, false
+	}
+
+	s.saving.Lock()
+	s.closed = true
+	s.saving.Unlock()
+
+	return s.dialog, true
+}
+
+func (sm *SessionManager) remove(c *Context) (*session, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	s, ok := sm.sessions[c]
+	if !ok {
+		return nil, false
+	}
+
+	delete(sm.sessions, c)
+	if s.recovered {
+		delete(sm.recovered, c.Person.key())
+	}
+	return s, true
+}
+
+// setChannel keeps the name of the channel of the active dialog of the context (for the logs)
+func (sm *SessionManager) setChannel(c *Context, channel string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if s, ok := sm.sessions[c]; ok {
+		s.channel = channel
+	}
+}
+
+// describe returns the id and the channel of the active dialog of the context
+func (sm *SessionManager) describe(c *Context) (string, string, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	s, ok := sm.sessions[c]
+	if !ok {
+		return "", "", false
+	}
+	return s.dialog.ID, s.channel, true
+}
+
+// IsActive returns true if the context has an open dialog
+func (sm *SessionManager) IsActive(c *Context) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	_, ok := sm.sessions[c]
+	return ok
+}
+
+// Len returns the total of active dialogs
+func (sm *SessionManager) Len() int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	return len(sm.sessions)
+}
+
+// Contexts returns a snapshot of all contexts with an active dialog
+func (sm *SessionManager) Contexts() []*Context {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	contexts := make([]*Context, 0, len(sm.sessions))
+	for c := range sm.sessions {
+		contexts = append(contexts, c)
+	}
+	return contexts
+}
+
+// Dialog returns a copy of the active dialog of the context
+func (sm *SessionManager) Dialog(c *Context) (*Dialog, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	s, ok := sm.sessions[c]
+	if !ok {
+		return nil, false
+	}
+
+	return s.snapshot(), true
+}
+
+// must be called with the lock of the manager
+func (s *session) snapshot() *Dialog {
+	snapshot := *s.dialog
+	snapshot.Ins = append([]*InputRecord{}, s.dialog.Ins...)
+	snapshot.Outs = append([]*OutputRecord{}, s.dialog.Outs...)
+	snapshot.Contexts = append([]*ContextRecord{}, s.dialog.Contexts...)
+	return &snapshot
+}
+
+// expired returns the contexts without activity since maxIdle before t
+func (sm *SessionManager) expired(t time.Time, maxIdle time.Duration) []*Context {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	contexts := make([]*Context, 0)
+	for c, s := range sm.sessions {
+		if t.Sub(s.dialog.LastActivity) > maxIdle {
+			contexts = append(contexts, c)
+		}
+	}
+	return contexts
+}
+
+// isIdle returns true if the context has an active dialog without activity since maxIdle before t
+func (sm *SessionManager) isIdle(c *Context, t time.Time, maxIdle time.Duration) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	s, ok := sm.sessions[c]
+	return ok && t.Sub(s.dialog.LastActivity) > maxIdle
+}
+
+func (sm *SessionManager) recordInput(c *Context, in *Input) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if s, ok := sm.sessions[c]; ok {
+		at := time.Now()
+		s.dirty = true
+		s.dialog.LastActivity = at
+		s.dialog.Contexts = append(s.dialog.Contexts, &ContextRecord{At: at, Context: c.snapshot()})
+		s.dialog.Ins = append(s.dialog.Ins, &InputRecord{At: at, Input: *in})
+	}
+}
+
+func (sm *SessionManager) recordOutput(c *Context, out *Output) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if s, ok := sm.sessions[c]; ok {
+		at := time.Now()
+		s.dirty = true
+		s.dialog.LastActivity = at
+		s.dialog.Contexts = append(s.dialog.Contexts, &ContextRecord{At: at, Context: c.snapshot()})
+		s.dialog.Outs = append(s.dialog.Outs, &OutputRecord{At: at, Output: *out})
+	}
+}
+
+// schedule marks the active dialog of the context to be saved, it returns false if there is
+// a save scheduled already (it will take the new records too)
+func (sm *SessionManager) schedule(c *Context) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	s, ok := sm.sessions[c]
+	if !ok || s.scheduled {
+		return false
+	}
+	s.scheduled = true
+	return true
+}
+
+// checkpoint saves a snapshot of the active dialog of the context, the snapshot is taken once the
+// previous save is done so the checkpoints of a dialog are saved in order and never after the
+// dialog was closed. If the save fails the dialog is still dirty
+func (sm *SessionManager) checkpoint(c *Context, save func(dialog *Dialog) error) error {
+	sm.mu.Lock()
+	s, ok := sm.sessions[c]
+	sm.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	s.saving.Lock()
+	defer s.saving.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	sm.mu.Lock()
+	s.scheduled = false
+	if !s.dirty {
+		sm.mu.Unlock()
+		return nil
+	}
+	s.dirty = false
+	snapshot := s.snapshot()
+	sm.mu.Unlock()
+
+	if err := save(snapshot); err != nil {
+		sm.mu.Lock()
+		s.dirty = true
+		sm.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// finish closes the dialog of the context and saves it, waiting for the checkpoints in progress
+func (sm *SessionManager) finish(c *Context, save func(dialog *Dialog) error) (bool, error) {
+	s, ok := sm.remove(c)
+	if !ok {
+		return false, nil
+	}
+
+	s.saving.Lock()
+	defer s.saving.Unlock()
+
+	s.closed = true
+	return true, save(s.dialog)
+}
+
+// dirty returns the contexts with records not saved yet
+func (sm *SessionManager) dirty() []*Context {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	contexts := make([]*Context, 0)
+	for c, s := range sm.sessions {
+		if s.dirty {
+			contexts = append(contexts, c)
+		}
+	}
+	return contexts
+}
+
+// restore keeps a dialog recovered from the repository under its last context, it waits there until
+// the person comes back (see resume) or until it expires
+func (sm *SessionManager) restore(c *Context, dialog *Dialog) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	key := c.Person.key()
+	if previous, ok := sm.recovered[key]; ok {
+		delete(sm.sessions, previous)
+	}
+
+	sm.sessions[c] = &session{dialog: dialog, recovered: true}
+	sm.recovered[key] = c
+}
+
+// resume moves the recovered dialog of the person of c (if any) to c, the variables of the recovered
+// context are restored into c. It returns false if the person doesn't have a recovered dialog
+func (sm *SessionManager) resume(c *Context) (*Dialog, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	key := c.Person.key()
+	previous, ok := sm.recovered[key]
+	if !ok {
+		return nil, false
+	}
+
+	s := sm.sessions[previous]
+	delete(sm.recovered, key)
+	delete(sm.sessions, previous)
+
+	for name, value := range previous.Variables {
+		c.SetContextVariable(name, value)
+	}
+
+	s.recovered = false
+	s.dirty = true
+	s.dialog.LastActivity = time.Now()
+	sm.sessions[c] = s
+
+	return s.dialog, true
+}
+
+// isRecovered returns true if the context was recovered and its person didn't come back
+func (sm *SessionManager) isRecovered(c *Context) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	s, ok := sm.sessions[c]
+	return ok && s.recovered
+}
+
+// acquire blocks until the session of the context is free, the returned func releases it
+func (sm *SessionManager) acquire(c *Context) func() {
+	sm.mu.Lock()
+	l, ok := sm.locks[c]
+	if !ok {
+		l = &sessionLock{}
+		sm.locks[c] = l
+	}
+	l.refs++
+	sm.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		sm.mu.Lock()
+		pending := l.pending
+		l.pending = nil
+		sm.mu.Unlock()
+
+		// the closes requested during the message run before the next message of the context
+		for _, close := range pending {
+			close()
+		}
+
+		l.mu.Unlock()
+
+		sm.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(sm.locks, c)
+		}
+		sm.mu.Unlock()
+	}
+}
+
+// deferClose queues the close of the context until its message in progress is released (the close
+// runs with the session still acquired), it returns false if the context isn't acquired
+func (sm *SessionManager) deferClose(c *Context, close func()) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	l, ok := sm.locks[c]
+	if !ok || l.refs == 0 {
+		return false
+	}
+	l.pending = append(l.pending, close)
+	return true
+}
@@ -0,0 +1,236 @@
+package neocortex_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	neo "github.com/minskylab/neocortex"
+	"github.com/minskylab/neocortex/channels/memory"
+	"github.com/minskylab/neocortex/cognitive/uselessbox"
+	memoryrepo "github.com/minskylab/neocortex/repositories/memory"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// slowBox takes its time to answer, the sessions expire in the middle of its messages
+type slowBox struct {
+	*uselessbox.Cognitive
+	delay time.Duration
+}
+
+func (box *slowBox) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	time.Sleep(box.delay)
+	return box.Cognitive.GetProtoResponse(c, in)
+}
+
+func newUselessEngine(t *testing.T, delay time.Duration, opts ...neo.EngineOption) (*neo.Engine, *memory.Channel) {
+	t.Helper()
+
+	ch := memory.NewChannel()
+	cognitive := &slowBox{Cognitive: uselessbox.NewCognitive(), delay: delay}
+	opts = append([]neo.EngineOption{neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat))}, opts...)
+	engine, err := neo.New(cognitive, []neo.CommunicationChannel{ch}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		return response(c, out)
+	})
+	return engine, ch
+}
+
+// TestSessionsManyUsers drives many users at the same time, every message must be recorded
+// into the dialog of its person (run it with -race)
+func TestSessionsManyUsers(t *testing.T) {
+	const users = 40
+	const messages = 15
+
+	repo := memoryrepo.New()
+	engine, ch := newUselessEngine(t, 0, neo.WithRepository(repo))
+
+	done := make(chan struct{})
+	go func() {
+		// the API of the sessions is read meanwhile
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, c := range engine.Sessions.Contexts() {
+				engine.Sessions.Dialog(c)
+			}
+			engine.Sessions.Len()
+		}
+	}()
+
+	wg := new(sync.WaitGroup)
+	for u := 0; u < users; u++ {
+		wg.Add(1)
+		go func(u int) {
+			defer wg.Done()
+			person := neo.PersonInfo{ID: fmt.Sprintf("user-%d", u)}
+			for m := 0; m < messages; m++ {
+				exchange, err := ch.Say(person, fmt.Sprintf("message %d", m))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(exchange.Texts()) != 1 {
+					t.Errorf("%s: expected 1 response, got %q", person.ID, exchange.Texts())
+				}
+			}
+			ch.End(person.ID)
+		}(u)
+	}
+	wg.Wait()
+	close(done)
+
+	if engine.Sessions.Len() != 0 {
+		t.Fatalf("expected 0 active sessions, got %d", engine.Sessions.Len())
+	}
+
+	dialogs, err := repo.AllDialogs(neo.TimeFrame{PageSize: users * 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dialogs) != users {
+		t.Fatalf("expected %d dialogs, got %d", users, len(dialogs))
+	}
+	for _, dialog := range dialogs {
+		if len(dialog.Ins) != messages || len(dialog.Outs) != messages {
+			t.Errorf("dialog %s: expected %d ins and outs, got %d and %d", dialog.ID, messages, len(dialog.Ins), len(dialog.Outs))
+		}
+		if dialog.EndAt.IsZero() {
+			t.Errorf("dialog %s isn't closed", dialog.ID)
+		}
+	}
+}
+
+// TestSessionsExpireMeanwhile expires the sessions while its users are talking, the garbage collector
+// must wait for the message in progress of each context
+func TestSessionsExpireMeanwhile(t *testing.T) {
+	const users = 20
+	const messages = 10
+
+	repo := memoryrepo.New()
+	engine, ch := newUselessEngine(t, 3*time.Millisecond,
+		neo.WithRepository(repo),
+		neo.WithPort("127.0.0.1:0"),
+		neo.WithSessionTimeout(time.Millisecond),
+		neo.WithGCTick(time.Millisecond),
+	)
+	go engine.Run()
+
+	wg := new(sync.WaitGroup)
+	for u := 0; u < users; u++ {
+		wg.Add(1)
+		go func(u int) {
+			defer wg.Done()
+			person := neo.PersonInfo{ID: fmt.Sprintf("user-%d", u)}
+			for m := 0; m < messages; m++ {
+				exchange, err := ch.Say(person, fmt.Sprintf("message %d", m))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(exchange.Texts()) != 1 {
+					t.Errorf("%s: expected 1 response, got %q", person.ID, exchange.Texts())
+				}
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for engine.Sessions.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if engine.Sessions.Len() != 0 {
+		t.Fatalf("expected 0 active sessions, got %d", engine.Sessions.Len())
+	}
+
+	dialogs, err := repo.AllDialogs(neo.TimeFrame{PageSize: users * messages})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ins, outs := 0, 0
+	for _, dialog := range dialogs {
+		if len(dialog.Ins) != len(dialog.Outs) {
+			t.Errorf("dialog %s: %d ins and %d outs, a message was cut by its expiration", dialog.ID, len(dialog.Ins), len(dialog.Outs))
+		}
+		ins += len(dialog.Ins)
+		outs += len(dialog.Outs)
+	}
+	if ins > users*messages || outs > users*messages {
+		t.Fatalf("more records than messages: %d ins and %d outs", ins, outs)
+	}
+}
+
+// endingBox ends the context while it answers "bye", like the cognitive services with end intents
+type endingBox struct {
+	*uselessbox.Cognitive
+	done []func(c *neo.Context)
+}
+
+func (box *endingBox) OnContextIsDone(callback func(c *neo.Context)) {
+	box.done = append(box.done, callback)
+}
+
+func (box *endingBox) GetProtoResponse(c *neo.Context, in *neo.Input) (*neo.Output, error) {
+	if in.Data.Value == "bye" {
+		for _, call := range box.done {
+			call(c)
+		}
+	}
+	return box.Cognitive.GetProtoResponse(c, in)
+}
+
+// TestTheCloseWaitsForTheMessage ends the context in the middle of its message, the close must wait
+// for the resolvers so the last output is recorded into the closed dialog
+func TestTheCloseWaitsForTheMessage(t *testing.T) {
+	repo := memoryrepo.New()
+	ch := memory.NewChannel()
+	engine, err := neo.New(&endingBox{Cognitive: uselessbox.NewCognitive()}, []neo.CommunicationChannel{ch},
+		neo.WithRepository(repo),
+		neo.WithLogger(neo.NewLogger(ioutil.Discard, neo.TextFormat)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.ResolveAny(ch, func(c *neo.Context, in *neo.Input, out *neo.Output, response neo.OutputResponse) error {
+		if len(engine.ActiveDialogs()) != 1 {
+			t.Error("the context can't be closed while its message is resolved")
+		}
+		return response(c, out)
+	})
+
+	person := neo.PersonInfo{ID: "42"}
+	ch.Say(person, "hi")
+	exchange, err := ch.Say(person, "bye")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exchange.Ended || engine.Sessions.Len() != 0 {
+		t.Fatalf("the context must be closed at the end of the message, got %d active sessions", engine.Sessions.Len())
+	}
+
+	dialogs, err := repo.AllDialogs(neo.TimeFrame{PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dialogs) != 1 || len(dialogs[0].Ins) != 2 || len(dialogs[0].Outs) != 2 || dialogs[0].EndAt.IsZero() {
+		t.Fatalf("the closed dialog must have every message, got %d dialogs", len(dialogs))
+	}
+}
@@ -0,0 +1,245 @@
+package neocortex
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// apiKeyPrefix starts every API key, so a key is recognized (and found into logs or leaks) at a glance
+const apiKeyPrefix = "nck_"
+
+// Scope is a permission of an API key over a group of endpoints, e.g. "dialogs:read" or "views:write",
+// a write scope includes the read. The admins can't be managed with API keys
+type Scope string
+
+// ScopeGroups are the groups of endpoints that an API key can access
+var ScopeGroups = []string{"summary", "collections", "views", "dialogs", "chats", "downloads", "actions"}
+
+const readAccess = "read"
+const writeAccess = "write"
+
+// IsValid returns true if the scope is "<group>:read" or "<group>:write" with a group of ScopeGroups
+func (scope Scope) IsValid() bool {
+	parts := strings.Split(string(scope), ":")
+	if len(parts) != 2 || (parts[1] != readAccess && parts[1] != writeAccess) {
+		return false
+	}
+
+	for _, group := range ScopeGroups {
+		if parts[0] == group {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a long-lived credential for machine clients, only the hash of its secret is kept
+type APIKey struct {
+	ID        string    `json:"id" bson:"id"`
+	Name      string    `json:"name" bson:"name"`
+	Hash      string    `json:"hash" bson:"hash"`
+	Scopes    []Scope   `json:"scopes" bson:"scopes"`
+	CreatedBy string    `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"` // the zero time means it never expires
+	RevokedAt time.Time `json:"revoked_at" bson:"revoked_at"`
+}
+
+// Active returns true if the key wasn't revoked and it didn't expire at t
+func (key *APIKey) Active(t time.Time) bool {
+	return key.RevokedAt.IsZero() && (key.ExpiresAt.IsZero() || t.Before(key.ExpiresAt))
+}
+
+// Allows returns true if the key can read (or write) the group of endpoints
+func (key *APIKey) Allows(group string, write bool) bool {
+	for _, scope := range key.Scopes {
+		if scope == Scope(group+":"+writeAccess) || (!write && scope == Scope(group+":"+readAccess)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenRepository persists the API keys and the revoked tokens, a Repository can implement it to keep them
+// between restarts. If the repository of the engine doesn't implement it they live into the memory
+type TokenRepository interface {
+	// SaveAPIKey inserts the key or replaces the key with the same id
+	SaveAPIKey(key *APIKey) error
+	GetAPIKey(id string) (*APIKey, error)
+	// AllAPIKeys returns the keys (the revoked ones too) in the order they were created
+	AllAPIKeys() ([]*APIKey, error)
+
+	// RevokeToken rejects the token with the id (its jti) until it expires, the expired revocations can be dropped
+	RevokeToken(id string, expiresAt time.Time) error
+	IsTokenRevoked(id string) (bool, error)
+}
+
+// memoryTokens is the TokenRepository used when the repository of the engine can't persist the tokens
+type memoryTokens struct {
+	mu      sync.RWMutex
+	keys    map[string]APIKey
+	revoked map[string]time.Time
+}
+
+func newMemoryTokens() *memoryTokens {
+	return &memoryTokens{keys: map[string]APIKey{}, revoked: map[string]time.Time{}}
+}
+
+func (m *memoryTokens) SaveAPIKey(key *APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *key
+	copied.Scopes = append([]Scope{}, key.Scopes...)
+	m.keys[key.ID] = copied
+	return nil
+}
+
+func (m *memoryTokens) GetAPIKey(id string) (*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, ok := m.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotExist
+	}
+	return &key, nil
+}
+
+func (m *memoryTokens) AllAPIKeys() ([]*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]*APIKey, 0, len(m.keys))
+	for _, key := range m.keys {
+		key := key
+		keys = append(keys, &key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+func (m *memoryTokens) RevokeToken(id string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for other, at := range m.revoked {
+		if at.Before(now) {
+			delete(m.revoked, other)
+		}
+	}
+
+	m.revoked[id] = expiresAt
+	return nil
+}
+
+func (m *memoryTokens) IsTokenRevoked(id string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.revoked[id]
+	return ok, nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey creates a key with the scopes, the returned string is the only copy of the
+// key (only its hash is saved). A zero expiresAt creates a key that never expires
+func (engine *Engine) CreateAPIKey(name string, scopes []Scope, expiresAt time.Time, createdBy string) (*APIKey, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, "", ErrInvalidScope
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &APIKey{
+		ID:        xid.New().String(),
+		Name:      name,
+		Hash:      hashAPIKeySecret(encoded),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	if err := engine.tokens.SaveAPIKey(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, apiKeyPrefix + key.ID + "_" + encoded, nil
+}
+
+// APIKeys returns all keys in the order they were created
+func (engine *Engine) APIKeys() ([]*APIKey, error) {
+	return engine.tokens.AllAPIKeys()
+}
+
+// RevokeAPIKey rejects the key from the next request, the key is kept for audit purpose
+func (engine *Engine) RevokeAPIKey(id string) (*APIKey, error) {
+	key, err := engine.tokens.GetAPIKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.RevokedAt.IsZero() {
+		key.RevokedAt = time.Now()
+		if err := engine.tokens.SaveAPIKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// RevokeToken rejects the JWT with the id (its jti claim), expiresAt is the expiration of the token
+func (engine *Engine) RevokeToken(id string, expiresAt time.Time) error {
+	return engine.tokens.RevokeToken(id, expiresAt)
+}
+
+// authenticateAPIKey returns the key if it's active and its secret matches, the key looks like "nck_<id>_<secret>"
+func (engine *Engine) authenticateAPIKey(raw string) (*APIKey, error) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(raw, apiKeyPrefix), "_", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := engine.tokens.GetAPIKey(parts[0])
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKeySecret(parts[1]))) != 1 || !key.Active(time.Now()) {
+		return nil, ErrInvalidAPIKey
+	}
+	return key, nil
+}